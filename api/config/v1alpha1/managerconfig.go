@@ -0,0 +1,189 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DefaultHydraPort is the port of ORY Hydra's admin server unless set.
+	DefaultHydraPort = 4445
+
+	// DefaultTimeout is the timeout of the requests to ORY Hydra unless set.
+	DefaultTimeout = 5 * time.Second
+
+	// DefaultClientIDKey is the key of the client ID in the Secrets unless
+	// set.
+	DefaultClientIDKey = "CLIENT_ID"
+
+	// DefaultClientSecretKey is the key of the client secret in the Secrets
+	// unless set.
+	DefaultClientSecretKey = "CLIENT_SECRET"
+)
+
+// Load returns the configuration of the manager, made of the settings of the
+// file at path over the ones of base, defaulted and validated. The file is
+// not read if path is empty.
+func Load(path string, base ManagerConfig) (*ManagerConfig, error) {
+	c := base
+	c.Namespaces.Watch = slices.Clone(base.Namespaces.Watch)
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.UnmarshalStrict(data, &c); err != nil {
+			return nil, fmt.Errorf("cannot parse %s: %w", path, err)
+		}
+		if c.APIVersion != GroupVersion.String() || c.Kind != Kind {
+			return nil, fmt.Errorf("%s is a %s %s, expected a %s %s", path, c.APIVersion, c.Kind, GroupVersion, Kind)
+		}
+	}
+
+	c.Default()
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Default sets the settings that are not set to their default value.
+func (c *ManagerConfig) Default() {
+	if c.Hydra.Port == 0 {
+		c.Hydra.Port = DefaultHydraPort
+	}
+	if c.Hydra.Timeout.Duration == 0 {
+		c.Hydra.Timeout.Duration = DefaultTimeout
+	}
+	if c.SecretKeys.ClientID == "" {
+		c.SecretKeys.ClientID = DefaultClientIDKey
+	}
+	if c.SecretKeys.ClientSecret == "" {
+		c.SecretKeys.ClientSecret = DefaultClientSecretKey
+	}
+	for _, n := range []*int{&c.Concurrency.OAuth2Clients, &c.Concurrency.JsonWebKeySets, &c.Concurrency.TrustedJwtGrantIssuers} {
+		if *n == 0 {
+			*n = 1
+		}
+	}
+}
+
+// Validate returns an error listing the invalid settings, if any.
+func (c *ManagerConfig) Validate() error {
+	var errs field.ErrorList
+
+	hydra := field.NewPath("hydra")
+	if c.Hydra.URL == "" {
+		errs = append(errs, field.Required(hydra.Child("url"), "the address of ORY Hydra can't be empty"))
+	} else if u, err := url.Parse(c.Hydra.URL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, field.Invalid(hydra.Child("url"), c.Hydra.URL, "must be an absolute URL, e.g. http://hydra-admin"))
+	}
+	if c.Hydra.Port < 1 || c.Hydra.Port > 65535 {
+		errs = append(errs, field.Invalid(hydra.Child("port"), c.Hydra.Port, "must be between 1 and 65535"))
+	}
+	if c.Hydra.Timeout.Duration < 0 {
+		errs = append(errs, field.Invalid(hydra.Child("timeout"), c.Hydra.Timeout.Duration.String(), "must not be negative"))
+	}
+	errs = append(errs, validateFile(hydra.Child("tls", "trustStore"), c.Hydra.TLS.TrustStore)...)
+
+	auth := hydra.Child("auth")
+	switch {
+	case c.Hydra.Auth.BearerTokenFile != "" && c.Hydra.Auth.Username != "":
+		errs = append(errs, field.Forbidden(auth.Child("username"), "bearerTokenFile and username are mutually exclusive"))
+	case c.Hydra.Auth.Username != "" && c.Hydra.Auth.PasswordFile == "":
+		errs = append(errs, field.Required(auth.Child("passwordFile"), "required with username"))
+	case c.Hydra.Auth.Username == "" && c.Hydra.Auth.PasswordFile != "":
+		errs = append(errs, field.Required(auth.Child("username"), "required with passwordFile"))
+	}
+	errs = append(errs, validateFile(auth.Child("bearerTokenFile"), c.Hydra.Auth.BearerTokenFile)...)
+	errs = append(errs, validateFile(auth.Child("passwordFile"), c.Hydra.Auth.PasswordFile)...)
+
+	secretKeys := field.NewPath("secretKeys")
+	for _, key := range []struct {
+		path  *field.Path
+		value string
+	}{
+		{secretKeys.Child("clientId"), c.SecretKeys.ClientID},
+		{secretKeys.Child("clientSecret"), c.SecretKeys.ClientSecret},
+	} {
+		for _, msg := range validation.IsConfigMapKey(key.value) {
+			errs = append(errs, field.Invalid(key.path, key.value, msg))
+		}
+	}
+	if c.SecretKeys.ClientID == c.SecretKeys.ClientSecret {
+		errs = append(errs, field.Duplicate(secretKeys.Child("clientSecret"), c.SecretKeys.ClientSecret))
+	}
+
+	namespaces := field.NewPath("namespaces")
+	for i, ns := range c.Namespaces.Watch {
+		for _, msg := range validation.IsDNS1123Label(ns) {
+			errs = append(errs, field.Invalid(namespaces.Child("watch").Index(i), ns, msg))
+		}
+	}
+	if _, err := labels.Parse(c.Namespaces.Selector); err != nil {
+		errs = append(errs, field.Invalid(namespaces.Child("selector"), c.Namespaces.Selector, err.Error()))
+	}
+
+	concurrency := field.NewPath("concurrency")
+	for _, n := range []struct {
+		name  string
+		value int
+	}{
+		{"oauth2Clients", c.Concurrency.OAuth2Clients},
+		{"jsonWebKeySets", c.Concurrency.JsonWebKeySets},
+		{"trustedJwtGrantIssuers", c.Concurrency.TrustedJwtGrantIssuers},
+	} {
+		if n.value < 1 {
+			errs = append(errs, field.Invalid(concurrency.Child(n.name), n.value, "must be at least 1"))
+		}
+	}
+
+	return errs.ToAggregate()
+}
+
+// RestartRequired returns the settings that differ between c and other and
+// that cannot be applied without restarting the manager.
+func (c *ManagerConfig) RestartRequired(other *ManagerConfig) []string {
+	// the settings of the requests to ORY Hydra are reloaded
+	hydra, otherHydra := c.Hydra, other.Hydra
+	hydra.Timeout, hydra.TLS, hydra.Auth = otherHydra.Timeout, otherHydra.TLS, otherHydra.Auth
+
+	var changed []string
+	for _, setting := range []struct {
+		name        string
+		value, with interface{}
+	}{
+		{"hydra", hydra, otherHydra},
+		{"secretKeys", c.SecretKeys, other.SecretKeys},
+		{"namespaces", c.Namespaces, other.Namespaces},
+		{"concurrency", c.Concurrency, other.Concurrency},
+	} {
+		if !equality.Semantic.DeepEqual(setting.value, setting.with) {
+			changed = append(changed, setting.name)
+		}
+	}
+	return changed
+}
+
+func validateFile(path *field.Path, name string) field.ErrorList {
+	if name == "" {
+		return nil
+	}
+	if _, err := os.Stat(name); err != nil {
+		return field.ErrorList{field.Invalid(path, name, err.Error())}
+	}
+	return nil
+}
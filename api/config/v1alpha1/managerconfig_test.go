@@ -0,0 +1,146 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	configv1alpha1 "github.com/ory/hydra-maester/api/config/v1alpha1"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	name := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(name, []byte(content), 0o600))
+	return name
+}
+
+func TestLoad(t *testing.T) {
+	base := configv1alpha1.ManagerConfig{}
+	base.Hydra.URL = "http://hydra-admin"
+	base.Namespaces.Watch = []string{"flag"}
+
+	t.Run("case=without file", func(t *testing.T) {
+		c, err := configv1alpha1.Load("", base)
+		require.NoError(t, err)
+		assert.Equal(t, "http://hydra-admin", c.Hydra.URL)
+		assert.Equal(t, configv1alpha1.DefaultHydraPort, c.Hydra.Port)
+		assert.Equal(t, configv1alpha1.DefaultTimeout, c.Hydra.Timeout.Duration)
+		assert.Equal(t, configv1alpha1.SecretKeysConfig{ClientID: "CLIENT_ID", ClientSecret: "CLIENT_SECRET"}, c.SecretKeys)
+		assert.Equal(t, configv1alpha1.ConcurrencyConfig{OAuth2Clients: 1, JsonWebKeySets: 1, TrustedJwtGrantIssuers: 1}, c.Concurrency)
+	})
+
+	t.Run("case=file overrides base", func(t *testing.T) {
+		c, err := configv1alpha1.Load(writeConfig(t, `
+apiVersion: config.hydra.ory.sh/v1alpha1
+kind: ManagerConfig
+hydra:
+  port: 4446
+  timeout: 10s
+secretKeys:
+  clientId: id
+namespaces:
+  watch: [a, b]
+concurrency:
+  oauth2Clients: 4
+`), base)
+		require.NoError(t, err)
+		assert.Equal(t, "http://hydra-admin", c.Hydra.URL)
+		assert.Equal(t, 4446, c.Hydra.Port)
+		assert.Equal(t, 10*time.Second, c.Hydra.Timeout.Duration)
+		assert.Equal(t, configv1alpha1.SecretKeysConfig{ClientID: "id", ClientSecret: "CLIENT_SECRET"}, c.SecretKeys)
+		assert.Equal(t, []string{"a", "b"}, c.Namespaces.Watch)
+		assert.Equal(t, 4, c.Concurrency.OAuth2Clients)
+		assert.Equal(t, 1, c.Concurrency.JsonWebKeySets)
+
+		// the base is left unchanged
+		assert.Equal(t, []string{"flag"}, base.Namespaces.Watch)
+	})
+
+	for desc, tc := range map[string]struct {
+		content string
+		err     string
+	}{
+		"wrong kind": {
+			content: "apiVersion: v1\nkind: ConfigMap\n",
+			err:     "expected a config.hydra.ory.sh/v1alpha1 ManagerConfig",
+		},
+		"unknown field": {
+			content: "apiVersion: config.hydra.ory.sh/v1alpha1\nkind: ManagerConfig\nhydra:\n  uri: http://hydra\n",
+			err:     `unknown field "uri"`,
+		},
+		"invalid settings": {
+			content: `
+apiVersion: config.hydra.ory.sh/v1alpha1
+kind: ManagerConfig
+hydra:
+  url: hydra-admin
+  auth:
+    username: admin
+secretKeys:
+  clientId: CLIENT_SECRET
+namespaces:
+  watch: [Invalid]
+  selector: "a in"
+concurrency:
+  oauth2Clients: -1
+`,
+			err: "hydra.url: Invalid value",
+		},
+	} {
+		t.Run("case="+desc, func(t *testing.T) {
+			_, err := configv1alpha1.Load(writeConfig(t, tc.content), base)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+
+	t.Run("case=reports all invalid settings", func(t *testing.T) {
+		c := configv1alpha1.ManagerConfig{}
+		c.Hydra.URL = "hydra-admin"
+		c.Hydra.TLS.TrustStore = "/does/not/exist"
+		c.Hydra.Auth.Username = "admin"
+		c.SecretKeys = configv1alpha1.SecretKeysConfig{ClientID: "key", ClientSecret: "key"}
+		c.Namespaces.Watch = []string{"Invalid"}
+		c.Namespaces.Selector = "a in"
+		c.Concurrency.OAuth2Clients = -1
+		c.Default()
+
+		err := c.Validate()
+		require.Error(t, err)
+		for _, field := range []string{
+			"hydra.url",
+			"hydra.tls.trustStore",
+			"hydra.auth.passwordFile",
+			"secretKeys.clientSecret",
+			"namespaces.watch[0]",
+			"namespaces.selector",
+			"concurrency.oauth2Clients",
+		} {
+			assert.Contains(t, err.Error(), field)
+		}
+	})
+}
+
+func TestRestartRequired(t *testing.T) {
+	c := configv1alpha1.ManagerConfig{}
+	c.Hydra.URL = "http://hydra-admin"
+	c.Default()
+
+	reloaded := c
+	reloaded.Hydra.Timeout.Duration = time.Minute
+	reloaded.Hydra.TLS.InsecureSkipVerify = true
+	reloaded.Hydra.Auth.Username = "admin"
+	assert.Empty(t, c.RestartRequired(&reloaded))
+
+	restarted := reloaded
+	restarted.Hydra.Port = 4446
+	restarted.Namespaces.Watch = []string{"a"}
+	assert.Equal(t, []string{"hydra", "namespaces"}, c.RestartRequired(&restarted))
+}
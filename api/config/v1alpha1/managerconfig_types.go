@@ -0,0 +1,123 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+// Package v1alpha1 contains the v1alpha1 version of the configuration file
+// of the manager, which is not served by the Kubernetes API.
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GroupVersion is the group version of the configuration file.
+var GroupVersion = schema.GroupVersion{Group: "config.hydra.ory.sh", Version: "v1alpha1"}
+
+// Kind is the kind of the configuration file.
+const Kind = "ManagerConfig"
+
+// ManagerConfig is the configuration file of the manager, given with the
+// --config flag. The settings it sets override the ones of the flags and env
+// vars. The settings of the requests to ORY Hydra, that is hydra.timeout,
+// hydra.tls and hydra.auth, are reloaded when the file changes, while the
+// others restart the manager.
+type ManagerConfig struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Hydra configures the default ORY Hydra admin server, used by the
+	// resources that do not set their own.
+	Hydra HydraConfig `json:"hydra"`
+
+	// SecretKeys are the keys of the client credentials in the Secrets.
+	SecretKeys SecretKeysConfig `json:"secretKeys"`
+
+	// Namespaces restricts the namespaces the controllers operate in.
+	Namespaces NamespacesConfig `json:"namespaces"`
+
+	// Concurrency is the number of resources of each kind reconciled in
+	// parallel.
+	Concurrency ConcurrencyConfig `json:"concurrency"`
+}
+
+// HydraConfig configures the default ORY Hydra admin server.
+type HydraConfig struct {
+	// URL is the address of ORY Hydra's admin server, e.g. http://hydra-admin.
+	URL string `json:"url"`
+
+	// Port is the port ORY Hydra's admin server listens on.
+	Port int `json:"port"`
+
+	// Endpoint is the path of ORY Hydra's clients endpoint. If empty, it is
+	// detected from ORY Hydra's version.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// ForwardedProto, if set, is sent as the X-Forwarded-Proto header.
+	ForwardedProto string `json:"forwardedProto,omitempty"`
+
+	// Timeout of the requests to ORY Hydra, including the ones to the admin
+	// servers set by the resources. Defaults to 5s.
+	Timeout metav1.Duration `json:"timeout"`
+
+	// TLS configures the connections to ORY Hydra.
+	TLS TLSConfig `json:"tls"`
+
+	// Auth configures the credentials sent to ORY Hydra, e.g. to a proxy in
+	// front of its admin server.
+	Auth AuthConfig `json:"auth"`
+}
+
+// TLSConfig configures the TLS connections to ORY Hydra.
+type TLSConfig struct {
+	// TrustStore is the path of the PEM encoded certificates of the
+	// certificate authorities that ORY Hydra's certificate is verified with.
+	TrustStore string `json:"trustStore,omitempty"`
+
+	// InsecureSkipVerify disables the verification of ORY Hydra's
+	// certificate.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// AuthConfig configures the credentials sent to ORY Hydra. The credentials
+// are read from files, which are read again when they change, so that they
+// can be mounted from a Secret.
+type AuthConfig struct {
+	// BearerTokenFile is the path of the file holding the bearer token.
+	BearerTokenFile string `json:"bearerTokenFile,omitempty"`
+
+	// Username is the username of the basic authentication.
+	Username string `json:"username,omitempty"`
+
+	// PasswordFile is the path of the file holding the password of the basic
+	// authentication.
+	PasswordFile string `json:"passwordFile,omitempty"`
+}
+
+// SecretKeysConfig are the keys of the client credentials in the Secrets.
+type SecretKeysConfig struct {
+	// ClientID is the key of the client ID. Defaults to CLIENT_ID.
+	ClientID string `json:"clientId"`
+
+	// ClientSecret is the key of the client secret. Defaults to
+	// CLIENT_SECRET.
+	ClientSecret string `json:"clientSecret"`
+}
+
+// NamespacesConfig restricts the namespaces the controllers operate in. If
+// both fields are empty, all namespaces are watched.
+type NamespacesConfig struct {
+	// Watch is the list of namespaces to operate in.
+	Watch []string `json:"watch,omitempty"`
+
+	// Selector is the label selector of the namespaces to operate in, e.g.
+	// hydra.ory.sh/managed=true. Combined with Watch.
+	Selector string `json:"selector,omitempty"`
+}
+
+// ConcurrencyConfig is the number of resources of each kind reconciled in
+// parallel, each defaulting to 1.
+type ConcurrencyConfig struct {
+	OAuth2Clients          int `json:"oauth2Clients"`
+	JsonWebKeySets         int `json:"jsonWebKeySets"`
+	TrustedJwtGrantIssuers int `json:"trustedJwtGrantIssuers"`
+}
@@ -0,0 +1,110 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// JsonWebKeySetSpec defines the desired state of JsonWebKeySet
+type JsonWebKeySetSpec struct {
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9._-]+$`
+	// +kubebuilder:validation:XValidation:rule="!self.startsWith('hydra.')",message="the hydra. prefix is reserved for the key sets of ORY Hydra"
+	//
+	// SetName is the name of the JSON Web Key Set in ORY Hydra, e.g.
+	// `example.id-token`. The sets prefixed with `hydra.` are managed by ORY
+	// Hydra itself and are reserved. A set is owned by the oldest resource
+	// naming it, the others are not reconciled.
+	SetName string `json:"setName"`
+
+	// +kubebuilder:validation:Enum=RS256;RS512;ES256;ES512;EdDSA;HS256;HS512
+	//
+	// Algorithm is the algorithm of the key ORY Hydra generates.
+	Algorithm string `json:"algorithm"`
+
+	// +kubebuilder:validation:Enum=sig;enc
+	// +kubebuilder:default=sig
+	//
+	// Use is the intended use of the key, either `sig` or `enc`.
+	Use string `json:"use,omitempty"`
+
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9._-]+$`
+	//
+	// KeyID is the ID of the current key of the set. Changing it rotates the
+	// key: a new key is generated and the previous one is kept in the set
+	// so that what it signed can still be verified. Older keys are removed.
+	KeyID string `json:"keyId"`
+
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^$|^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	//
+	// ConfigMapName is the name of the ConfigMap the public JSON Web Key Set
+	// is published to, under the `jwks.json` key. Defaults to the name of the
+	// resource.
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^$|^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	//
+	// SecretName is the name of the Secret the current private key is
+	// published to, under the `jwk.json` key. The private key is not
+	// published if empty.
+	SecretName string `json:"secretName,omitempty"`
+
+	// HydraAdmin is the optional configuration to use for managing
+	// this key set
+	HydraAdmin HydraAdmin `json:"hydraAdmin,omitempty"`
+
+	// +kubebuilder:validation:Enum=delete;orphan
+	//
+	// Indicates if the key set should be deleted from ORY Hydra when the
+	// resource is deleted. Values can be 'delete' to delete the set or
+	// 'orphan' to keep it. Defaults to 'delete'.
+	DeletionPolicy OAuth2ClientDeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// JsonWebKeySetStatus defines the observed state of JsonWebKeySet
+type JsonWebKeySetStatus struct {
+	// ObservedGeneration represents the most recent generation observed by the controller.
+	ObservedGeneration  int64                   `json:"observedGeneration,omitempty"`
+	ReconciliationError ReconciliationError     `json:"reconciliationError,omitempty"`
+	Conditions          []OAuth2ClientCondition `json:"conditions,omitempty"`
+	// KeyID is the ID of the current key of the set.
+	KeyID string `json:"keyId,omitempty"`
+	// PreviousKeyID is the ID of the key that was current before the last
+	// rotation.
+	PreviousKeyID string `json:"previousKeyId,omitempty"`
+	// CreatedKeyIDs are the IDs of the keys of the set generated for this
+	// resource. Only these keys are ever deleted from ORY Hydra.
+	CreatedKeyIDs []string `json:"createdKeyIds,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// JsonWebKeySet is the Schema for the jsonwebkeysets API
+type JsonWebKeySet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   JsonWebKeySetSpec   `json:"spec,omitempty"`
+	Status JsonWebKeySetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// JsonWebKeySetList contains a list of JsonWebKeySet
+type JsonWebKeySetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []JsonWebKeySet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&JsonWebKeySet{}, &JsonWebKeySetList{})
+}
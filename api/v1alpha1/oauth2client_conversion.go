@@ -0,0 +1,8 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+// Hub marks OAuth2Client as the hub of the conversion between the versions of
+// the API, the other versions convert from and to it.
+func (*OAuth2Client) Hub() {}
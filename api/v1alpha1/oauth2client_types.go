@@ -4,6 +4,7 @@
 package v1alpha1
 
 import (
+	apiv1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -11,11 +12,22 @@ import (
 type StatusCode string
 
 const (
-	StatusRegistrationFailed  StatusCode = "CLIENT_REGISTRATION_FAILED"
-	StatusCreateSecretFailed  StatusCode = "SECRET_CREATION_FAILED"
-	StatusUpdateFailed        StatusCode = "CLIENT_UPDATE_FAILED"
-	StatusInvalidSecret       StatusCode = "INVALID_SECRET"
-	StatusInvalidHydraAddress StatusCode = "INVALID_HYDRA_ADDRESS"
+	StatusRegistrationFailed     StatusCode = "CLIENT_REGISTRATION_FAILED"
+	StatusCreateSecretFailed     StatusCode = "SECRET_CREATION_FAILED"
+	StatusUpdateFailed           StatusCode = "CLIENT_UPDATE_FAILED"
+	StatusInvalidSecret          StatusCode = "INVALID_SECRET"
+	StatusInvalidHydraAddress    StatusCode = "INVALID_HYDRA_ADDRESS"
+	StatusInvalidCredentialStore StatusCode = "INVALID_CREDENTIAL_STORE"
+	StatusUnsupportedField       StatusCode = "UNSUPPORTED_FIELD"
+	StatusInvalidTokenLifespans  StatusCode = "INVALID_TOKEN_LIFESPANS"
+	StatusKeyGenerationFailed    StatusCode = "KEY_GENERATION_FAILED"
+	StatusPublishKeysFailed      StatusCode = "KEYS_PUBLICATION_FAILED"
+	StatusTrustFailed            StatusCode = "TRUST_FAILED"
+	StatusInvalidJWK             StatusCode = "INVALID_JWK"
+	StatusLookupFailed           StatusCode = "CLIENT_LOOKUP_FAILED"
+	StatusPolicyViolation        StatusCode = "POLICY_VIOLATION"
+	StatusScopeDenied            StatusCode = "SCOPE_DENIED"
+	StatusKeySetConflict         StatusCode = "KEY_SET_CONFLICT"
 )
 
 // HydraAdmin defines the desired hydra admin instance to use for OAuth2Client
@@ -39,8 +51,8 @@ type HydraAdmin struct {
 	//
 	// Endpoint is the endpoint for the hydra instance on which
 	// to set up the client. This value will override the value
-	// provided to `--endpoint` (defaults to `"/clients"` in the
-	// application)
+	// provided to `--endpoint`. If both are empty, the endpoint is
+	// detected from ORY Hydra's version (`/admin/clients` as of v2)
 	Endpoint string `json:"endpoint,omitempty"`
 
 	// +kubebuilder:validation:Pattern=(^$|https?|off)
@@ -53,74 +65,107 @@ type HydraAdmin struct {
 
 // TokenLifespans defines the desired token durations by grant type for OAuth2Client
 type TokenLifespans struct {
-	// +kubebuilder:validation:Pattern=[0-9]+(ns|us|ms|s|m|h)
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|ms|s|m|h))+$`
 	//
 	// AuthorizationCodeGrantAccessTokenLifespan is the access token lifespan
 	// issued on an authorization_code grant.
 	AuthorizationCodeGrantAccessTokenLifespan string `json:"authorization_code_grant_access_token_lifespan,omitempty"`
 
-	// +kubebuilder:validation:Pattern=[0-9]+(ns|us|ms|s|m|h)
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|ms|s|m|h))+$`
 	//
 	// AuthorizationCodeGrantIdTokenLifespan is the id token lifespan
 	// issued on an authorization_code grant.
 	AuthorizationCodeGrantIdTokenLifespan string `json:"authorization_code_grant_id_token_lifespan,omitempty"`
 
-	// +kubebuilder:validation:Pattern=[0-9]+(ns|us|ms|s|m|h)
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|ms|s|m|h))+$`
 	//
 	// AuthorizationCodeGrantRefreshTokenLifespan is the refresh token lifespan
 	// issued on an authorization_code grant.
 	AuthorizationCodeGrantRefreshTokenLifespan string `json:"authorization_code_grant_refresh_token_lifespan,omitempty"`
 
-	// +kubebuilder:validation:Pattern=[0-9]+(ns|us|ms|s|m|h)
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|ms|s|m|h))+$`
 	//
 	// AuthorizationCodeGrantRefreshTokenLifespan is the access token lifespan
 	// issued on a client_credentials grant.
 	ClientCredentialsGrantAccessTokenLifespan string `json:"client_credentials_grant_access_token_lifespan,omitempty"`
 
-	// +kubebuilder:validation:Pattern=[0-9]+(ns|us|ms|s|m|h)
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|ms|s|m|h))+$`
+	//
+	// DeviceAuthorizationGrantAccessTokenLifespan is the access token lifespan
+	// issued on a device_code grant.
+	DeviceAuthorizationGrantAccessTokenLifespan string `json:"device_authorization_grant_access_token_lifespan,omitempty"`
+
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|ms|s|m|h))+$`
+	//
+	// DeviceAuthorizationGrantIdTokenLifespan is the id token lifespan
+	// issued on a device_code grant.
+	DeviceAuthorizationGrantIdTokenLifespan string `json:"device_authorization_grant_id_token_lifespan,omitempty"`
+
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|ms|s|m|h))+$`
+	//
+	// DeviceAuthorizationGrantRefreshTokenLifespan is the refresh token lifespan
+	// issued on a device_code grant.
+	DeviceAuthorizationGrantRefreshTokenLifespan string `json:"device_authorization_grant_refresh_token_lifespan,omitempty"`
+
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|ms|s|m|h))+$`
 	//
 	// ImplicitGrantAccessTokenLifespan is the access token lifespan
 	// issued on an implicit grant.
 	ImplicitGrantAccessTokenLifespan string `json:"implicit_grant_access_token_lifespan,omitempty"`
 
-	// +kubebuilder:validation:Pattern=[0-9]+(ns|us|ms|s|m|h)
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|ms|s|m|h))+$`
 	//
 	// ImplicitGrantIdTokenLifespan is the id token lifespan
 	// issued on an implicit grant.
 	ImplicitGrantIdTokenLifespan string `json:"implicit_grant_id_token_lifespan,omitempty"`
 
-	// +kubebuilder:validation:Pattern=[0-9]+(ns|us|ms|s|m|h)
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|ms|s|m|h))+$`
 	//
 	// JwtBearerGrantAccessTokenLifespan is the access token lifespan
 	// issued on a jwt_bearer grant.
 	JwtBearerGrantAccessTokenLifespan string `json:"jwt_bearer_grant_access_token_lifespan,omitempty"`
 
-	// +kubebuilder:validation:Pattern=[0-9]+(ns|us|ms|s|m|h)
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|ms|s|m|h))+$`
 	//
 	// RefreshTokenGrantAccessTokenLifespan is the access token lifespan
 	// issued on a refresh_token grant.
 	RefreshTokenGrantAccessTokenLifespan string `json:"refresh_token_grant_access_token_lifespan,omitempty"`
 
-	// +kubebuilder:validation:Pattern=[0-9]+(ns|us|ms|s|m|h)
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|ms|s|m|h))+$`
 	//
 	// RefreshTokenGrantIdTokenLifespan is the id token lifespan
 	// issued on a refresh_token grant.
 	RefreshTokenGrantIdTokenLifespan string `json:"refresh_token_grant_id_token_lifespan,omitempty"`
 
-	// +kubebuilder:validation:Pattern=[0-9]+(ns|us|ms|s|m|h)
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|ms|s|m|h))+$`
 	//
 	// RefreshTokenGrantRefreshTokenLifespan is the refresh token lifespan
 	// issued on a refresh_token grant.
 	RefreshTokenGrantRefreshTokenLifespan string `json:"refresh_token_grant_refresh_token_lifespan,omitempty"`
 }
 
+// +kubebuilder:validation:XValidation:rule="!has(self.jwksFrom) || !has(self.jwksUri) || self.jwksUri == ''",message="jwksUri and jwksFrom are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!self.grantTypes.exists(g, g == 'urn:ietf:params:oauth:grant-type:device_code') || self.grantTypes.exists(g, g == 'authorization_code' || g == 'implicit') || !has(self.responseTypes) || self.responseTypes.all(r, r == 'code')",message="clients using the device_code grant type without the authorization_code or implicit grant type only support the code response type"
+// +kubebuilder:validation:XValidation:rule="!has(self.keyPairGeneration) || (!has(self.jwksFrom) && (!has(self.jwksUri) || self.jwksUri == ''))",message="keyPairGeneration is mutually exclusive with jwksUri and jwksFrom"
+// +kubebuilder:validation:XValidation:rule="!has(self.keyPairGeneration) || (has(self.tokenEndpointAuthMethod) && self.tokenEndpointAuthMethod == 'private_key_jwt')",message="keyPairGeneration requires the private_key_jwt token endpoint auth method"
+// +kubebuilder:validation:XValidation:rule="!has(self.keyPairGeneration) || !has(self.credentialStore) || self.credentialStore == 'secret'",message="keyPairGeneration requires the secret credential store"
+
 // OAuth2ClientSpec defines the desired state of OAuth2Client
 type OAuth2ClientSpec struct {
 
 	// ClientName is the human-readable string name of the client to be presented to the end-user during authorization.
 	ClientName string `json:"clientName,omitempty"`
 
-	// +kubebuilder:validation:MaxItems=4
+	// +kubebuilder:validation:MaxLength=255
+	//
+	// ClientID is the ID under which the client is registered in ORY Hydra.
+	// It may be a template rendered with the resource's name and namespace,
+	// e.g. `{{ .Namespace }}-{{ .Name }}`. Only the client secret is generated
+	// when set. If empty, the ID is taken from the secret, the
+	// `--client-id-template` flag or generated.
+	ClientID string `json:"clientId,omitempty"`
+
+	// +kubebuilder:validation:MaxItems=6
 	// +kubebuilder:validation:MinItems=1
 	//
 	// GrantTypes is an array of grant types the client is allowed to use.
@@ -163,11 +208,21 @@ type OAuth2ClientSpec struct {
 
 	// +kubebuilder:validation:MinLength=1
 	// +kubebuilder:validation:MaxLength=253
-	// +kubebuilder:validation:Pattern=[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
 	//
 	// SecretName points to the K8s secret that contains this client's ID and password
 	SecretName string `json:"secretName"`
 
+	// +kubebuilder:validation:Enum=secret;http;file
+	//
+	// CredentialStore is the backend in which the client's ID and password are
+	// stored. Values can be 'secret' (the default) to use the K8s secret named by
+	// secretName, 'http' to use the key-value store configured with
+	// `--credential-store-http-url` or 'file' to use the directory configured
+	// with `--credential-store-file-dir`. The http and file backends use
+	// secretName as the key under the client's namespace.
+	CredentialStore CredentialStoreType `json:"credentialStore,omitempty"`
+
 	// SkipConsent skips the consent screen for this client.
 	// +kubebuilder:validation:type=bool
 	// +kubebuilder:default=false
@@ -177,6 +232,14 @@ type OAuth2ClientSpec struct {
 	// this client
 	HydraAdmin HydraAdmin `json:"hydraAdmin,omitempty"`
 
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	//
+	// ControllerClass selects the hydra-maester instance that manages this
+	// client, matching its `--controller-class` flag. If empty, the client is
+	// managed by the instances that have no controller class set.
+	ControllerClass string `json:"controllerClass,omitempty"`
+
 	// +kubebuilder:validation:Enum=client_secret_basic;client_secret_post;private_key_jwt;none
 	//
 	// Indication which authentication method should be used for the token endpoint
@@ -199,6 +262,19 @@ type OAuth2ClientSpec struct {
 	// JwksUri Define the URL where the JSON Web Key Set should be fetched from when performing the private_key_jwt client authentication method.
 	JwksUri string `json:"jwksUri,omitempty"`
 
+	// JwksFrom selects the key of a ConfigMap or Secret holding the JSON Web
+	// Key Set used when performing the private_key_jwt client authentication
+	// method. It is sent inline to ORY Hydra and the client is updated
+	// whenever it changes. Exclusive with JwksUri.
+	JwksFrom *JwksSource `json:"jwksFrom,omitempty"`
+
+	// KeyPairGeneration makes the controller generate the key pair the
+	// client authenticates with using the private_key_jwt method. The
+	// private key and its ID are stored in the client's secret and the
+	// public keys are sent inline to ORY Hydra. No client secret is
+	// generated. Exclusive with JwksUri and JwksFrom.
+	KeyPairGeneration *KeyPairGeneration `json:"keyPairGeneration,omitempty"`
+
 	// +kubebuilder:validation:type=bool
 	// +kubebuilder:default=false
 	//
@@ -229,6 +305,11 @@ type OAuth2ClientSpec struct {
 	// Values can be 'delete' to delete the OAuth2 client, value 'orphan' to keep an orphan oauth2 client.
 	DeletionPolicy OAuth2ClientDeletionPolicy `json:"deletionPolicy,omitempty"`
 
+	// RevocationPolicy makes the controller revoke the tokens of the client
+	// in ORY Hydra when it is deleted or re-keyed.
+	// Nothing is revoked if unset.
+	RevocationPolicy *RevocationPolicy `json:"revocationPolicy,omitempty"`
+
 	// +kubebuilder:validation:type=string
 	// +kubebuilder:validation:Pattern=`(^$|^https?://.*)`
 	//
@@ -303,9 +384,18 @@ type OAuth2ClientSpec struct {
 }
 
 // GrantType represents an OAuth 2.0 grant type
-// +kubebuilder:validation:Enum=client_credentials;authorization_code;implicit;refresh_token
+// +kubebuilder:validation:Enum=client_credentials;authorization_code;implicit;refresh_token;"urn:ietf:params:oauth:grant-type:jwt-bearer";"urn:ietf:params:oauth:grant-type:device_code"
 type GrantType string
 
+// GrantTypeJwtBearer is the grant type of the JWT Profile for OAuth 2.0
+// Authorization Grants (RFC 7523), which trusts the issuers managed with
+// TrustedJwtGrantIssuer resources.
+const GrantTypeJwtBearer GrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+
+// GrantTypeDeviceCode is the grant type of the OAuth 2.0 Device
+// Authorization Grant (RFC 8628), used by input-constrained devices.
+const GrantTypeDeviceCode GrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
 // ResponseType represents an OAuth 2.0 response type strings
 // +kubebuilder:validation:Enum=id_token;code;token;code token;code id_token;id_token token;code id_token token
 type ResponseType string
@@ -324,6 +414,103 @@ type OAuth2ClientStatus struct {
 	ObservedGeneration  int64                   `json:"observedGeneration,omitempty"`
 	ReconciliationError ReconciliationError     `json:"reconciliationError,omitempty"`
 	Conditions          []OAuth2ClientCondition `json:"conditions,omitempty"`
+	// ClientID is the ID under which the client is registered in ORY Hydra.
+	ClientID string `json:"clientId,omitempty"`
+	// Hydra is the address of the ORY Hydra admin server the client is
+	// registered in.
+	Hydra string `json:"hydra,omitempty"`
+	// CreatedAt is the time ORY Hydra reports the client was created at.
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+	// UpdatedAt is the time ORY Hydra reports the client was last updated
+	// at.
+	UpdatedAt *metav1.Time `json:"updatedAt,omitempty"`
+	// SecretName is the name of the Secret holding the client's credentials
+	// when the client was last synced.
+	SecretName string `json:"secretName,omitempty"`
+	// SecretResourceVersion is the resourceVersion of the Secret holding the
+	// client's credentials when the client was last synced.
+	SecretResourceVersion string `json:"secretResourceVersion,omitempty"`
+	// LastSyncTime is the time the client was last successfully synced with
+	// ORY Hydra.
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// ResyncAt is the value of the `hydra.ory.sh/resync-at` annotation when
+	// the client was last synced.
+	ResyncAt string `json:"resyncAt,omitempty"`
+	// RetryCount is the number of consecutive reconciliations that failed
+	// with a transient error, e.g. because ORY Hydra was unavailable.
+	RetryCount int32 `json:"retryCount,omitempty"`
+	// NextRetryTime is the time the reconciliation is retried at after a
+	// transient error.
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+	// SpecHash is a hash of the spec last applied in ORY Hydra, excluding the
+	// token lifespans. It allows to update only the lifespans when nothing
+	// else changed.
+	SpecHash string `json:"specHash,omitempty"`
+	// JwksHash is a hash of the JSON Web Key Set taken from spec.jwksFrom
+	// last applied in ORY Hydra.
+	JwksHash string `json:"jwksHash,omitempty"`
+	// KeyPair describes the key pair generated for the client, if any.
+	KeyPair *KeyPairStatus `json:"keyPair,omitempty"`
+	// Revocation describes the last revocation of the client's tokens, if
+	// any.
+	Revocation *RevocationStatus `json:"revocation,omitempty"`
+	// Replacement describes the ongoing replacement of the client registered
+	// in ORY Hydra by a new one, if any.
+	Replacement *ReplacementStatus `json:"replacement,omitempty"`
+	// HydraVersion is the version of the ORY Hydra instance the client is
+	// registered in.
+	HydraVersion string `json:"hydraVersion,omitempty"`
+	// Controller identifies the hydra-maester instance that last reconciled
+	// this client, as `<controller class>/<instance>` or `<instance>` if the
+	// instance has no controller class.
+	Controller string `json:"controller,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="has(self.configMapKeyRef) != has(self.secretKeyRef)",message="exactly one of configMapKeyRef and secretKeyRef must be set"
+
+// JwksSource selects the key of a ConfigMap or a Secret holding a JSON Web
+// Key Set
+type JwksSource struct {
+	// ConfigMapKeyRef selects the key of a ConfigMap in the namespace of the
+	// client.
+	ConfigMapKeyRef *apiv1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// SecretKeyRef selects the key of a Secret in the namespace of the
+	// client.
+	SecretKeyRef *apiv1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// KeyPairGeneration defines the key pair the controller generates for a client
+type KeyPairGeneration struct {
+	// +kubebuilder:validation:Enum=RS256;ES256;EdDSA
+	//
+	// Algorithm is the algorithm of the generated key pair.
+	Algorithm string `json:"algorithm"`
+
+	// +kubebuilder:default="720h"
+	//
+	// RotationPeriod is how often a new key pair is generated, as a Go
+	// duration. The key pair is never rotated if zero.
+	RotationPeriod metav1.Duration `json:"rotationPeriod,omitempty"`
+
+	// +kubebuilder:default="24h"
+	//
+	// OverlapPeriod is how long the public key of the previous key pair stays
+	// registered after a rotation, as a Go duration.
+	OverlapPeriod metav1.Duration `json:"overlapPeriod,omitempty"`
+}
+
+// KeyPairStatus describes the key pair generated for a client
+type KeyPairStatus struct {
+	// KeyID is the ID of the current key.
+	KeyID string `json:"keyId,omitempty"`
+	// RotatedAt is the time the current key was generated at.
+	RotatedAt *metav1.Time `json:"rotatedAt,omitempty"`
+	// PreviousKeyID is the ID of the previous key while it is still
+	// registered.
+	PreviousKeyID string `json:"previousKeyId,omitempty"`
+	// PreviousKeyExpiresAt is the time the previous key is unregistered at.
+	PreviousKeyExpiresAt *metav1.Time `json:"previousKeyExpiresAt,omitempty"`
 }
 
 // ReconciliationError represents an error that occurred during the reconciliation process
@@ -344,6 +531,22 @@ type OAuth2ClientConditionType string
 
 const (
 	OAuth2ClientConditionReady = "Ready"
+	// OAuth2ClientConditionPaused is true while the reconciliation of the
+	// client is paused with the `hydra.ory.sh/reconcile: paused` annotation.
+	OAuth2ClientConditionPaused = "Paused"
+	// OAuth2ClientConditionHydraReachable is false while the ORY Hydra admin
+	// server of the client cannot be reached or is not ready. It is only set
+	// when false.
+	OAuth2ClientConditionHydraReachable = "HydraReachable"
+)
+
+// CredentialStoreType represents the backend in which the credentials of an oauth2 client are stored.
+type CredentialStoreType string
+
+const (
+	CredentialStoreSecret CredentialStoreType = "secret"
+	CredentialStoreHTTP   CredentialStoreType = "http"
+	CredentialStoreFile   CredentialStoreType = "file"
 )
 
 // OAuth2ClientDeletionPolicy represents if a deleted oauth2 client object should delete the database row or not.
@@ -354,6 +557,85 @@ const (
 	OAuth2ClientDeletionPolicyOrphan = "orphan"
 )
 
+// RevocationPolicy defines when and what the controller revokes in ORY Hydra
+// for a client
+type RevocationPolicy struct {
+	// +kubebuilder:default={delete,rekey}
+	// +kubebuilder:validation:MinItems=1
+	//
+	// On lists the events triggering the revocation: 'delete' when the client
+	// is deleted from ORY Hydra, 'rekey' when its client ID, client secret
+	// or generated key pair changes.
+	On []RevocationTrigger `json:"on,omitempty"`
+
+	// +kubebuilder:default={tokens}
+	// +kubebuilder:validation:MinItems=1
+	//
+	// Revoke lists what is revoked: 'tokens' for the access and refresh
+	// tokens issued to the client. Consent sessions cannot be revoked per
+	// client, as ORY Hydra only revokes them per subject.
+	Revoke []RevocationTarget `json:"revoke,omitempty"`
+}
+
+// RevocationTrigger is an event triggering a revocation
+// +kubebuilder:validation:Enum=delete;rekey
+type RevocationTrigger string
+
+const (
+	RevocationOnDelete RevocationTrigger = "delete"
+	RevocationOnRekey  RevocationTrigger = "rekey"
+)
+
+// RevocationTarget is what a revocation revokes
+// +kubebuilder:validation:Enum=tokens
+type RevocationTarget string
+
+const (
+	RevokeTokens RevocationTarget = "tokens"
+)
+
+// RevocationStatus describes a revocation of a client's tokens
+type RevocationStatus struct {
+	// ClientID is the ID of the client the revocation applied to.
+	ClientID string `json:"clientId,omitempty"`
+	// Trigger is the event that triggered the revocation.
+	Trigger RevocationTrigger `json:"trigger,omitempty"`
+	// Time is the time of the revocation.
+	Time *metav1.Time `json:"time,omitempty"`
+	// Revoked lists what has been revoked.
+	Revoked []RevocationTarget `json:"revoked,omitempty"`
+	// Error describes why the revocation failed, if it did.
+	Error string `json:"error,omitempty"`
+}
+
+// ReplacementPhase is a step of the replacement of the clients registered in
+// ORY Hydra for an OAuth2Client by a new one
+type ReplacementPhase string
+
+const (
+	// ReplacementCreated means the new client is registered in ORY Hydra but
+	// its credentials are not persisted yet.
+	ReplacementCreated ReplacementPhase = "Created"
+	// ReplacementSecretUpdated means the credentials of the new client are
+	// persisted, and the previous clients are deleted once the grace period
+	// elapsed or the replacement has been acknowledged.
+	ReplacementSecretUpdated ReplacementPhase = "SecretUpdated"
+)
+
+// ReplacementStatus describes the replacement of the clients registered in
+// ORY Hydra for an OAuth2Client by a new one
+type ReplacementStatus struct {
+	// Phase is the current step of the replacement.
+	Phase ReplacementPhase `json:"phase"`
+	// ClientID is the ID of the new client.
+	ClientID string `json:"clientId"`
+	// PreviousClientIDs are the IDs of the clients being replaced.
+	PreviousClientIDs []string `json:"previousClientIds,omitempty"`
+	// SecretUpdateTime is the time the credentials of the new client were
+	// persisted.
+	SecretUpdateTime *metav1.Time `json:"secretUpdateTime,omitempty"`
+}
+
 // +kubebuilder:validation:Enum=True;False;Unknown
 type ConditionStatus string
 
@@ -365,6 +647,11 @@ const (
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="ClientID",type=string,JSONPath=`.status.clientId`
+// +kubebuilder:printcolumn:name="Hydra",type=string,JSONPath=`.status.hydra`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:storageversion
 
 // OAuth2Client is the Schema for the oauth2clients API
 type OAuth2Client struct {
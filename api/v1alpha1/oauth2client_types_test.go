@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
+	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -112,6 +113,30 @@ func TestCreateAPI(t *testing.T) {
 				"invalid lifespan refresh token id token":           func() { created.Spec.TokenLifespans.RefreshTokenGrantIdTokenLifespan = "invalid" },
 				"invalid lifespan refresh token refresh token":      func() { created.Spec.TokenLifespans.RefreshTokenGrantRefreshTokenLifespan = "invalid" },
 				"invalid deletion policy":                           func() { created.Spec.DeletionPolicy = "invalid" },
+				"invalid lifespan device authorization access token": func() {
+					created.Spec.TokenLifespans.DeviceAuthorizationGrantAccessTokenLifespan = "invalid"
+				},
+				"device code grant type with token response type": func() {
+					created.Spec.GrantTypes = []GrantType{GrantTypeDeviceCode, "refresh_token"}
+					created.Spec.ResponseTypes = []ResponseType{"code", "token"}
+				},
+				"jwks uri and jwks from": func() {
+					created.Spec.JwksUri = "https://ory.sh/jwks.json"
+					created.Spec.JwksFrom = &JwksSource{ConfigMapKeyRef: &apiv1.ConfigMapKeySelector{Key: "jwks.json"}}
+				},
+				"jwks from without reference": func() { created.Spec.JwksFrom = &JwksSource{} },
+				"key pair generation without private key jwt": func() {
+					created.Spec.KeyPairGeneration = &KeyPairGeneration{Algorithm: "ES256"}
+				},
+				"key pair generation with invalid algorithm": func() {
+					created.Spec.TokenEndpointAuthMethod = "private_key_jwt"
+					created.Spec.KeyPairGeneration = &KeyPairGeneration{Algorithm: "HS256"}
+				},
+				"key pair generation and jwks uri": func() {
+					created.Spec.TokenEndpointAuthMethod = "private_key_jwt"
+					created.Spec.JwksUri = "https://ory.sh/jwks.json"
+					created.Spec.KeyPairGeneration = &KeyPairGeneration{Algorithm: "ES256"}
+				},
 			} {
 				t.Run(fmt.Sprintf("case=%s", desc), func(t *testing.T) {
 					resetTestClient()
@@ -127,6 +152,20 @@ func TestCreateAPI(t *testing.T) {
 				"single response type": func() { created.Spec.ResponseTypes = []ResponseType{"token", "id_token", "code"} },
 				"double response type": func() { created.Spec.ResponseTypes = []ResponseType{"id_token token", "code id_token", "code token"} },
 				"triple response type": func() { created.Spec.ResponseTypes = []ResponseType{"code id_token token"} },
+				"jwt bearer grant type": func() {
+					created.Spec.GrantTypes = append(created.Spec.GrantTypes, GrantTypeJwtBearer)
+				},
+				"device code grant type": func() {
+					created.Spec.GrantTypes = []GrantType{GrantTypeDeviceCode, "refresh_token"}
+					created.Spec.ResponseTypes = []ResponseType{"code"}
+				},
+				"device code grant type with authorization code grant type": func() {
+					created.Spec.GrantTypes = append(created.Spec.GrantTypes, GrantTypeDeviceCode)
+				},
+				"key pair generation": func() {
+					created.Spec.TokenEndpointAuthMethod = "private_key_jwt"
+					created.Spec.KeyPairGeneration = &KeyPairGeneration{Algorithm: "EdDSA"}
+				},
 			} {
 				t.Run(fmt.Sprintf("case=%s", desc), func(t *testing.T) {
 					resetTestClient()
@@ -0,0 +1,104 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OAuth2ClientPolicySpec defines the constraints the oauth2 clients must
+// satisfy to be registered in ORY Hydra. The constraints left unset do not
+// restrict the clients. In the patterns, `*` matches any sequence of
+// characters.
+type OAuth2ClientPolicySpec struct {
+	// AllowedGrantTypes is the list of grant types the clients may use.
+	AllowedGrantTypes []GrantType `json:"allowedGrantTypes,omitempty"`
+
+	// AllowedScopes is the list of patterns of the scopes the clients may
+	// request, e.g. `orders.*`.
+	AllowedScopes []string `json:"allowedScopes,omitempty"`
+
+	// AllowedRedirectURIHosts is the list of patterns of the hosts of the
+	// redirect and post logout redirect URIs of the clients, e.g.
+	// `*.example.com`.
+	AllowedRedirectURIHosts []string `json:"allowedRedirectUriHosts,omitempty"`
+
+	// AllowedAudiences is the list of patterns of the audiences the clients
+	// may request.
+	AllowedAudiences []string `json:"allowedAudiences,omitempty"`
+
+	// AllowSkipConsent, if set to false, forbids the clients to skip the
+	// consent screen.
+	AllowSkipConsent *bool `json:"allowSkipConsent,omitempty"`
+
+	// MaxTokenLifespan is the maximum of the token lifespans of the clients,
+	// e.g. `1h`.
+	MaxTokenLifespan *metav1.Duration `json:"maxTokenLifespan,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	//
+	// MaxClientsPerNamespace is the maximum number of clients in a namespace.
+	// The clients created last are the ones rejected.
+	MaxClientsPerNamespace *int32 `json:"maxClientsPerNamespace,omitempty"`
+}
+
+// ClusterOAuth2ClientPolicySpec defines the constraints of the oauth2 clients
+// in the namespaces selected by the policy.
+type ClusterOAuth2ClientPolicySpec struct {
+	// NamespaceSelector selects the namespaces of the clients the policy
+	// applies to, by their labels. The policy applies to the clients of all
+	// namespaces if unset.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	OAuth2ClientPolicySpec `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+
+// OAuth2ClientPolicy constrains the oauth2 clients of its namespace. The
+// clients violating it are rejected by the validating webhook, if enabled,
+// and not registered in ORY Hydra.
+type OAuth2ClientPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec OAuth2ClientPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OAuth2ClientPolicyList contains a list of OAuth2ClientPolicy
+type OAuth2ClientPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OAuth2ClientPolicy `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// ClusterOAuth2ClientPolicy constrains the oauth2 clients of the namespaces
+// it selects, like an OAuth2ClientPolicy in each of them.
+type ClusterOAuth2ClientPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterOAuth2ClientPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterOAuth2ClientPolicyList contains a list of ClusterOAuth2ClientPolicy
+type ClusterOAuth2ClientPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterOAuth2ClientPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&OAuth2ClientPolicy{}, &OAuth2ClientPolicyList{},
+		&ClusterOAuth2ClientPolicy{}, &ClusterOAuth2ClientPolicyList{},
+	)
+}
@@ -0,0 +1,81 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OAuth2ScopeSpec declares a scope owned by a namespace.
+type OAuth2ScopeSpec struct {
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^\S+$`
+	//
+	// Scope is the scope owned by the namespace, e.g. `payments:write`.
+	Scope string `json:"scope"`
+
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	//
+	// Namespace is the namespace owning the scope.
+	Namespace string `json:"namespace"`
+
+	// Approvals lists the namespaces and clients that may request the scope,
+	// besides the clients of the owning namespace.
+	Approvals []OAuth2ScopeApproval `json:"approvals,omitempty"`
+}
+
+// OAuth2ScopeApproval approves the clients of a namespace to request a scope.
+type OAuth2ScopeApproval struct {
+	// +kubebuilder:validation:MinLength=1
+	//
+	// Namespace is the namespace of the approved clients, or `*` to approve
+	// the clients of all namespaces.
+	Namespace string `json:"namespace"`
+
+	// Clients is the list of names of the approved OAuth2Clients of the
+	// namespace. All the clients of the namespace are approved if empty.
+	Clients []string `json:"clients,omitempty"`
+}
+
+// OAuth2ScopeStatus defines the observed state of OAuth2Scope.
+type OAuth2ScopeStatus struct {
+	// ConflictsWith is the name of the OAuth2Scope declaring the same scope
+	// before this one. This OAuth2Scope is then ignored.
+	ConflictsWith string `json:"conflictsWith,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Scope",type=string,JSONPath=`.spec.scope`
+// +kubebuilder:printcolumn:name="Namespace",type=string,JSONPath=`.spec.namespace`
+// +kubebuilder:printcolumn:name="Conflicts With",type=string,JSONPath=`.status.conflictsWith`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// OAuth2Scope declares that a namespace owns a scope, and which other
+// namespaces and clients may request it. OAuth2Scopes are cluster-scoped, so
+// that only the cluster administrators assign scopes to namespaces. If
+// several OAuth2Scopes declare the same scope, the one created first owns it
+// and the other ones are ignored.
+type OAuth2Scope struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OAuth2ScopeSpec   `json:"spec,omitempty"`
+	Status OAuth2ScopeStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OAuth2ScopeList contains a list of OAuth2Scope
+type OAuth2ScopeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OAuth2Scope `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OAuth2Scope{}, &OAuth2ScopeList{})
+}
@@ -0,0 +1,112 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:validation:XValidation:rule="has(self.subject) != (has(self.allowAnySubject) && self.allowAnySubject)",message="exactly one of subject and allowAnySubject must be set"
+// +kubebuilder:validation:XValidation:rule="has(self.jwk) != has(self.jwkSecretRef)",message="exactly one of jwk and jwkSecretRef must be set"
+
+// TrustedJwtGrantIssuerSpec defines the desired state of TrustedJwtGrantIssuer
+type TrustedJwtGrantIssuerSpec struct {
+	// +kubebuilder:validation:MinLength=1
+	//
+	// Issuer is the issuer of the JWTs exchanged with the
+	// `urn:ietf:params:oauth:grant-type:jwt-bearer` grant type.
+	Issuer string `json:"issuer"`
+
+	// +kubebuilder:validation:MinLength=1
+	//
+	// Subject is the subject of the JWTs the issuer may issue.
+	Subject string `json:"subject,omitempty"`
+
+	// AllowAnySubject lets the issuer issue JWTs for any subject. Exclusive
+	// with Subject.
+	AllowAnySubject bool `json:"allowAnySubject,omitempty"`
+
+	// Scope is the list of scopes the issuer may request.
+	Scope []string `json:"scope,omitempty"`
+
+	// ExpiresAt is the time the trust relationship expires at.
+	ExpiresAt metav1.Time `json:"expiresAt"`
+
+	// +kubebuilder:validation:Type=object
+	// +optional
+	//
+	// JWK is the public JSON Web Key the JWTs of the issuer are signed with.
+	// Exclusive with JWKSecretRef.
+	JWK *apiextensionsv1.JSON `json:"jwk,omitempty"`
+
+	// JWKSecretRef selects the key of a Secret holding the public JSON Web
+	// Key the JWTs of the issuer are signed with. Exclusive with JWK.
+	JWKSecretRef *NamespacedSecretKeySelector `json:"jwkSecretRef,omitempty"`
+
+	// HydraAdmin is the optional configuration to use for managing
+	// this trust relationship
+	HydraAdmin HydraAdmin `json:"hydraAdmin,omitempty"`
+
+	// +kubebuilder:validation:Enum=delete;orphan
+	//
+	// Indicates if the trust relationship should be deleted from ORY Hydra
+	// when the resource is deleted. Values can be 'delete' to delete it or
+	// 'orphan' to keep it. Defaults to 'delete'.
+	DeletionPolicy OAuth2ClientDeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// NamespacedSecretKeySelector selects a key of a Secret of a namespace.
+type NamespacedSecretKeySelector struct {
+	apiv1.SecretKeySelector `json:",inline"`
+
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	//
+	// Namespace is the namespace of the Secret. The Secret is only read if
+	// the namespace is watched.
+	Namespace string `json:"namespace"`
+}
+
+// TrustedJwtGrantIssuerStatus defines the observed state of TrustedJwtGrantIssuer
+type TrustedJwtGrantIssuerStatus struct {
+	// ObservedGeneration represents the most recent generation observed by the controller.
+	ObservedGeneration  int64                   `json:"observedGeneration,omitempty"`
+	ReconciliationError ReconciliationError     `json:"reconciliationError,omitempty"`
+	Conditions          []OAuth2ClientCondition `json:"conditions,omitempty"`
+	// ID is the ID of the trust relationship in ORY Hydra.
+	ID string `json:"id,omitempty"`
+	// RequestHash is a hash of the trust relationship last created in ORY
+	// Hydra, including the JSON Web Key taken from the Secret.
+	RequestHash string `json:"requestHash,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+
+// TrustedJwtGrantIssuer is the Schema for the trustedjwtgrantissuers API.
+// The resources are cluster-scoped as a trusted issuer may request tokens for
+// any client and, with allowAnySubject, any subject of ORY Hydra.
+type TrustedJwtGrantIssuer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TrustedJwtGrantIssuerSpec   `json:"spec,omitempty"`
+	Status TrustedJwtGrantIssuerStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TrustedJwtGrantIssuerList contains a list of TrustedJwtGrantIssuer
+type TrustedJwtGrantIssuerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TrustedJwtGrantIssuer `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TrustedJwtGrantIssuer{}, &TrustedJwtGrantIssuerList{})
+}
@@ -22,9 +22,91 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterOAuth2ClientPolicy) DeepCopyInto(out *ClusterOAuth2ClientPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterOAuth2ClientPolicy.
+func (in *ClusterOAuth2ClientPolicy) DeepCopy() *ClusterOAuth2ClientPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterOAuth2ClientPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterOAuth2ClientPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterOAuth2ClientPolicyList) DeepCopyInto(out *ClusterOAuth2ClientPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterOAuth2ClientPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterOAuth2ClientPolicyList.
+func (in *ClusterOAuth2ClientPolicyList) DeepCopy() *ClusterOAuth2ClientPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterOAuth2ClientPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterOAuth2ClientPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterOAuth2ClientPolicySpec) DeepCopyInto(out *ClusterOAuth2ClientPolicySpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.OAuth2ClientPolicySpec.DeepCopyInto(&out.OAuth2ClientPolicySpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterOAuth2ClientPolicySpec.
+func (in *ClusterOAuth2ClientPolicySpec) DeepCopy() *ClusterOAuth2ClientPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterOAuth2ClientPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HydraAdmin) DeepCopyInto(out *HydraAdmin) {
 	*out = *in
@@ -40,6 +122,188 @@ func (in *HydraAdmin) DeepCopy() *HydraAdmin {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JsonWebKeySet) DeepCopyInto(out *JsonWebKeySet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JsonWebKeySet.
+func (in *JsonWebKeySet) DeepCopy() *JsonWebKeySet {
+	if in == nil {
+		return nil
+	}
+	out := new(JsonWebKeySet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *JsonWebKeySet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JsonWebKeySetList) DeepCopyInto(out *JsonWebKeySetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]JsonWebKeySet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JsonWebKeySetList.
+func (in *JsonWebKeySetList) DeepCopy() *JsonWebKeySetList {
+	if in == nil {
+		return nil
+	}
+	out := new(JsonWebKeySetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *JsonWebKeySetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JsonWebKeySetSpec) DeepCopyInto(out *JsonWebKeySetSpec) {
+	*out = *in
+	out.HydraAdmin = in.HydraAdmin
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JsonWebKeySetSpec.
+func (in *JsonWebKeySetSpec) DeepCopy() *JsonWebKeySetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JsonWebKeySetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JsonWebKeySetStatus) DeepCopyInto(out *JsonWebKeySetStatus) {
+	*out = *in
+	out.ReconciliationError = in.ReconciliationError
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]OAuth2ClientCondition, len(*in))
+		copy(*out, *in)
+	}
+	if in.CreatedKeyIDs != nil {
+		in, out := &in.CreatedKeyIDs, &out.CreatedKeyIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JsonWebKeySetStatus.
+func (in *JsonWebKeySetStatus) DeepCopy() *JsonWebKeySetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JsonWebKeySetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JwksSource) DeepCopyInto(out *JwksSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JwksSource.
+func (in *JwksSource) DeepCopy() *JwksSource {
+	if in == nil {
+		return nil
+	}
+	out := new(JwksSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyPairGeneration) DeepCopyInto(out *KeyPairGeneration) {
+	*out = *in
+	out.RotationPeriod = in.RotationPeriod
+	out.OverlapPeriod = in.OverlapPeriod
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyPairGeneration.
+func (in *KeyPairGeneration) DeepCopy() *KeyPairGeneration {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyPairGeneration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyPairStatus) DeepCopyInto(out *KeyPairStatus) {
+	*out = *in
+	if in.RotatedAt != nil {
+		in, out := &in.RotatedAt, &out.RotatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.PreviousKeyExpiresAt != nil {
+		in, out := &in.PreviousKeyExpiresAt, &out.PreviousKeyExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyPairStatus.
+func (in *KeyPairStatus) DeepCopy() *KeyPairStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyPairStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedSecretKeySelector) DeepCopyInto(out *NamespacedSecretKeySelector) {
+	*out = *in
+	in.SecretKeySelector.DeepCopyInto(&out.SecretKeySelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedSecretKeySelector.
+func (in *NamespacedSecretKeySelector) DeepCopy() *NamespacedSecretKeySelector {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedSecretKeySelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OAuth2Client) DeepCopyInto(out *OAuth2Client) {
 	*out = *in
@@ -114,6 +378,114 @@ func (in *OAuth2ClientList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2ClientPolicy) DeepCopyInto(out *OAuth2ClientPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2ClientPolicy.
+func (in *OAuth2ClientPolicy) DeepCopy() *OAuth2ClientPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2ClientPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OAuth2ClientPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2ClientPolicyList) DeepCopyInto(out *OAuth2ClientPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OAuth2ClientPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2ClientPolicyList.
+func (in *OAuth2ClientPolicyList) DeepCopy() *OAuth2ClientPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2ClientPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OAuth2ClientPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2ClientPolicySpec) DeepCopyInto(out *OAuth2ClientPolicySpec) {
+	*out = *in
+	if in.AllowedGrantTypes != nil {
+		in, out := &in.AllowedGrantTypes, &out.AllowedGrantTypes
+		*out = make([]GrantType, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedScopes != nil {
+		in, out := &in.AllowedScopes, &out.AllowedScopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedRedirectURIHosts != nil {
+		in, out := &in.AllowedRedirectURIHosts, &out.AllowedRedirectURIHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedAudiences != nil {
+		in, out := &in.AllowedAudiences, &out.AllowedAudiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowSkipConsent != nil {
+		in, out := &in.AllowSkipConsent, &out.AllowSkipConsent
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxTokenLifespan != nil {
+		in, out := &in.MaxTokenLifespan, &out.MaxTokenLifespan
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxClientsPerNamespace != nil {
+		in, out := &in.MaxClientsPerNamespace, &out.MaxClientsPerNamespace
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2ClientPolicySpec.
+func (in *OAuth2ClientPolicySpec) DeepCopy() *OAuth2ClientPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2ClientPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OAuth2ClientSpec) DeepCopyInto(out *OAuth2ClientSpec) {
 	*out = *in
@@ -160,6 +532,21 @@ func (in *OAuth2ClientSpec) DeepCopyInto(out *OAuth2ClientSpec) {
 	out.HydraAdmin = in.HydraAdmin
 	out.TokenLifespans = in.TokenLifespans
 	in.Metadata.DeepCopyInto(&out.Metadata)
+	if in.JwksFrom != nil {
+		in, out := &in.JwksFrom, &out.JwksFrom
+		*out = new(JwksSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KeyPairGeneration != nil {
+		in, out := &in.KeyPairGeneration, &out.KeyPairGeneration
+		*out = new(KeyPairGeneration)
+		**out = **in
+	}
+	if in.RevocationPolicy != nil {
+		in, out := &in.RevocationPolicy, &out.RevocationPolicy
+		*out = new(RevocationPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Contacts != nil {
 		in, out := &in.Contacts, &out.Contacts
 		*out = make([]string, len(*in))
@@ -186,6 +573,37 @@ func (in *OAuth2ClientStatus) DeepCopyInto(out *OAuth2ClientStatus) {
 		*out = make([]OAuth2ClientCondition, len(*in))
 		copy(*out, *in)
 	}
+	if in.CreatedAt != nil {
+		in, out := &in.CreatedAt, &out.CreatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.UpdatedAt != nil {
+		in, out := &in.UpdatedAt, &out.UpdatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.KeyPair != nil {
+		in, out := &in.KeyPair, &out.KeyPair
+		*out = new(KeyPairStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Revocation != nil {
+		in, out := &in.Revocation, &out.Revocation
+		*out = new(RevocationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replacement != nil {
+		in, out := &in.Replacement, &out.Replacement
+		*out = new(ReplacementStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2ClientStatus.
@@ -198,6 +616,122 @@ func (in *OAuth2ClientStatus) DeepCopy() *OAuth2ClientStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2Scope) DeepCopyInto(out *OAuth2Scope) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2Scope.
+func (in *OAuth2Scope) DeepCopy() *OAuth2Scope {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2Scope)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OAuth2Scope) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2ScopeApproval) DeepCopyInto(out *OAuth2ScopeApproval) {
+	*out = *in
+	if in.Clients != nil {
+		in, out := &in.Clients, &out.Clients
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2ScopeApproval.
+func (in *OAuth2ScopeApproval) DeepCopy() *OAuth2ScopeApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2ScopeApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2ScopeList) DeepCopyInto(out *OAuth2ScopeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OAuth2Scope, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2ScopeList.
+func (in *OAuth2ScopeList) DeepCopy() *OAuth2ScopeList {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2ScopeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OAuth2ScopeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2ScopeSpec) DeepCopyInto(out *OAuth2ScopeSpec) {
+	*out = *in
+	if in.Approvals != nil {
+		in, out := &in.Approvals, &out.Approvals
+		*out = make([]OAuth2ScopeApproval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2ScopeSpec.
+func (in *OAuth2ScopeSpec) DeepCopy() *OAuth2ScopeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2ScopeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2ScopeStatus) DeepCopyInto(out *OAuth2ScopeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2ScopeStatus.
+func (in *OAuth2ScopeStatus) DeepCopy() *OAuth2ScopeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2ScopeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReconciliationError) DeepCopyInto(out *ReconciliationError) {
 	*out = *in
@@ -213,6 +747,79 @@ func (in *ReconciliationError) DeepCopy() *ReconciliationError {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplacementStatus) DeepCopyInto(out *ReplacementStatus) {
+	*out = *in
+	if in.PreviousClientIDs != nil {
+		in, out := &in.PreviousClientIDs, &out.PreviousClientIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretUpdateTime != nil {
+		in, out := &in.SecretUpdateTime, &out.SecretUpdateTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplacementStatus.
+func (in *ReplacementStatus) DeepCopy() *ReplacementStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplacementStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RevocationPolicy) DeepCopyInto(out *RevocationPolicy) {
+	*out = *in
+	if in.On != nil {
+		in, out := &in.On, &out.On
+		*out = make([]RevocationTrigger, len(*in))
+		copy(*out, *in)
+	}
+	if in.Revoke != nil {
+		in, out := &in.Revoke, &out.Revoke
+		*out = make([]RevocationTarget, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevocationPolicy.
+func (in *RevocationPolicy) DeepCopy() *RevocationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RevocationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RevocationStatus) DeepCopyInto(out *RevocationStatus) {
+	*out = *in
+	if in.Time != nil {
+		in, out := &in.Time, &out.Time
+		*out = (*in).DeepCopy()
+	}
+	if in.Revoked != nil {
+		in, out := &in.Revoked, &out.Revoked
+		*out = make([]RevocationTarget, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevocationStatus.
+func (in *RevocationStatus) DeepCopy() *RevocationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RevocationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TokenLifespans) DeepCopyInto(out *TokenLifespans) {
 	*out = *in
@@ -227,3 +834,115 @@ func (in *TokenLifespans) DeepCopy() *TokenLifespans {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustedJwtGrantIssuer) DeepCopyInto(out *TrustedJwtGrantIssuer) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustedJwtGrantIssuer.
+func (in *TrustedJwtGrantIssuer) DeepCopy() *TrustedJwtGrantIssuer {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustedJwtGrantIssuer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TrustedJwtGrantIssuer) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustedJwtGrantIssuerList) DeepCopyInto(out *TrustedJwtGrantIssuerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TrustedJwtGrantIssuer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustedJwtGrantIssuerList.
+func (in *TrustedJwtGrantIssuerList) DeepCopy() *TrustedJwtGrantIssuerList {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustedJwtGrantIssuerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TrustedJwtGrantIssuerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustedJwtGrantIssuerSpec) DeepCopyInto(out *TrustedJwtGrantIssuerSpec) {
+	*out = *in
+	if in.Scope != nil {
+		in, out := &in.Scope, &out.Scope
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.ExpiresAt.DeepCopyInto(&out.ExpiresAt)
+	if in.JWK != nil {
+		in, out := &in.JWK, &out.JWK
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JWKSecretRef != nil {
+		in, out := &in.JWKSecretRef, &out.JWKSecretRef
+		*out = new(NamespacedSecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	out.HydraAdmin = in.HydraAdmin
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustedJwtGrantIssuerSpec.
+func (in *TrustedJwtGrantIssuerSpec) DeepCopy() *TrustedJwtGrantIssuerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustedJwtGrantIssuerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustedJwtGrantIssuerStatus) DeepCopyInto(out *TrustedJwtGrantIssuerStatus) {
+	*out = *in
+	out.ReconciliationError = in.ReconciliationError
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]OAuth2ClientCondition, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustedJwtGrantIssuerStatus.
+func (in *TrustedJwtGrantIssuerStatus) DeepCopy() *TrustedJwtGrantIssuerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustedJwtGrantIssuerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
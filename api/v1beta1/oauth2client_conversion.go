@@ -0,0 +1,373 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+)
+
+const (
+	// SpecAnnotation holds the v1alpha1 spec of a client whose spec can not
+	// be derived from its v1beta1 spec, e.g. when both scope and scopeArray
+	// are set. It is restored as long as the v1beta1 spec is not changed.
+	SpecAnnotation = "hydra.ory.sh/v1alpha1-spec"
+	// StatusAnnotation holds the v1alpha1 status of a client whose status can
+	// not be derived from its v1beta1 status. It is restored as long as the
+	// v1beta1 status is not changed.
+	StatusAnnotation = "hydra.ory.sh/v1alpha1-status"
+)
+
+var _ conversion.Convertible = &OAuth2Client{}
+
+// ConvertTo converts this OAuth2Client to the hub version, v1alpha1.
+func (src *OAuth2Client) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*hydrav1alpha1.OAuth2Client)
+	if !ok {
+		return fmt.Errorf("unsupported conversion of OAuth2Client to %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec = convertSpecTo(&src.Spec)
+	dst.Status = convertStatusTo(&src.Status)
+
+	if stored, ok := dst.Annotations[SpecAnnotation]; ok {
+		var spec hydrav1alpha1.OAuth2ClientSpec
+		if err := json.Unmarshal([]byte(stored), &spec); err == nil && equality.Semantic.DeepEqual(convertSpecFrom(&spec), src.Spec) {
+			dst.Spec = spec
+		}
+	}
+	if stored, ok := dst.Annotations[StatusAnnotation]; ok {
+		var status hydrav1alpha1.OAuth2ClientStatus
+		if err := json.Unmarshal([]byte(stored), &status); err == nil && equality.Semantic.DeepEqual(convertStatusFrom(&status, src.CreationTimestamp), src.Status) {
+			dst.Status = status
+		}
+	}
+	removeAnnotations(&dst.ObjectMeta)
+
+	return nil
+}
+
+// ConvertFrom converts from the hub version, v1alpha1, to this version. The
+// parts of the hub version that can not be converted are kept in
+// annotations.
+func (dst *OAuth2Client) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*hydrav1alpha1.OAuth2Client)
+	if !ok {
+		return fmt.Errorf("unsupported conversion of %T to OAuth2Client", srcRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec = convertSpecFrom(&src.Spec)
+	dst.Status = convertStatusFrom(&src.Status, src.CreationTimestamp)
+	removeAnnotations(&dst.ObjectMeta)
+
+	if !equality.Semantic.DeepEqual(convertSpecTo(&dst.Spec), src.Spec) {
+		if err := setAnnotation(&dst.ObjectMeta, SpecAnnotation, src.Spec); err != nil {
+			return err
+		}
+	}
+	if !equality.Semantic.DeepEqual(convertStatusTo(&dst.Status), src.Status) {
+		if err := setAnnotation(&dst.ObjectMeta, StatusAnnotation, src.Status); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func convertSpecTo(src *OAuth2ClientSpec) hydrav1alpha1.OAuth2ClientSpec {
+	in := src.DeepCopy()
+
+	var admin hydrav1alpha1.HydraAdmin
+	if in.Hydra != nil {
+		admin.URL, admin.Port = splitPort(in.Hydra.URL)
+		admin.Endpoint = in.Hydra.Endpoint
+		admin.ForwardedProto = in.Hydra.ForwardedProto
+	}
+
+	return hydrav1alpha1.OAuth2ClientSpec{
+		ClientName:                        in.ClientName,
+		ClientID:                          in.ClientID,
+		GrantTypes:                        in.GrantTypes,
+		ResponseTypes:                     in.ResponseTypes,
+		RedirectURIs:                      toRedirectURIs(in.RedirectURIs),
+		RequestURIs:                       toRedirectURIs(in.RequestURIs),
+		PostLogoutRedirectURIs:            toRedirectURIs(in.PostLogoutRedirectURIs),
+		AllowedCorsOrigins:                toRedirectURIs(in.AllowedCorsOrigins),
+		Audience:                          in.Audience,
+		ScopeArray:                        in.Scopes,
+		SecretName:                        in.SecretName,
+		CredentialStore:                   in.CredentialStore,
+		SkipConsent:                       in.SkipConsent,
+		HydraAdmin:                        admin,
+		ControllerClass:                   in.ControllerClass,
+		TokenEndpointAuthMethod:           in.TokenEndpointAuthMethod,
+		TokenLifespans:                    in.TokenLifespans,
+		Metadata:                          in.Metadata,
+		JwksUri:                           in.JwksUri,
+		JwksFrom:                          in.JwksFrom,
+		KeyPairGeneration:                 in.KeyPairGeneration,
+		FrontChannelLogoutSessionRequired: in.FrontChannelLogoutSessionRequired,
+		FrontChannelLogoutURI:             in.FrontChannelLogoutURI,
+		BackChannelLogoutSessionRequired:  in.BackChannelLogoutSessionRequired,
+		BackChannelLogoutURI:              in.BackChannelLogoutURI,
+		DeletionPolicy:                    in.DeletionPolicy,
+		RevocationPolicy:                  in.RevocationPolicy,
+		LogoUri:                           in.LogoUri,
+		AccessTokenStrategy:               in.AccessTokenStrategy,
+		ClientSecretExpiresAt:             in.ClientSecretExpiresAt,
+		ClientUri:                         in.ClientUri,
+		Contacts:                          in.Contacts,
+		PolicyUri:                         in.PolicyUri,
+		RequestObjectSigningAlg:           in.RequestObjectSigningAlg,
+		SectorIdentifierUri:               in.SectorIdentifierUri,
+		SkipLogoutConsent:                 in.SkipLogoutConsent,
+		SubjectType:                       in.SubjectType,
+		TokenEndpointAuthSigningAlg:       in.TokenEndpointAuthSigningAlg,
+		TosUri:                            in.TosUri,
+		UserinfoSignedResponseAlg:         in.UserinfoSignedResponseAlg,
+	}
+}
+
+func convertSpecFrom(src *hydrav1alpha1.OAuth2ClientSpec) OAuth2ClientSpec {
+	in := src.DeepCopy()
+
+	var hydra *HydraConnection
+	if in.HydraAdmin != (hydrav1alpha1.HydraAdmin{}) {
+		hydra = &HydraConnection{
+			URL:            joinPort(in.HydraAdmin.URL, in.HydraAdmin.Port),
+			Endpoint:       in.HydraAdmin.Endpoint,
+			ForwardedProto: in.HydraAdmin.ForwardedProto,
+		}
+	}
+
+	// ORY Hydra is sent the scope array followed by the scope string
+	scopes := in.ScopeArray
+	if fields := strings.Fields(in.Scope); len(fields) > 0 {
+		scopes = append(scopes, fields...)
+	}
+
+	return OAuth2ClientSpec{
+		ClientName:                        in.ClientName,
+		ClientID:                          in.ClientID,
+		GrantTypes:                        in.GrantTypes,
+		ResponseTypes:                     in.ResponseTypes,
+		RedirectURIs:                      toStrings(in.RedirectURIs),
+		RequestURIs:                       toStrings(in.RequestURIs),
+		PostLogoutRedirectURIs:            toStrings(in.PostLogoutRedirectURIs),
+		AllowedCorsOrigins:                toStrings(in.AllowedCorsOrigins),
+		Audience:                          in.Audience,
+		Scopes:                            scopes,
+		SecretName:                        in.SecretName,
+		CredentialStore:                   in.CredentialStore,
+		SkipConsent:                       in.SkipConsent,
+		Hydra:                             hydra,
+		ControllerClass:                   in.ControllerClass,
+		TokenEndpointAuthMethod:           in.TokenEndpointAuthMethod,
+		TokenLifespans:                    in.TokenLifespans,
+		Metadata:                          in.Metadata,
+		JwksUri:                           in.JwksUri,
+		JwksFrom:                          in.JwksFrom,
+		KeyPairGeneration:                 in.KeyPairGeneration,
+		FrontChannelLogoutSessionRequired: in.FrontChannelLogoutSessionRequired,
+		FrontChannelLogoutURI:             in.FrontChannelLogoutURI,
+		BackChannelLogoutSessionRequired:  in.BackChannelLogoutSessionRequired,
+		BackChannelLogoutURI:              in.BackChannelLogoutURI,
+		DeletionPolicy:                    in.DeletionPolicy,
+		RevocationPolicy:                  in.RevocationPolicy,
+		LogoUri:                           in.LogoUri,
+		AccessTokenStrategy:               in.AccessTokenStrategy,
+		ClientSecretExpiresAt:             in.ClientSecretExpiresAt,
+		ClientUri:                         in.ClientUri,
+		Contacts:                          in.Contacts,
+		PolicyUri:                         in.PolicyUri,
+		RequestObjectSigningAlg:           in.RequestObjectSigningAlg,
+		SectorIdentifierUri:               in.SectorIdentifierUri,
+		SkipLogoutConsent:                 in.SkipLogoutConsent,
+		SubjectType:                       in.SubjectType,
+		TokenEndpointAuthSigningAlg:       in.TokenEndpointAuthSigningAlg,
+		TosUri:                            in.TosUri,
+		UserinfoSignedResponseAlg:         in.UserinfoSignedResponseAlg,
+	}
+}
+
+func convertStatusTo(src *OAuth2ClientStatus) hydrav1alpha1.OAuth2ClientStatus {
+	in := src.DeepCopy()
+
+	out := hydrav1alpha1.OAuth2ClientStatus{
+		ObservedGeneration:    in.ObservedGeneration,
+		ClientID:              in.ClientID,
+		Hydra:                 in.Hydra,
+		CreatedAt:             in.CreatedAt,
+		UpdatedAt:             in.UpdatedAt,
+		SecretName:            in.SecretName,
+		SecretResourceVersion: in.SecretResourceVersion,
+		LastSyncTime:          in.LastSyncTime,
+		ResyncAt:              in.ResyncAt,
+		RetryCount:            in.RetryCount,
+		NextRetryTime:         in.NextRetryTime,
+		SpecHash:              in.SpecHash,
+		JwksHash:              in.JwksHash,
+		KeyPair:               in.KeyPair,
+		Revocation:            in.Revocation,
+		Replacement:           in.Replacement,
+		HydraVersion:          in.HydraVersion,
+		Controller:            in.Controller,
+	}
+	for _, c := range in.Conditions {
+		out.Conditions = append(out.Conditions, hydrav1alpha1.OAuth2ClientCondition{
+			Type:   hydrav1alpha1.OAuth2ClientConditionType(c.Type),
+			Status: hydrav1alpha1.ConditionStatus(c.Status),
+		})
+		if c.Type == ConditionReady && c.Status != metav1.ConditionTrue && c.Reason != ReasonNotReconciled {
+			out.ReconciliationError = hydrav1alpha1.ReconciliationError{
+				Code:        hydrav1alpha1.StatusCode(c.Reason),
+				Description: c.Message,
+			}
+		}
+	}
+
+	return out
+}
+
+// convertStatusFrom converts a v1alpha1 status, which does not record when
+// the conditions changed. Their last transition is set to the given time.
+func convertStatusFrom(src *hydrav1alpha1.OAuth2ClientStatus, transition metav1.Time) OAuth2ClientStatus {
+	in := src.DeepCopy()
+
+	out := OAuth2ClientStatus{
+		ObservedGeneration:    in.ObservedGeneration,
+		ClientID:              in.ClientID,
+		Hydra:                 in.Hydra,
+		CreatedAt:             in.CreatedAt,
+		UpdatedAt:             in.UpdatedAt,
+		SecretName:            in.SecretName,
+		SecretResourceVersion: in.SecretResourceVersion,
+		LastSyncTime:          in.LastSyncTime,
+		ResyncAt:              in.ResyncAt,
+		RetryCount:            in.RetryCount,
+		NextRetryTime:         in.NextRetryTime,
+		SpecHash:              in.SpecHash,
+		JwksHash:              in.JwksHash,
+		KeyPair:               in.KeyPair,
+		Revocation:            in.Revocation,
+		Replacement:           in.Replacement,
+		HydraVersion:          in.HydraVersion,
+		Controller:            in.Controller,
+	}
+	for _, c := range in.Conditions {
+		condition := metav1.Condition{
+			Type:               string(c.Type),
+			Status:             metav1.ConditionStatus(c.Status),
+			ObservedGeneration: in.ObservedGeneration,
+			LastTransitionTime: transition,
+		}
+		switch {
+		case c.Type != ConditionReady && c.Status == hydrav1alpha1.ConditionTrue:
+			// e.g. Paused
+			condition.Reason = string(c.Type)
+		case c.Type != ConditionReady:
+			condition.Reason = "Not" + string(c.Type)
+		case c.Status == hydrav1alpha1.ConditionTrue:
+			condition.Reason = ReasonReconciled
+		case in.ReconciliationError.Code != "":
+			condition.Reason = string(in.ReconciliationError.Code)
+			condition.Message = in.ReconciliationError.Description
+		default:
+			condition.Reason = ReasonNotReconciled
+		}
+		out.Conditions = append(out.Conditions, condition)
+	}
+
+	return out
+}
+
+// joinPort adds the port to the URL, unless it has none or the URL already
+// has one.
+func joinPort(rawURL string, port int) string {
+	if port == 0 {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || u.Port() != "" {
+		return rawURL
+	}
+	u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(port))
+	return u.String()
+}
+
+// splitPort removes the port from the URL and returns it, if joining them
+// again gives the same URL.
+func splitPort(rawURL string) (string, int) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Port() == "" {
+		return rawURL, 0
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil || port <= 0 {
+		return rawURL, 0
+	}
+	u.Host = u.Hostname()
+	if strings.Contains(u.Host, ":") {
+		u.Host = "[" + u.Host + "]"
+	}
+	if joinPort(u.String(), port) != rawURL {
+		return rawURL, 0
+	}
+	return u.String(), port
+}
+
+func toStrings(uris []hydrav1alpha1.RedirectURI) []string {
+	if uris == nil {
+		return nil
+	}
+	out := make([]string, 0, len(uris))
+	for _, uri := range uris {
+		out = append(out, string(uri))
+	}
+	return out
+}
+
+func toRedirectURIs(uris []string) []hydrav1alpha1.RedirectURI {
+	if uris == nil {
+		return nil
+	}
+	out := make([]hydrav1alpha1.RedirectURI, 0, len(uris))
+	for _, uri := range uris {
+		out = append(out, hydrav1alpha1.RedirectURI(uri))
+	}
+	return out
+}
+
+func setAnnotation(meta *metav1.ObjectMeta, key string, value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("unable to store %s of OAuth2Client %s/%s: %w", key, meta.Namespace, meta.Name, err)
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[key] = string(b)
+	return nil
+}
+
+func removeAnnotations(meta *metav1.ObjectMeta) {
+	for _, key := range []string{SpecAnnotation, StatusAnnotation} {
+		delete(meta.Annotations, key)
+	}
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+}
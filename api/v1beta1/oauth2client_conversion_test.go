@@ -0,0 +1,207 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package v1beta1_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/randfill"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	hydrav1beta1 "github.com/ory/hydra-maester/api/v1beta1"
+)
+
+// filler fills v1alpha1 clients with random values that survive being
+// marshaled to JSON, as they are when stored by the API server.
+func filler(seed int64) *randfill.Filler {
+	return randfill.NewWithSeed(seed).NilChance(0.2).NumElements(0, 3).Funcs(
+		func(j *apiextensionsv1.JSON, c randfill.Continue) {
+			if c.Bool() {
+				*j = apiextensionsv1.JSON{}
+				return
+			}
+			j.Raw, _ = json.Marshal(map[string]string{c.String(0): c.String(0)})
+		},
+		func(t *metav1.Time, c randfill.Continue) {
+			*t = metav1.Unix(c.Int63n(1<<32), 0)
+		},
+		func(m *metav1.ObjectMeta, c randfill.Continue) {
+			m.Name = c.String(0)
+			m.Namespace = c.String(0)
+			c.Fill(&m.Labels)
+			c.Fill(&m.Annotations)
+			c.Fill(&m.CreationTimestamp)
+		},
+	)
+}
+
+func roundTrip(t *testing.T, hub *hydrav1alpha1.OAuth2Client) (*hydrav1beta1.OAuth2Client, *hydrav1alpha1.OAuth2Client) {
+	spoke := &hydrav1beta1.OAuth2Client{}
+	require.NoError(t, spoke.ConvertFrom(hub.DeepCopy()))
+
+	// the API server stores the converted object as JSON
+	b, err := json.Marshal(spoke)
+	require.NoError(t, err)
+	spoke = &hydrav1beta1.OAuth2Client{}
+	require.NoError(t, json.Unmarshal(b, spoke))
+
+	restored := &hydrav1alpha1.OAuth2Client{}
+	require.NoError(t, spoke.DeepCopy().ConvertTo(restored))
+	return spoke, restored
+}
+
+func TestConversion(t *testing.T) {
+	t.Run("should not lose any v1alpha1 field in a round trip", func(t *testing.T) {
+		for seed := int64(0); seed < 1000; seed++ {
+			hub := &hydrav1alpha1.OAuth2Client{}
+			filler(seed).Fill(hub)
+
+			_, restored := roundTrip(t, hub)
+			assert.True(t, equality.Semantic.DeepEqual(hub.ObjectMeta, restored.ObjectMeta), "seed %d: metadata differs", seed)
+			assert.True(t, equality.Semantic.DeepEqual(hub.Spec, restored.Spec), "seed %d: spec differs:\n%#v\n%#v", seed, hub.Spec, restored.Spec)
+			assert.True(t, equality.Semantic.DeepEqual(hub.Status, restored.Status), "seed %d: status differs:\n%#v\n%#v", seed, hub.Status, restored.Status)
+		}
+	})
+
+	t.Run("should not annotate clients that convert without loss", func(t *testing.T) {
+		for seed := int64(0); seed < 1000; seed++ {
+			hub := &hydrav1alpha1.OAuth2Client{}
+			filler(seed).Fill(hub)
+
+			hub.Spec.Scope = ""
+			hub.Spec.HydraAdmin = hydrav1alpha1.HydraAdmin{}
+			if seed%2 == 0 {
+				hub.Spec.HydraAdmin = hydrav1alpha1.HydraAdmin{URL: "http://hydra-admin", Port: 4445, Endpoint: "/admin/clients", ForwardedProto: "https"}
+			}
+			hub.Status.Conditions = []hydrav1alpha1.OAuth2ClientCondition{{Type: hydrav1alpha1.OAuth2ClientConditionReady, Status: hydrav1alpha1.ConditionTrue}}
+			hub.Status.ReconciliationError = hydrav1alpha1.ReconciliationError{}
+			if seed%3 == 0 {
+				hub.Status.Conditions[0].Status = hydrav1alpha1.ConditionFalse
+				hub.Status.ReconciliationError = hydrav1alpha1.ReconciliationError{Code: hydrav1alpha1.StatusRegistrationFailed, Description: "oops"}
+			}
+
+			spoke, restored := roundTrip(t, hub)
+			assert.NotContains(t, spoke.Annotations, hydrav1beta1.SpecAnnotation, "seed %d", seed)
+			assert.NotContains(t, spoke.Annotations, hydrav1beta1.StatusAnnotation, "seed %d", seed)
+			assert.True(t, equality.Semantic.DeepEqual(hub.Spec, restored.Spec), "seed %d: spec differs:\n%#v\n%#v", seed, hub.Spec, restored.Spec)
+			assert.True(t, equality.Semantic.DeepEqual(hub.Status, restored.Status), "seed %d: status differs:\n%#v\n%#v", seed, hub.Status, restored.Status)
+		}
+	})
+
+	t.Run("should convert the v1alpha1 fields to their v1beta1 counterparts", func(t *testing.T) {
+		created := metav1.NewTime(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+		hub := &hydrav1alpha1.OAuth2Client{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", CreationTimestamp: created},
+			Spec: hydrav1alpha1.OAuth2ClientSpec{
+				GrantTypes:         []hydrav1alpha1.GrantType{"client_credentials"},
+				RedirectURIs:       []hydrav1alpha1.RedirectURI{"https://example.com/callback"},
+				AllowedCorsOrigins: []hydrav1alpha1.RedirectURI{"https://example.com"},
+				Scope:              "read write",
+				ScopeArray:         []string{"openid"},
+				SecretName:         "foo-secret",
+				HydraAdmin: hydrav1alpha1.HydraAdmin{
+					URL:      "http://hydra-admin.hydra",
+					Port:     4445,
+					Endpoint: "/admin/clients",
+				},
+			},
+			Status: hydrav1alpha1.OAuth2ClientStatus{
+				ObservedGeneration: 2,
+				ReconciliationError: hydrav1alpha1.ReconciliationError{
+					Code:        hydrav1alpha1.StatusInvalidSecret,
+					Description: "secret is invalid",
+				},
+				Conditions: []hydrav1alpha1.OAuth2ClientCondition{{
+					Type:   hydrav1alpha1.OAuth2ClientConditionReady,
+					Status: hydrav1alpha1.ConditionFalse,
+				}},
+			},
+		}
+
+		spoke := &hydrav1beta1.OAuth2Client{}
+		require.NoError(t, spoke.ConvertFrom(hub))
+
+		assert.Equal(t, []string{"openid", "read", "write"}, spoke.Spec.Scopes)
+		assert.Equal(t, []string{"https://example.com/callback"}, spoke.Spec.RedirectURIs)
+		assert.Equal(t, []string{"https://example.com"}, spoke.Spec.AllowedCorsOrigins)
+		assert.Equal(t, &hydrav1beta1.HydraConnection{URL: "http://hydra-admin.hydra:4445", Endpoint: "/admin/clients"}, spoke.Spec.Hydra)
+		assert.Equal(t, []metav1.Condition{{
+			Type:               hydrav1beta1.ConditionReady,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: 2,
+			LastTransitionTime: created,
+			Reason:             string(hydrav1alpha1.StatusInvalidSecret),
+			Message:            "secret is invalid",
+		}}, spoke.Status.Conditions)
+		assert.Contains(t, spoke.Annotations, hydrav1beta1.SpecAnnotation)
+		assert.NotContains(t, spoke.Annotations, hydrav1beta1.StatusAnnotation)
+
+		restored := &hydrav1alpha1.OAuth2Client{}
+		require.NoError(t, spoke.DeepCopy().ConvertTo(restored))
+		assert.Equal(t, hub.Spec, restored.Spec)
+		assert.Equal(t, hub.Status, restored.Status)
+		assert.Empty(t, restored.Annotations)
+
+		t.Run("and drop the stored spec once the v1beta1 spec changed", func(t *testing.T) {
+			changed := spoke.DeepCopy()
+			changed.Spec.Scopes = []string{"openid", "read"}
+
+			restored := &hydrav1alpha1.OAuth2Client{}
+			require.NoError(t, changed.ConvertTo(restored))
+			assert.Empty(t, restored.Spec.Scope)
+			assert.Equal(t, []string{"openid", "read"}, restored.Spec.ScopeArray)
+			assert.Equal(t, hydrav1alpha1.HydraAdmin{URL: "http://hydra-admin.hydra", Port: 4445, Endpoint: "/admin/clients"}, restored.Spec.HydraAdmin)
+			assert.Empty(t, restored.Annotations)
+		})
+	})
+
+	t.Run("should not lose any v1beta1 spec field in a round trip", func(t *testing.T) {
+		for desc, spec := range map[string]hydrav1beta1.OAuth2ClientSpec{
+			"minimal": {
+				GrantTypes: []hydrav1alpha1.GrantType{"client_credentials"},
+				SecretName: "foo-secret",
+			},
+			"with scopes and URIs": {
+				GrantTypes:             []hydrav1alpha1.GrantType{"authorization_code"},
+				Scopes:                 []string{"openid", "offline"},
+				RedirectURIs:           []string{"https://example.com/callback"},
+				RequestURIs:            []string{"https://example.com/request"},
+				PostLogoutRedirectURIs: []string{"https://example.com/logout"},
+				AllowedCorsOrigins:     []string{"https://example.com"},
+				SecretName:             "foo-secret",
+			},
+			"with a hydra connection": {
+				GrantTypes: []hydrav1alpha1.GrantType{"client_credentials"},
+				SecretName: "foo-secret",
+				Hydra:      &hydrav1beta1.HydraConnection{URL: "https://[::1]:4445", ForwardedProto: "off"},
+			},
+			"with a hydra connection without a port": {
+				GrantTypes: []hydrav1alpha1.GrantType{"client_credentials"},
+				SecretName: "foo-secret",
+				Hydra:      &hydrav1beta1.HydraConnection{URL: "https://hydra.example.com/prefix", Endpoint: "/clients"},
+			},
+		} {
+			t.Run("case="+desc, func(t *testing.T) {
+				spoke := &hydrav1beta1.OAuth2Client{
+					ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+					Spec:       spec,
+				}
+
+				hub := &hydrav1alpha1.OAuth2Client{}
+				require.NoError(t, spoke.DeepCopy().ConvertTo(hub))
+				restored := &hydrav1beta1.OAuth2Client{}
+				require.NoError(t, restored.ConvertFrom(hub))
+
+				assert.Equal(t, spoke, restored)
+			})
+		}
+	})
+}
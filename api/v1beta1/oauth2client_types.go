@@ -0,0 +1,383 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package v1beta1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+)
+
+const (
+	// ConditionReady is the condition type reporting whether the client is
+	// registered in ORY Hydra as specified.
+	ConditionReady = "Ready"
+
+	// ReasonReconciled is the reason of the Ready condition of a client
+	// registered in ORY Hydra as specified. When the reconciliation fails,
+	// the reason is the status code of the failure.
+	ReasonReconciled = "Reconciled"
+	// ReasonNotReconciled is the reason of the Ready condition of a client
+	// that is not ready for no known reason.
+	ReasonNotReconciled = "NotReconciled"
+
+	// ConditionPaused is the condition type reporting whether the
+	// reconciliation of the client is paused. Its reason is its type if true.
+	ConditionPaused = "Paused"
+)
+
+// HydraConnection defines the ORY Hydra admin server a client is registered in
+type HydraConnection struct {
+	// +kubebuilder:validation:MaxLength=256
+	// +kubebuilder:validation:Pattern=`(^$|^https?://.*)`
+	//
+	// URL is the URL of the ORY Hydra admin server, including its port,
+	// e.g. `http://hydra-admin:4445`. If empty, the server configured with
+	// the `--hydra-*` flags is used and the other fields are ignored.
+	URL string `json:"url,omitempty"`
+
+	// +kubebuilder:validation:Pattern=(^$|^/.*)
+	//
+	// Endpoint is the path of the client endpoint of the ORY Hydra admin
+	// server. If empty, it is detected from ORY Hydra's version
+	// (`/admin/clients` as of v2).
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// +kubebuilder:validation:Pattern=(^$|https?|off)
+	//
+	// ForwardedProto overrides the `--forwarded-proto` flag. The
+	// value "off" will force this to be off even if
+	// `--forwarded-proto` is specified
+	ForwardedProto string `json:"forwardedProto,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="!has(self.jwksFrom) || !has(self.jwksUri) || self.jwksUri == ''",message="jwksUri and jwksFrom are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!self.grantTypes.exists(g, g == 'urn:ietf:params:oauth:grant-type:device_code') || self.grantTypes.exists(g, g == 'authorization_code' || g == 'implicit') || !has(self.responseTypes) || self.responseTypes.all(r, r == 'code')",message="clients using the device_code grant type without the authorization_code or implicit grant type only support the code response type"
+// +kubebuilder:validation:XValidation:rule="!has(self.keyPairGeneration) || (!has(self.jwksFrom) && (!has(self.jwksUri) || self.jwksUri == ''))",message="keyPairGeneration is mutually exclusive with jwksUri and jwksFrom"
+// +kubebuilder:validation:XValidation:rule="!has(self.keyPairGeneration) || (has(self.tokenEndpointAuthMethod) && self.tokenEndpointAuthMethod == 'private_key_jwt')",message="keyPairGeneration requires the private_key_jwt token endpoint auth method"
+// +kubebuilder:validation:XValidation:rule="!has(self.keyPairGeneration) || !has(self.credentialStore) || self.credentialStore == 'secret'",message="keyPairGeneration requires the secret credential store"
+
+// OAuth2ClientSpec defines the desired state of OAuth2Client
+type OAuth2ClientSpec struct {
+
+	// ClientName is the human-readable string name of the client to be presented to the end-user during authorization.
+	ClientName string `json:"clientName,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=255
+	//
+	// ClientID is the ID under which the client is registered in ORY Hydra.
+	// It may be a template rendered with the resource's name and namespace,
+	// e.g. `{{ .Namespace }}-{{ .Name }}`. Only the client secret is generated
+	// when set. If empty, the ID is taken from the secret, the
+	// `--client-id-template` flag or generated.
+	ClientID string `json:"clientId,omitempty"`
+
+	// +kubebuilder:validation:MaxItems=6
+	// +kubebuilder:validation:MinItems=1
+	//
+	// GrantTypes is an array of grant types the client is allowed to use.
+	GrantTypes []hydrav1alpha1.GrantType `json:"grantTypes"`
+
+	// +kubebuilder:validation:MaxItems=3
+	// +kubebuilder:validation:MinItems=1
+	//
+	// ResponseTypes is an array of the OAuth 2.0 response type strings that the client can
+	// use at the authorization endpoint.
+	ResponseTypes []hydrav1alpha1.ResponseType `json:"responseTypes,omitempty"`
+
+	// +kubebuilder:validation:items:Pattern=`\w+:/?/?[^\s]+`
+	//
+	// RedirectURIs is an array of the redirect URIs allowed for the application
+	RedirectURIs []string `json:"redirectUris,omitempty"`
+
+	// +kubebuilder:validation:items:Pattern=`\w+:/?/?[^\s]+`
+	//
+	// RequestURIs is an array of request URIs that can be used in authorization requests
+	RequestURIs []string `json:"requestUris,omitempty"`
+
+	// +kubebuilder:validation:items:Pattern=`\w+:/?/?[^\s]+`
+	//
+	// PostLogoutRedirectURIs is an array of the post logout redirect URIs allowed for the application
+	PostLogoutRedirectURIs []string `json:"postLogoutRedirectUris,omitempty"`
+
+	// +kubebuilder:validation:items:Pattern=`\w+:/?/?[^\s]+`
+	//
+	// AllowedCorsOrigins is an array of allowed CORS origins
+	AllowedCorsOrigins []string `json:"allowedCorsOrigins,omitempty"`
+
+	// Audience is a whitelist defining the audiences this client is allowed to request tokens for
+	Audience []string `json:"audience,omitempty"`
+
+	// Scopes is an array of scope values (as described in Section 3.3 of
+	// OAuth 2.0 [RFC6749]) that the client can use when requesting access
+	// tokens.
+	Scopes []string `json:"scopes,omitempty"`
+
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	//
+	// SecretName points to the K8s secret that contains this client's ID and password
+	SecretName string `json:"secretName"`
+
+	// +kubebuilder:validation:Enum=secret;http;file
+	//
+	// CredentialStore is the backend in which the client's ID and password are
+	// stored. Values can be 'secret' (the default) to use the K8s secret named by
+	// secretName, 'http' to use the key-value store configured with
+	// `--credential-store-http-url` or 'file' to use the directory configured
+	// with `--credential-store-file-dir`. The http and file backends use
+	// secretName as the key under the client's namespace.
+	CredentialStore hydrav1alpha1.CredentialStoreType `json:"credentialStore,omitempty"`
+
+	// SkipConsent skips the consent screen for this client.
+	// +kubebuilder:validation:type=bool
+	// +kubebuilder:default=false
+	SkipConsent bool `json:"skipConsent,omitempty"`
+
+	// Hydra is the ORY Hydra admin server the client is registered in. If
+	// unset, the server configured with the `--hydra-*` flags is used.
+	Hydra *HydraConnection `json:"hydra,omitempty"`
+
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	//
+	// ControllerClass selects the hydra-maester instance that manages this
+	// client, matching its `--controller-class` flag. If empty, the client is
+	// managed by the instances that have no controller class set.
+	ControllerClass string `json:"controllerClass,omitempty"`
+
+	// +kubebuilder:validation:Enum=client_secret_basic;client_secret_post;private_key_jwt;none
+	//
+	// Indication which authentication method should be used for the token endpoint
+	TokenEndpointAuthMethod hydrav1alpha1.TokenEndpointAuthMethod `json:"tokenEndpointAuthMethod,omitempty"`
+
+	// TokenLifespans is the configuration to use for managing different token lifespans
+	// depending on the used grant type.
+	TokenLifespans hydrav1alpha1.TokenLifespans `json:"tokenLifespans,omitempty"`
+
+	// +kubebuilder:validation:Type=object
+	// +nullable
+	// +optional
+	//
+	// Metadata is arbitrary data
+	Metadata apiextensionsv1.JSON `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:type=string
+	// +kubebuilder:validation:Pattern=`(^$|^https?://.*)`
+	//
+	// JwksUri Define the URL where the JSON Web Key Set should be fetched from when performing the private_key_jwt client authentication method.
+	JwksUri string `json:"jwksUri,omitempty"`
+
+	// JwksFrom selects the key of a ConfigMap or Secret holding the JSON Web
+	// Key Set used when performing the private_key_jwt client authentication
+	// method. It is sent inline to ORY Hydra and the client is updated
+	// whenever it changes. Exclusive with JwksUri.
+	JwksFrom *hydrav1alpha1.JwksSource `json:"jwksFrom,omitempty"`
+
+	// KeyPairGeneration makes the controller generate the key pair the
+	// client authenticates with using the private_key_jwt method. The
+	// private key and its ID are stored in the client's secret and the
+	// public keys are sent inline to ORY Hydra. No client secret is
+	// generated. Exclusive with JwksUri and JwksFrom.
+	KeyPairGeneration *hydrav1alpha1.KeyPairGeneration `json:"keyPairGeneration,omitempty"`
+
+	// +kubebuilder:validation:type=bool
+	// +kubebuilder:default=false
+	//
+	// FrontChannelLogoutSessionRequired Boolean value specifying whether the RP requires that iss (issuer) and sid (session ID) query parameters be included to identify the RP session with the OP when the frontchannel_logout_uri is used
+	FrontChannelLogoutSessionRequired bool `json:"frontChannelLogoutSessionRequired,omitempty"`
+
+	// +kubebuilder:validation:type=string
+	// +kubebuilder:validation:Pattern=`(^$|^https?://.*)`
+	//
+	// FrontChannelLogoutURI RP URL that will cause the RP to log itself out when rendered in an iframe by the OP. An iss (issuer) query parameter and a sid (session ID) query parameter MAY be included by the OP to enable the RP to validate the request and to determine which of the potentially multiple sessions is to be logged out; if either is included, both MUST be
+	FrontChannelLogoutURI string `json:"frontChannelLogoutURI,omitempty"`
+
+	// +kubebuilder:validation:type=bool
+	// +kubebuilder:default=false
+	//
+	// BackChannelLogoutSessionRequired Boolean value specifying whether the RP requires that a sid (session ID) Claim be included in the Logout Token to identify the RP session with the OP when the backchannel_logout_uri is used. If omitted, the default value is false.
+	BackChannelLogoutSessionRequired bool `json:"backChannelLogoutSessionRequired,omitempty"`
+
+	// +kubebuilder:validation:type=string
+	// +kubebuilder:validation:Pattern=`(^$|^https?://.*)`
+	//
+	// BackChannelLogoutURI RP URL that will cause the RP to log itself out when sent a Logout Token by the OP
+	BackChannelLogoutURI string `json:"backChannelLogoutURI,omitempty"`
+
+	// +kubebuilder:validation:Enum=delete;orphan
+	//
+	// Indicates if a deleted OAuth2Client custom resource should delete the database row or not.
+	// Values can be 'delete' to delete the OAuth2 client, value 'orphan' to keep an orphan oauth2 client.
+	DeletionPolicy hydrav1alpha1.OAuth2ClientDeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// RevocationPolicy makes the controller revoke the tokens of the client
+	// in ORY Hydra when it is deleted or re-keyed.
+	// Nothing is revoked if unset.
+	RevocationPolicy *hydrav1alpha1.RevocationPolicy `json:"revocationPolicy,omitempty"`
+
+	// +kubebuilder:validation:type=string
+	// +kubebuilder:validation:Pattern=`(^$|^https?://.*)`
+	//
+	// LogoUri is the URI to the logo of the client.
+	// This is used to display the logo in the consent screen.
+	// It should be a valid URL pointing to an image.
+	LogoUri string `json:"logoUri,omitempty"`
+
+	// +kubebuilder:validation:Enum=jwt;opaque
+	//
+	// AccessTokenStrategy is the OAuth 2.0 Access Token Strategy
+	AccessTokenStrategy string `json:"accessTokenStrategy,omitempty"`
+
+	// +kubebuilder:validation:type=integer
+	// +kubebuilder:validation:Minimum=0
+	//
+	// ClientSecretExpiresAt is the timestamp when the client secret expires (currently always 0)
+	ClientSecretExpiresAt int64 `json:"clientSecretExpiresAt,omitempty"`
+
+	// +kubebuilder:validation:type=string
+	// +kubebuilder:validation:Pattern=`(^$|^https?://.*)`
+	//
+	// ClientUri is a URL string of a web page providing information about the client
+	ClientUri string `json:"clientUri,omitempty"`
+
+	// Contacts is an array of strings representing ways to contact people responsible for this client
+	Contacts []string `json:"contacts,omitempty"`
+
+	// +kubebuilder:validation:type=string
+	// +kubebuilder:validation:Pattern=`(^$|^https?://.*)`
+	//
+	// PolicyUri is a URL string that points to a human-readable privacy policy document
+	PolicyUri string `json:"policyUri,omitempty"`
+
+	// +kubebuilder:validation:type=string
+	//
+	// RequestObjectSigningAlg is the algorithm that must be used for signing request objects
+	RequestObjectSigningAlg string `json:"requestObjectSigningAlg,omitempty"`
+
+	// +kubebuilder:validation:type=string
+	// +kubebuilder:validation:Pattern=`(^$|^https?://.*)`
+	//
+	// SectorIdentifierUri is a URL using the https scheme to be used in calculating Pseudonymous Identifiers
+	SectorIdentifierUri string `json:"sectorIdentifierUri,omitempty"`
+
+	// +kubebuilder:validation:type=bool
+	// +kubebuilder:default=false
+	//
+	// SkipLogoutConsent skips asking the user to confirm the logout request
+	SkipLogoutConsent bool `json:"skipLogoutConsent,omitempty"`
+
+	// +kubebuilder:validation:Enum=public;pairwise
+	//
+	// SubjectType is the requested subject type
+	SubjectType string `json:"subjectType,omitempty"`
+
+	// +kubebuilder:validation:type=string
+	//
+	// TokenEndpointAuthSigningAlg is the algorithm used to sign JWT tokens for client authentication
+	TokenEndpointAuthSigningAlg string `json:"tokenEndpointAuthSigningAlg,omitempty"`
+
+	// +kubebuilder:validation:type=string
+	// +kubebuilder:validation:Pattern=`(^$|^https?://.*)`
+	//
+	// TosUri is a URL string that points to a human-readable terms of service document
+	TosUri string `json:"tosUri,omitempty"`
+
+	// +kubebuilder:validation:type=string
+	//
+	// UserinfoSignedResponseAlg is the algorithm used to sign UserInfo responses
+	UserinfoSignedResponseAlg string `json:"userinfoSignedResponseAlg,omitempty"`
+}
+
+// OAuth2ClientStatus defines the observed state of OAuth2Client
+type OAuth2ClientStatus struct {
+	// ObservedGeneration is the most recent generation observed by the
+	// controller.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the client. The Ready condition is
+	// false with the status code of the failure as reason and its
+	// description as message if the reconciliation failed.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ClientID is the ID under which the client is registered in ORY Hydra.
+	ClientID string `json:"clientId,omitempty"`
+	// Hydra is the address of the ORY Hydra admin server the client is
+	// registered in.
+	Hydra string `json:"hydra,omitempty"`
+	// CreatedAt is the time ORY Hydra reports the client was created at.
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+	// UpdatedAt is the time ORY Hydra reports the client was last updated
+	// at.
+	UpdatedAt *metav1.Time `json:"updatedAt,omitempty"`
+	// SecretName is the name of the Secret holding the client's credentials
+	// when the client was last synced.
+	SecretName string `json:"secretName,omitempty"`
+	// SecretResourceVersion is the resourceVersion of the Secret holding the
+	// client's credentials when the client was last synced.
+	SecretResourceVersion string `json:"secretResourceVersion,omitempty"`
+	// LastSyncTime is the time the client was last successfully synced with
+	// ORY Hydra.
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// ResyncAt is the value of the `hydra.ory.sh/resync-at` annotation when
+	// the client was last synced.
+	ResyncAt string `json:"resyncAt,omitempty"`
+	// RetryCount is the number of consecutive reconciliations that failed
+	// with a transient error, e.g. because ORY Hydra was unavailable.
+	RetryCount int32 `json:"retryCount,omitempty"`
+	// NextRetryTime is the time the reconciliation is retried at after a
+	// transient error.
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+	// SpecHash is a hash of the spec last applied in ORY Hydra, excluding the
+	// token lifespans. It allows to update only the lifespans when nothing
+	// else changed.
+	SpecHash string `json:"specHash,omitempty"`
+	// JwksHash is a hash of the JSON Web Key Set taken from spec.jwksFrom
+	// last applied in ORY Hydra.
+	JwksHash string `json:"jwksHash,omitempty"`
+	// KeyPair describes the key pair generated for the client, if any.
+	KeyPair *hydrav1alpha1.KeyPairStatus `json:"keyPair,omitempty"`
+	// Revocation describes the last revocation of the client's tokens, if
+	// any.
+	Revocation *hydrav1alpha1.RevocationStatus `json:"revocation,omitempty"`
+	// Replacement describes the ongoing replacement of the client registered
+	// in ORY Hydra by a new one, if any.
+	Replacement *hydrav1alpha1.ReplacementStatus `json:"replacement,omitempty"`
+	// HydraVersion is the version of the ORY Hydra instance the client is
+	// registered in.
+	HydraVersion string `json:"hydraVersion,omitempty"`
+	// Controller identifies the hydra-maester instance that last reconciled
+	// this client, as `<controller class>/<instance>` or `<instance>` if the
+	// instance has no controller class.
+	Controller string `json:"controller,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="ClientID",type=string,JSONPath=`.status.clientId`
+// +kubebuilder:printcolumn:name="Hydra",type=string,JSONPath=`.status.hydra`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// OAuth2Client is the Schema for the oauth2clients API
+type OAuth2Client struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OAuth2ClientSpec   `json:"spec,omitempty"`
+	Status OAuth2ClientStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OAuth2ClientList contains a list of OAuth2Client
+type OAuth2ClientList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OAuth2Client `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OAuth2Client{}, &OAuth2ClientList{})
+}
@@ -0,0 +1,237 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright © 2023 Ory Corp
+SPDX-License-Identifier: Apache-2.0
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"github.com/ory/hydra-maester/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HydraConnection) DeepCopyInto(out *HydraConnection) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HydraConnection.
+func (in *HydraConnection) DeepCopy() *HydraConnection {
+	if in == nil {
+		return nil
+	}
+	out := new(HydraConnection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2Client) DeepCopyInto(out *OAuth2Client) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2Client.
+func (in *OAuth2Client) DeepCopy() *OAuth2Client {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2Client)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OAuth2Client) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2ClientList) DeepCopyInto(out *OAuth2ClientList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OAuth2Client, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2ClientList.
+func (in *OAuth2ClientList) DeepCopy() *OAuth2ClientList {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2ClientList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OAuth2ClientList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2ClientSpec) DeepCopyInto(out *OAuth2ClientSpec) {
+	*out = *in
+	if in.GrantTypes != nil {
+		in, out := &in.GrantTypes, &out.GrantTypes
+		*out = make([]v1alpha1.GrantType, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResponseTypes != nil {
+		in, out := &in.ResponseTypes, &out.ResponseTypes
+		*out = make([]v1alpha1.ResponseType, len(*in))
+		copy(*out, *in)
+	}
+	if in.RedirectURIs != nil {
+		in, out := &in.RedirectURIs, &out.RedirectURIs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequestURIs != nil {
+		in, out := &in.RequestURIs, &out.RequestURIs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PostLogoutRedirectURIs != nil {
+		in, out := &in.PostLogoutRedirectURIs, &out.PostLogoutRedirectURIs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedCorsOrigins != nil {
+		in, out := &in.AllowedCorsOrigins, &out.AllowedCorsOrigins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Audience != nil {
+		in, out := &in.Audience, &out.Audience
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hydra != nil {
+		in, out := &in.Hydra, &out.Hydra
+		*out = new(HydraConnection)
+		**out = **in
+	}
+	out.TokenLifespans = in.TokenLifespans
+	in.Metadata.DeepCopyInto(&out.Metadata)
+	if in.JwksFrom != nil {
+		in, out := &in.JwksFrom, &out.JwksFrom
+		*out = new(v1alpha1.JwksSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KeyPairGeneration != nil {
+		in, out := &in.KeyPairGeneration, &out.KeyPairGeneration
+		*out = new(v1alpha1.KeyPairGeneration)
+		**out = **in
+	}
+	if in.RevocationPolicy != nil {
+		in, out := &in.RevocationPolicy, &out.RevocationPolicy
+		*out = new(v1alpha1.RevocationPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Contacts != nil {
+		in, out := &in.Contacts, &out.Contacts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2ClientSpec.
+func (in *OAuth2ClientSpec) DeepCopy() *OAuth2ClientSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2ClientSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2ClientStatus) DeepCopyInto(out *OAuth2ClientStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CreatedAt != nil {
+		in, out := &in.CreatedAt, &out.CreatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.UpdatedAt != nil {
+		in, out := &in.UpdatedAt, &out.UpdatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.KeyPair != nil {
+		in, out := &in.KeyPair, &out.KeyPair
+		*out = new(v1alpha1.KeyPairStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Revocation != nil {
+		in, out := &in.Revocation, &out.Revocation
+		*out = new(v1alpha1.RevocationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replacement != nil {
+		in, out := &in.Replacement, &out.Replacement
+		*out = new(v1alpha1.ReplacementStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2ClientStatus.
+func (in *OAuth2ClientStatus) DeepCopy() *OAuth2ClientStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2ClientStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,525 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+const (
+	// JWKSKey is the ConfigMap key the public JSON Web Key Set is published under.
+	JWKSKey = "jwks.json"
+	// JWKKey is the Secret key the current private key is published under.
+	JWKKey = "jwk.json"
+	// KeyIDKey is the Secret key the ID of the current key is published under.
+	KeyIDKey = "kid"
+
+	// reservedSetPrefix prefixes the key sets ORY Hydra manages itself.
+	reservedSetPrefix = "hydra."
+)
+
+// errKeySetConflict is returned when a key set or one of its keys belongs to
+// something else than the reconciled resource.
+var errKeySetConflict = errors.New("key set conflict")
+
+// KeysClientFactory is a function that creates JSON Web Key clients.
+// The JsonWebKeySetReconciler defaults to use hydra.NewKeysClient and the
+// factory allows to override this behavior for mocks during tests.
+type KeysClientFactory func(
+	admin hydrav1alpha1.HydraAdmin,
+	tlsTrustStore string,
+	insecureSkipVerify bool,
+) (hydra.KeysClient, error)
+
+// JsonWebKeySetReconciler reconciles a JsonWebKeySet object.
+type JsonWebKeySetReconciler struct {
+	client.Client
+	KeysClient hydra.KeysClient
+	Log        logr.Logger
+
+	keysClients       map[clientKey]hydra.KeysClient
+	keysClientFactory KeysClientFactory
+	reader            client.Reader
+	mu                sync.Mutex
+}
+
+// JsonWebKeySetOptions represent options to pass to the JSON Web Key Set
+// reconciler.
+type JsonWebKeySetOptions struct {
+	KeysClientFactory KeysClientFactory
+	// Reader lists the resources claiming the same key set. Defaults to the
+	// client of the reconciler.
+	Reader client.Reader
+}
+
+// JsonWebKeySetOption is a functional option.
+type JsonWebKeySetOption func(*JsonWebKeySetOptions)
+
+// WithKeysClientFactory sets a function to create new JSON Web Key clients
+// during the reconciliation logic.
+func WithKeysClientFactory(factory KeysClientFactory) JsonWebKeySetOption {
+	return func(o *JsonWebKeySetOptions) {
+		o.KeysClientFactory = factory
+	}
+}
+
+// WithKeySetReader sets the reader listing the resources claiming the same
+// key set, e.g. an uncached reader when the cache does not hold the
+// resources of all namespaces.
+func WithKeySetReader(reader client.Reader) JsonWebKeySetOption {
+	return func(o *JsonWebKeySetOptions) {
+		o.Reader = reader
+	}
+}
+
+// NewJsonWebKeySetReconciler returns a new JsonWebKeySetReconciler.
+func NewJsonWebKeySetReconciler(c client.Client, keysClient hydra.KeysClient, log logr.Logger, opts ...JsonWebKeySetOption) *JsonWebKeySetReconciler {
+	options := &JsonWebKeySetOptions{
+		KeysClientFactory: hydra.NewKeysClient,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.Reader == nil {
+		options.Reader = c
+	}
+
+	return &JsonWebKeySetReconciler{
+		Client:            c,
+		KeysClient:        keysClient,
+		Log:               log,
+		keysClients:       make(map[clientKey]hydra.KeysClient),
+		keysClientFactory: options.KeysClientFactory,
+		reader:            options.Reader,
+	}
+}
+
+// +kubebuilder:rbac:groups=hydra.ory.sh,resources=jsonwebkeysets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=hydra.ory.sh,resources=jsonwebkeysets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+func (r *JsonWebKeySetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, span := startReconcileSpan(ctx, "JsonWebKeySet", req)
+	defer func() { endReconcileSpan(span, result, err) }()
+
+	ctx = ctrl.LoggerInto(ctx, r.Log.WithValues("jsonwebkeyset", req.NamespacedName))
+
+	var keySet hydrav1alpha1.JsonWebKeySet
+	if err := r.Get(ctx, req.NamespacedName, &keySet); err != nil {
+		// the published ConfigMap and Secret are garbage collected
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if keySet.ObjectMeta.DeletionTimestamp.IsZero() {
+		if !containsString(keySet.ObjectMeta.Finalizers, FinalizerName) {
+			typeMeta := keySet.TypeMeta
+			keySet.ObjectMeta.Finalizers = append(keySet.ObjectMeta.Finalizers, FinalizerName)
+			if err := r.Update(ctx, &keySet); err != nil {
+				return ctrl.Result{}, err
+			}
+			// restore the TypeMeta object as it is removed during Update, but need to be accessed later
+			keySet.TypeMeta = typeMeta
+		}
+	} else {
+		if containsString(keySet.ObjectMeta.Finalizers, FinalizerName) {
+			if keySet.Spec.DeletionPolicy != hydrav1alpha1.OAuth2ClientDeletionPolicyOrphan {
+				// only the keys generated for the resource are deleted, the set
+				// may hold keys of others
+				if created := createdKeyIDsOf(keySet); len(created) > 0 {
+					keysClient, err := r.getKeysClient(keySet)
+					if err != nil {
+						return ctrl.Result{}, err
+					}
+					for _, kid := range created {
+						if err := keysClient.DeleteJSONWebKey(ctx, keySet.Spec.SetName, kid); err != nil {
+							return ctrl.Result{}, err
+						}
+					}
+				}
+			} else {
+				ctrl.LoggerFrom(ctx).Info("json web key set deleted, leaving its keys orphan in ORY Hydra")
+			}
+
+			keySet.ObjectMeta.Finalizers = removeString(keySet.ObjectMeta.Finalizers, FinalizerName)
+			if err := r.Update(ctx, &keySet); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.checkOwnership(ctx, keySet); err != nil {
+		if !errors.Is(err, errKeySetConflict) {
+			return ctrl.Result{}, err
+		}
+		if updateErr := r.updateReconciliationStatusError(ctx, &keySet, hydrav1alpha1.StatusKeySetConflict, err); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{}, nil
+	}
+
+	keysClient, err := r.getKeysClient(keySet)
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "hydra address is invalid",
+			"url", keySet.Spec.HydraAdmin.URL,
+			"port", keySet.Spec.HydraAdmin.Port,
+			"endpoint", keySet.Spec.HydraAdmin.Endpoint,
+		)
+		if updateErr := r.updateReconciliationStatusError(ctx, &keySet, hydrav1alpha1.StatusInvalidHydraAddress, err); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{}, nil
+	}
+
+	keys, err := r.ensureKeys(ctx, keysClient, &keySet)
+	if errors.Is(err, errKeySetConflict) {
+		if updateErr := r.updateReconciliationStatusError(ctx, &keySet, hydrav1alpha1.StatusKeySetConflict, err); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		if updateErr := r.updateReconciliationStatusError(ctx, &keySet, hydrav1alpha1.StatusKeyGenerationFailed, err); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := r.publishKeys(ctx, &keySet, keys); err != nil {
+		if updateErr := r.updateReconciliationStatusError(ctx, &keySet, hydrav1alpha1.StatusPublishKeysFailed, err); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{}, err
+	}
+
+	previousKeyID := ""
+	if k := keys.Key(previousKeyIDOf(keySet)); k != nil {
+		previousKeyID = k.KeyID()
+	}
+	return ctrl.Result{}, r.ensureEmptyStatusError(ctx, &keySet, previousKeyID)
+}
+
+func (r *JsonWebKeySetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&hydrav1alpha1.JsonWebKeySet{}).
+		Owns(&apiv1.ConfigMap{}).
+		Owns(&apiv1.Secret{}).
+		// the resources claiming the same set may take it over once its owner
+		// is deleted
+		Watches(&hydrav1alpha1.JsonWebKeySet{}, handler.EnqueueRequestsFromMapFunc(r.claimantsOf)).
+		Complete(r)
+}
+
+// checkOwnership returns an errKeySetConflict error if the set of the
+// resource is reserved or owned by another resource. A set is owned by the
+// oldest resource naming it in the same ORY Hydra.
+func (r *JsonWebKeySetReconciler) checkOwnership(ctx context.Context, keySet hydrav1alpha1.JsonWebKeySet) error {
+	if strings.HasPrefix(keySet.Spec.SetName, reservedSetPrefix) {
+		return fmt.Errorf("%w: the %s prefix of set %s is reserved for the key sets of ORY Hydra", errKeySetConflict, reservedSetPrefix, keySet.Spec.SetName)
+	}
+
+	var list hydrav1alpha1.JsonWebKeySetList
+	if err := r.reader.List(ctx, &list); err != nil {
+		return err
+	}
+	for _, other := range list.Items {
+		if !sameKeySet(other, keySet) || !claimedBefore(other, keySet) {
+			continue
+		}
+		return fmt.Errorf("%w: set %s is owned by JsonWebKeySet %s/%s", errKeySetConflict, keySet.Spec.SetName, other.Namespace, other.Name)
+	}
+	return nil
+}
+
+// claimantsOf returns the requests of the other resources claiming the set of
+// obj.
+func (r *JsonWebKeySetReconciler) claimantsOf(ctx context.Context, obj client.Object) []reconcile.Request {
+	keySet, ok := obj.(*hydrav1alpha1.JsonWebKeySet)
+	if !ok {
+		return nil
+	}
+
+	var list hydrav1alpha1.JsonWebKeySetList
+	if err := r.List(ctx, &list); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "cannot list json web key sets")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, other := range list.Items {
+		if sameKeySet(other, *keySet) && client.ObjectKeyFromObject(&other) != client.ObjectKeyFromObject(keySet) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&other)})
+		}
+	}
+	return requests
+}
+
+// ensureKeys makes the set in ORY Hydra hold the current key of the spec and
+// the previous key, if any, and returns it.
+func (r *JsonWebKeySetReconciler) ensureKeys(ctx context.Context, keysClient hydra.KeysClient, keySet *hydrav1alpha1.JsonWebKeySet) (*hydra.JSONWebKeySet, error) {
+	spec := keySet.Spec
+	keys, found, err := keysClient.GetJSONWebKeySet(ctx, spec.SetName)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		keys = &hydra.JSONWebKeySet{}
+	}
+
+	created := createdKeyIDsOf(*keySet)
+	current := keys.Key(spec.KeyID)
+	if current != nil && !slices.Contains(created, spec.KeyID) {
+		return nil, fmt.Errorf("%w: key %s of set %s was not generated for this resource", errKeySetConflict, spec.KeyID, spec.SetName)
+	}
+	if current != nil && (current.Algorithm() != spec.Algorithm || current.Use() != keyUse(spec)) {
+		// a key cannot be changed in place, generate it again
+		ctrl.LoggerFrom(ctx).Info("key changed, generating it again", "keyID", spec.KeyID)
+		if err := keysClient.DeleteJSONWebKey(ctx, spec.SetName, spec.KeyID); err != nil {
+			return nil, err
+		}
+		current = nil
+	}
+
+	if current == nil {
+		// claim the key before generating it, so that it is never mistaken
+		// for a key of others
+		if err := r.claimKey(ctx, keySet, spec.KeyID); err != nil {
+			return nil, err
+		}
+		generated, err := keysClient.CreateJSONWebKey(ctx, spec.SetName, &hydra.JSONWebKeyRequest{
+			Algorithm: spec.Algorithm,
+			KeyID:     spec.KeyID,
+			Use:       keyUse(spec),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if generated == nil || generated.Key(spec.KeyID) == nil {
+			return nil, fmt.Errorf("ORY Hydra did not return the generated key %s", spec.KeyID)
+		}
+		current = generated.Key(spec.KeyID)
+	}
+
+	// keep the previous key so that what it signed can still be verified
+	result := &hydra.JSONWebKeySet{Keys: []hydra.JSONWebKey{current}}
+	previousKeyID := previousKeyIDOf(*keySet)
+	for _, k := range keys.Keys {
+		switch k.KeyID() {
+		case spec.KeyID:
+		case previousKeyID:
+			result.Keys = append(result.Keys, k)
+		default:
+			if !slices.Contains(created, k.KeyID()) {
+				// leave the keys of others alone
+				continue
+			}
+			if err := keysClient.DeleteJSONWebKey(ctx, spec.SetName, k.KeyID()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// claimKey records kid among the keys generated for the resource.
+func (r *JsonWebKeySetReconciler) claimKey(ctx context.Context, k *hydrav1alpha1.JsonWebKeySet, kid string) error {
+	_, err := controllerutil.CreateOrPatch(ctx, r.Client, k, func() error {
+		if !slices.Contains(k.Status.CreatedKeyIDs, kid) {
+			k.Status.CreatedKeyIDs = append(k.Status.CreatedKeyIDs, kid)
+		}
+		return nil
+	})
+	return err
+}
+
+// publishKeys publishes the public keys to the ConfigMap of the key set and
+// the current private key to its Secret, if any.
+func (r *JsonWebKeySetReconciler) publishKeys(ctx context.Context, keySet *hydrav1alpha1.JsonWebKeySet, keys *hydra.JSONWebKeySet) error {
+	jwks, err := json.Marshal(keys.Public())
+	if err != nil {
+		return err
+	}
+
+	configMapName := keySet.Spec.ConfigMapName
+	if configMapName == "" {
+		configMapName = keySet.Name
+	}
+	configMap := &apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: configMapName, Namespace: keySet.Namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[JWKSKey] = string(jwks)
+		return controllerutil.SetControllerReference(keySet, configMap, r.Scheme())
+	}); err != nil {
+		return err
+	}
+
+	if keySet.Spec.SecretName == "" {
+		return nil
+	}
+
+	jwk, err := json.Marshal(keys.Key(keySet.Spec.KeyID))
+	if err != nil {
+		return err
+	}
+
+	secret := &apiv1.Secret{ObjectMeta: metav1.ObjectMeta{Name: keySet.Spec.SecretName, Namespace: keySet.Namespace}}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[JWKKey] = jwk
+		secret.Data[KeyIDKey] = []byte(keySet.Spec.KeyID)
+		return controllerutil.SetControllerReference(keySet, secret, r.Scheme())
+	})
+	return err
+}
+
+func (r *JsonWebKeySetReconciler) updateReconciliationStatusError(ctx context.Context, k *hydrav1alpha1.JsonWebKeySet, code hydrav1alpha1.StatusCode, err error) error {
+	ctrl.LoggerFrom(ctx).Error(err, "error processing json web key set", "code", code)
+
+	_, err = controllerutil.CreateOrPatch(ctx, r.Client, k, func() error {
+		k.Status.ObservedGeneration = k.Generation
+		k.Status.ReconciliationError = hydrav1alpha1.ReconciliationError{
+			Code:        code,
+			Description: err.Error(),
+		}
+		k.Status.Conditions = []hydrav1alpha1.OAuth2ClientCondition{
+			{
+				Type:   hydrav1alpha1.OAuth2ClientConditionReady,
+				Status: hydrav1alpha1.ConditionFalse,
+			},
+		}
+
+		return nil
+	})
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "status update failed")
+	}
+
+	return err
+}
+
+func (r *JsonWebKeySetReconciler) ensureEmptyStatusError(ctx context.Context, k *hydrav1alpha1.JsonWebKeySet, previousKeyID string) error {
+	_, err := controllerutil.CreateOrPatch(ctx, r.Client, k, func() error {
+		k.Status.ObservedGeneration = k.Generation
+		k.Status.ReconciliationError = hydrav1alpha1.ReconciliationError{}
+		k.Status.KeyID = k.Spec.KeyID
+		k.Status.PreviousKeyID = previousKeyID
+		// the other keys generated for the resource were deleted
+		k.Status.CreatedKeyIDs = []string{k.Spec.KeyID}
+		if previousKeyID != "" {
+			k.Status.CreatedKeyIDs = append(k.Status.CreatedKeyIDs, previousKeyID)
+		}
+		k.Status.Conditions = []hydrav1alpha1.OAuth2ClientCondition{
+			{
+				Type:   hydrav1alpha1.OAuth2ClientConditionReady,
+				Status: hydrav1alpha1.ConditionTrue,
+			},
+		}
+
+		return nil
+	})
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "status update failed")
+	}
+
+	return err
+}
+
+func (r *JsonWebKeySetReconciler) getKeysClient(keySet hydrav1alpha1.JsonWebKeySet) (hydra.KeysClient, error) {
+	admin := keySet.Spec.HydraAdmin
+	if admin.URL != "" {
+		key := clientKey{
+			url:            admin.URL,
+			port:           admin.Port,
+			endpoint:       admin.Endpoint,
+			forwardedProto: admin.ForwardedProto,
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if c, ok := r.keysClients[key]; ok {
+			return c, nil
+		}
+
+		c, err := r.keysClientFactory(admin, "", false)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create keys client from CRD: %w", err)
+		}
+
+		r.keysClients[key] = c
+		return c, nil
+	}
+
+	if r.KeysClient == nil {
+		return nil, fmt.Errorf("no default client configured")
+	}
+
+	return r.KeysClient, nil
+}
+
+// previousKeyIDOf returns the ID of the key that was current before the
+// current key of the spec.
+func previousKeyIDOf(keySet hydrav1alpha1.JsonWebKeySet) string {
+	if keySet.Status.KeyID != "" && keySet.Status.KeyID != keySet.Spec.KeyID {
+		return keySet.Status.KeyID
+	}
+	return keySet.Status.PreviousKeyID
+}
+
+// createdKeyIDsOf returns the IDs of the keys generated for the resource,
+// including the keys recorded before their IDs were.
+func createdKeyIDsOf(keySet hydrav1alpha1.JsonWebKeySet) []string {
+	created := slices.Clone(keySet.Status.CreatedKeyIDs)
+	for _, kid := range []string{keySet.Status.KeyID, keySet.Status.PreviousKeyID} {
+		if kid != "" && !slices.Contains(created, kid) {
+			created = append(created, kid)
+		}
+	}
+	return created
+}
+
+// sameKeySet returns whether a and b name the same set of the same ORY Hydra.
+func sameKeySet(a, b hydrav1alpha1.JsonWebKeySet) bool {
+	return a.Spec.SetName == b.Spec.SetName &&
+		a.Spec.HydraAdmin.URL == b.Spec.HydraAdmin.URL &&
+		a.Spec.HydraAdmin.Port == b.Spec.HydraAdmin.Port &&
+		a.Spec.HydraAdmin.Endpoint == b.Spec.HydraAdmin.Endpoint
+}
+
+// claimedBefore returns whether a claimed its set before b.
+func claimedBefore(a, b hydrav1alpha1.JsonWebKeySet) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name
+}
+
+func keyUse(spec hydrav1alpha1.JsonWebKeySetSpec) string {
+	if spec.Use == "" {
+		return "sig"
+	}
+	return spec.Use
+}
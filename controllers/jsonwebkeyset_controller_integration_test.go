@@ -0,0 +1,241 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/stretchr/testify/mock"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/controllers"
+	mocks "github.com/ory/hydra-maester/controllers/mocks/hydra"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+var _ = Describe("JsonWebKeySet Controller", func() {
+
+	Context("in a happy-path scenario", func() {
+
+		It("generate, publish, rotate and delete the keys", func() {
+			tstName, tstSetName := "test-jwks", "test.id-token"
+			key := client.ObjectKey{Name: tstName, Namespace: tstNamespace}
+
+			s := runtime.NewScheme()
+			Expect(hydrav1alpha1.AddToScheme(s)).To(Succeed())
+			Expect(apiv1.AddToScheme(s)).To(Succeed())
+
+			mgr, err := manager.New(cfg, manager.Options{
+				Scheme: s,
+				Metrics: server.Options{
+					BindAddress: ":8094",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			c := mgr.GetClient()
+
+			// the mock keeps the keys of the set like ORY Hydra would, starting
+			// with a key the controller did not generate
+			var mu sync.Mutex
+			keys := []hydra.JSONWebKey{{"kty": "RSA", "kid": "external", "n": "modulus-external", "e": "AQAB"}}
+			mkc := &mocks.KeysClient{}
+			mkc.On("GetJSONWebKeySet", Anything, tstSetName).Return(func(context.Context, string) *hydra.JSONWebKeySet {
+				mu.Lock()
+				defer mu.Unlock()
+				return &hydra.JSONWebKeySet{Keys: append([]hydra.JSONWebKey{}, keys...)}
+			}, func(context.Context, string) bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return len(keys) > 0
+			}, nil)
+			mkc.On("CreateJSONWebKey", Anything, tstSetName, AnythingOfType("*hydra.JSONWebKeyRequest")).Return(func(_ context.Context, _ string, k *hydra.JSONWebKeyRequest) *hydra.JSONWebKeySet {
+				mu.Lock()
+				defer mu.Unlock()
+				generated := hydra.JSONWebKey{"kty": "RSA", "kid": k.KeyID, "alg": k.Algorithm, "use": k.Use, "n": "modulus-" + k.KeyID, "e": "AQAB", "d": "private-" + k.KeyID}
+				keys = append(keys, generated)
+				return &hydra.JSONWebKeySet{Keys: []hydra.JSONWebKey{generated}}
+			}, nil)
+			mkc.On("DeleteJSONWebKey", Anything, tstSetName, AnythingOfType("string")).Return(func(_ context.Context, _, kid string) error {
+				mu.Lock()
+				defer mu.Unlock()
+				var kept []hydra.JSONWebKey
+				for _, k := range keys {
+					if k.KeyID() != kid {
+						kept = append(kept, k)
+					}
+				}
+				keys = kept
+				return nil
+			})
+
+			recFn, requests := SetupTestReconcile(getKeySetReconciler(mgr, mkc))
+			Expect(addKeySetController(mgr, recFn)).To(Succeed())
+			go func() {
+				for range requests {
+				}
+			}()
+
+			stopMgr := StartTestManager(mgr)
+
+			instance := &hydrav1alpha1.JsonWebKeySet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      tstName,
+					Namespace: tstNamespace,
+				},
+				Spec: hydrav1alpha1.JsonWebKeySetSpec{
+					SetName:    tstSetName,
+					Algorithm:  "RS256",
+					KeyID:      "key-1",
+					SecretName: "test-jwks-private",
+				},
+			}
+			Expect(c.Create(context.TODO(), instance)).To(Succeed())
+
+			var retrieved hydrav1alpha1.JsonWebKeySet
+			Eventually(func() string {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.KeyID
+			}, timeout).Should(Equal("key-1"))
+			Expect(retrieved.Status.ReconciliationError).To(BeZero())
+			Expect(retrieved.Finalizers).To(ContainElement(controllers.FinalizerName))
+
+			// Verify the published public keys and private key
+			var configMap apiv1.ConfigMap
+			Expect(k8sClient.Get(context.TODO(), key, &configMap)).To(Succeed())
+			Expect(publishedKeyIDs(configMap)).To(Equal([]string{"key-1"}))
+			Expect(configMap.Data[controllers.JWKSKey]).NotTo(ContainSubstring("private"))
+
+			var secret apiv1.Secret
+			Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Name: "test-jwks-private", Namespace: tstNamespace}, &secret)).To(Succeed())
+			Expect(string(secret.Data[controllers.KeyIDKey])).To(Equal("key-1"))
+			Expect(string(secret.Data[controllers.JWKKey])).To(ContainSubstring("private-key-1"))
+
+			// Rotate the key twice, keeping only the previous key
+			for _, kid := range []string{"key-2", "key-3"} {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				retrieved.Spec.KeyID = kid
+				Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+
+				Eventually(func() string {
+					Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+					return retrieved.Status.KeyID
+				}, timeout).Should(Equal(kid))
+			}
+			Expect(retrieved.Status.PreviousKeyID).To(Equal("key-2"))
+			Expect(retrieved.Status.CreatedKeyIDs).To(Equal([]string{"key-3", "key-2"}))
+
+			Eventually(func() []string {
+				Expect(k8sClient.Get(context.TODO(), key, &configMap)).To(Succeed())
+				return publishedKeyIDs(configMap)
+			}, timeout).Should(Equal([]string{"key-3", "key-2"}))
+			Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Name: "test-jwks-private", Namespace: tstNamespace}, &secret)).To(Succeed())
+			Expect(string(secret.Data[controllers.KeyIDKey])).To(Equal("key-3"))
+
+			mu.Lock()
+			Expect(keys).To(HaveLen(3))
+			mu.Unlock()
+
+			// Another resource cannot claim the set
+			claimant := &hydrav1alpha1.JsonWebKeySet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      tstName + "-claimant",
+					Namespace: tstNamespace,
+				},
+				Spec: hydrav1alpha1.JsonWebKeySetSpec{
+					SetName:   tstSetName,
+					Algorithm: "RS256",
+					KeyID:     "key-4",
+				},
+			}
+			Expect(c.Create(context.TODO(), claimant)).To(Succeed())
+			Eventually(func() hydrav1alpha1.StatusCode {
+				Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(claimant), &retrieved)).To(Succeed())
+				return retrieved.Status.ReconciliationError.Code
+			}, timeout).Should(Equal(hydrav1alpha1.StatusKeySetConflict))
+			Expect(retrieved.Status.ReconciliationError.Description).To(ContainSubstring("owned by JsonWebKeySet " + tstNamespace + "/" + tstName))
+
+			keyIDs := func() []string {
+				mu.Lock()
+				defer mu.Unlock()
+				var kids []string
+				for _, k := range keys {
+					kids = append(kids, k.KeyID())
+				}
+				return kids
+			}
+
+			// Delete the resource and its keys only, handing the set over to
+			// the claimant
+			Expect(c.Delete(context.TODO(), instance)).To(Succeed())
+			Eventually(keyIDs, timeout).Should(Equal([]string{"external"}))
+			Eventually(func() error {
+				return c.Get(context.TODO(), key, &retrieved)
+			}, timeout).ShouldNot(Succeed())
+
+			// SetupWithManager reconciles the claimants once the owner is gone,
+			// the test controller only watches the resources themselves
+			Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(claimant), &retrieved)).To(Succeed())
+			retrieved.Annotations = map[string]string{"test": "owner-deleted"}
+			Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+			Eventually(keyIDs, timeout).Should(Equal([]string{"external", "key-4"}))
+			Eventually(func() string {
+				Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(claimant), &retrieved)).To(Succeed())
+				return retrieved.Status.KeyID
+			}, timeout).Should(Equal("key-4"))
+
+			Expect(c.Delete(context.TODO(), claimant)).To(Succeed())
+			Eventually(keyIDs, timeout).Should(Equal([]string{"external"}))
+			mkc.AssertNotCalled(GinkgoT(), "DeleteJSONWebKeySet", Anything, Anything)
+
+			stopMgr.Done()
+		})
+	})
+})
+
+func publishedKeyIDs(configMap apiv1.ConfigMap) []string {
+	var set hydra.JSONWebKeySet
+	Expect(json.Unmarshal([]byte(configMap.Data[controllers.JWKSKey]), &set)).To(Succeed())
+
+	var kids []string
+	for _, k := range set.Keys {
+		kids = append(kids, k.KeyID())
+	}
+	return kids
+}
+
+// addKeySetController adds a new JsonWebKeySet Controller to mgr with r as the reconcile.Reconciler
+func addKeySetController(mgr manager.Manager, r reconcile.Reconciler) error {
+	name := fmt.Sprintf("controller-%s", uuid.NewString())
+	c, err := controller.New(name, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	return c.Watch(source.Kind(mgr.GetCache(), &hydrav1alpha1.JsonWebKeySet{}, &handler.TypedEnqueueRequestForObject[*hydrav1alpha1.JsonWebKeySet]{}))
+}
+
+func getKeySetReconciler(mgr ctrl.Manager, mock hydra.KeysClient) reconcile.Reconciler {
+	return controllers.NewJsonWebKeySetReconciler(
+		mgr.GetClient(),
+		mock,
+		ctrl.Log.WithName("controllers").WithName("JsonWebKeySet"),
+	)
+}
@@ -0,0 +1,75 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+)
+
+const (
+	// ReconcileAnnotation controls the reconciliation of a client. Set to
+	// ReconcilePaused, the client is neither changed in ORY Hydra nor are its
+	// credentials, until the annotation is removed or the client is deleted.
+	ReconcileAnnotation = "hydra.ory.sh/reconcile"
+	// ReconcilePaused is the value of ReconcileAnnotation pausing the
+	// reconciliation of a client.
+	ReconcilePaused = "paused"
+	// ResyncAtAnnotation forces a full sync of a client with ORY Hydra
+	// whenever its value changes, e.g. to the current time.
+	ResyncAtAnnotation = "hydra.ory.sh/resync-at"
+	// ReplacementAcknowledgedAnnotation acknowledges the replacement of the
+	// clients registered in ORY Hydra for a client by a new one, once its
+	// consumers use the new credentials. Set to the ID of the new client, the
+	// previous clients are deleted without waiting for the grace period.
+	ReplacementAcknowledgedAnnotation = "hydra.ory.sh/replacement-acknowledged"
+)
+
+// isPaused returns true if the reconciliation of the client is paused.
+func isPaused(c *hydrav1alpha1.OAuth2Client) bool {
+	return c.Annotations[ReconcileAnnotation] == ReconcilePaused
+}
+
+// resyncRequested returns true if the client has to be fully synced with ORY
+// Hydra although its spec did not change, either because the value of its
+// resync-at annotation changed or because its reconciliation was paused.
+func resyncRequested(c *hydrav1alpha1.OAuth2Client) bool {
+	if resyncAt := c.Annotations[ResyncAtAnnotation]; resyncAt != "" && resyncAt != c.Status.ResyncAt {
+		return true
+	}
+	for _, condition := range c.Status.Conditions {
+		if condition.Type == hydrav1alpha1.OAuth2ClientConditionPaused {
+			return true
+		}
+	}
+	return false
+}
+
+// reportPaused reports in the status of the client that its reconciliation is
+// paused, keeping its other conditions.
+func (r *OAuth2ClientReconciler) reportPaused(ctx context.Context, c *hydrav1alpha1.OAuth2Client) error {
+	_, err := controllerutil.CreateOrPatch(ctx, r.Client, c, func() error {
+		c.Status.Controller = r.controllerName()
+		for i, condition := range c.Status.Conditions {
+			if condition.Type == hydrav1alpha1.OAuth2ClientConditionPaused {
+				c.Status.Conditions[i].Status = hydrav1alpha1.ConditionTrue
+				return nil
+			}
+		}
+		c.Status.Conditions = append(c.Status.Conditions, hydrav1alpha1.OAuth2ClientCondition{
+			Type:   hydrav1alpha1.OAuth2ClientConditionPaused,
+			Status: hydrav1alpha1.ConditionTrue,
+		})
+		return nil
+	})
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "status update failed")
+	}
+
+	return err
+}
@@ -0,0 +1,81 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"time"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+const (
+	// DefaultRetryBaseDelay is the delay before the first retry of a client
+	// whose reconciliation failed with a transient error.
+	DefaultRetryBaseDelay = 5 * time.Second
+	// DefaultRetryMaxDelay is the maximum delay between two retries of a
+	// client whose reconciliation keeps failing with transient errors.
+	DefaultRetryMaxDelay = 5 * time.Minute
+)
+
+// retryError is returned by updateReconciliationStatusError once a transient
+// error has been reported in the status of a client, to requeue the client
+// after the given delay.
+type retryError struct {
+	after time.Duration
+	err   error
+}
+
+func (e *retryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryError) Unwrap() error {
+	return e.err
+}
+
+// isTransient returns true if err is likely to go away when the
+// reconciliation is retried later, such as ORY Hydra being unavailable or a
+// conflicting update of the Kubernetes API. All other errors, such as an
+// invalid spec or a client ID owned by another resource, are terminal and
+// only retried once the client changes.
+func isTransient(err error) bool {
+	return hydra.IsTransient(err) ||
+		apierrs.IsConflict(err) ||
+		apierrs.IsServerTimeout(err) ||
+		apierrs.IsTimeout(err) ||
+		apierrs.IsTooManyRequests(err) ||
+		apierrs.IsServiceUnavailable(err) ||
+		apierrs.IsInternalError(err)
+}
+
+// retryDelay returns the delay before the given retry, doubling the base
+// delay with each retry up to the maximum delay.
+func (r *OAuth2ClientReconciler) retryDelay(retry int32) time.Duration {
+	delay := r.retryBaseDelay
+	for i := int32(1); i < retry && delay < r.retryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, r.retryMaxDelay)
+}
+
+// retryPending returns true if the last reconciliation of the client failed
+// with a transient error.
+func retryPending(c *hydrav1alpha1.OAuth2Client) bool {
+	return c.Status.NextRetryTime != nil
+}
+
+// retryBackoff returns the time left until the client may be retried, if
+// its last reconciliation failed with a transient error and neither its spec
+// changed nor a resync was requested since. The status updates and watches
+// would otherwise reconcile the client again right away.
+func retryBackoff(c *hydrav1alpha1.OAuth2Client, now time.Time) (time.Duration, bool) {
+	if !retryPending(c) || c.Generation != c.Status.ObservedGeneration || resyncRequested(c) {
+		return 0, false
+	}
+	left := c.Status.NextRetryTime.Sub(now)
+	return left, left > 0
+}
@@ -5,29 +5,43 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	apiv1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/credentials"
 	"github.com/ory/hydra-maester/hydra"
+	"github.com/ory/hydra-maester/policy"
 )
 
 const (
 	DefaultClientID  = "CLIENT_ID"
 	DefaultSecretKey = "CLIENT_SECRET"
 	FinalizerName    = "finalizer.ory.hydra.sh"
-
-	DefaultNamespace = "default"
 )
 
 var (
@@ -54,38 +68,67 @@ type OAuth2ClientFactory func(
 // OAuth2ClientReconciler reconciles a OAuth2Client object.
 type OAuth2ClientReconciler struct {
 	client.Client
-	HydraClient         hydra.Client
-	Log                 logr.Logger
-	ControllerNamespace string
-
-	oauth2Clients       map[clientKey]hydra.Client
-	oauth2ClientFactory OAuth2ClientFactory
-	mu                  sync.Mutex
+	HydraClient hydra.Client
+	Log         logr.Logger
+
+	oauth2Clients          map[clientKey]hydra.Client
+	oauth2ClientFactory    OAuth2ClientFactory
+	credentialStores       map[hydrav1alpha1.CredentialStoreType]credentials.Store
+	credentialsGenerator   *credentials.Generator
+	controllerClass        string
+	controllerInstance     string
+	lifespanBounds         hydra.LifespanBounds
+	recorder               events.EventRecorder
+	hydraURL               string
+	retryBaseDelay         time.Duration
+	retryMaxDelay          time.Duration
+	replacementGracePeriod time.Duration
+	scopeOwnership         bool
+	namespace              string
+	clientsReader          client.Reader
+	mu                     sync.Mutex
 }
 
 // Options represent options to pass to the oauth2 client reconciler.
 type Options struct {
-	Namespace           string
-	OAuth2ClientFactory OAuth2ClientFactory
+	OAuth2ClientFactory    OAuth2ClientFactory
+	CredentialStores       map[hydrav1alpha1.CredentialStoreType]credentials.Store
+	CredentialsGenerator   *credentials.Generator
+	ControllerClass        string
+	ControllerInstance     string
+	LifespanBounds         hydra.LifespanBounds
+	EventRecorder          events.EventRecorder
+	HydraURL               string
+	RetryBaseDelay         time.Duration
+	RetryMaxDelay          time.Duration
+	ReplacementGracePeriod time.Duration
+	ClientIDKey            string
+	ClientSecretKey        string
+	ScopeOwnership         bool
+	Namespace              string
+	ClientsReader          client.Reader
 }
 
 // Option is a functional option.
 type Option func(*Options)
 
-func init() {
-	if os.Getenv("CLIENT_ID_KEY") != "" {
-		ClientIDKey = os.Getenv("CLIENT_ID_KEY")
-	}
-	if os.Getenv("CLIENT_SECRET_KEY") != "" {
-		ClientSecretKey = os.Getenv("CLIENT_SECRET_KEY")
+// WithNamespace makes the reconciler ignore the clients of other namespaces.
+//
+// Deprecated: the clients of other namespaces are still cached and listed.
+// Restrict the namespaces of the manager cache instead, e.g. with
+// helpers.CacheNamespaces.
+func WithNamespace(ns string) Option {
+	return func(o *Options) {
+		o.Namespace = ns
 	}
 }
 
-// WithNamespace sets the kubernetes namespace for the controller.
-// The default is "default".
-func WithNamespace(ns string) Option {
+// WithClientsReader sets the reader counting the clients of a namespace for
+// the policies limiting them, e.g. an uncached reader when the cache is
+// filtered by a label selector. The default is the client of the reconciler.
+func WithClientsReader(reader client.Reader) Option {
 	return func(o *Options) {
-		o.Namespace = ns
+		o.ClientsReader = reader
 	}
 }
 
@@ -96,37 +139,193 @@ func WithClientFactory(factory OAuth2ClientFactory) Option {
 	}
 }
 
+// WithCredentialStore registers the store to use for oauth2 clients that
+// select the given credential store type. The "secret" type is always
+// registered and backed by K8s secrets unless overridden.
+func WithCredentialStore(t hydrav1alpha1.CredentialStoreType, store credentials.Store) Option {
+	return func(o *Options) {
+		o.CredentialStores[t] = store
+	}
+}
+
+// WithCredentialsGenerator sets the generator used to create the credentials
+// of new oauth2 clients.
+func WithCredentialsGenerator(g *credentials.Generator) Option {
+	return func(o *Options) {
+		o.CredentialsGenerator = g
+	}
+}
+
+// WithControllerClass makes the reconciler manage only the oauth2 clients
+// whose spec.controllerClass matches the given class.
+func WithControllerClass(class string) Option {
+	return func(o *Options) {
+		o.ControllerClass = class
+	}
+}
+
+// WithControllerInstance sets the name of this controller instance, which is
+// recorded in the status of the reconciled oauth2 clients. It defaults to the
+// hostname, i.e. the pod name.
+func WithControllerInstance(name string) Option {
+	return func(o *Options) {
+		o.ControllerInstance = name
+	}
+}
+
+// WithLifespanBounds sets the minimum and maximum token lifespans oauth2
+// clients may set.
+func WithLifespanBounds(bounds hydra.LifespanBounds) Option {
+	return func(o *Options) {
+		o.LifespanBounds = bounds
+	}
+}
+
+// WithEventRecorder sets the recorder of the events reported on oauth2
+// clients, such as the revocation of their tokens.
+func WithEventRecorder(recorder events.EventRecorder) Option {
+	return func(o *Options) {
+		o.EventRecorder = recorder
+	}
+}
+
+// WithHydraURL sets the address of the default ORY Hydra admin server, which
+// is reported in the status of the oauth2 clients registered in it.
+func WithHydraURL(url string) Option {
+	return func(o *Options) {
+		o.HydraURL = url
+	}
+}
+
+// WithRetryBackoff sets the delay before retrying an oauth2 client whose
+// reconciliation failed with a transient error, which doubles with each
+// consecutive failure up to max.
+func WithRetryBackoff(base, max time.Duration) Option {
+	return func(o *Options) {
+		o.RetryBaseDelay = base
+		o.RetryMaxDelay = max
+	}
+}
+
+// WithReplacementGracePeriod sets how long the oauth2 clients replaced by a
+// new one are kept in ORY Hydra after the credentials of the new one have been
+// persisted, unless the replacement is acknowledged earlier.
+func WithReplacementGracePeriod(d time.Duration) Option {
+	return func(o *Options) {
+		o.ReplacementGracePeriod = d
+	}
+}
+
+// WithSecretKeys sets the keys of the client ID and secret in the Secrets
+// of the "secret" credential store, unless the store is overridden.
+func WithSecretKeys(clientIDKey, clientSecretKey string) Option {
+	return func(o *Options) {
+		o.ClientIDKey = clientIDKey
+		o.ClientSecretKey = clientSecretKey
+	}
+}
+
+// WithScopeOwnership makes the reconciler reject the oauth2 clients
+// requesting scopes that are not declared by an OAuth2Scope, or that the
+// namespace owning them did not approve for the client.
+func WithScopeOwnership() Option {
+	return func(o *Options) {
+		o.ScopeOwnership = true
+	}
+}
+
 // New returns a new Oauth2ClientReconciler.
 func New(c client.Client, hydraClient hydra.Client, log logr.Logger, opts ...Option) *OAuth2ClientReconciler {
 	options := &Options{
-		Namespace:           DefaultNamespace,
-		OAuth2ClientFactory: hydra.New,
+		OAuth2ClientFactory:    hydra.New,
+		CredentialStores:       map[hydrav1alpha1.CredentialStoreType]credentials.Store{},
+		CredentialsGenerator:   credentials.NewGenerator(),
+		RetryBaseDelay:         DefaultRetryBaseDelay,
+		RetryMaxDelay:          DefaultRetryMaxDelay,
+		ReplacementGracePeriod: DefaultReplacementGracePeriod,
+		ClientIDKey:            ClientIDKey,
+		ClientSecretKey:        ClientSecretKey,
 	}
+	options.ControllerInstance, _ = os.Hostname()
 	for _, opt := range opts {
 		opt(options)
 	}
+	if _, ok := options.CredentialStores[hydrav1alpha1.CredentialStoreSecret]; !ok {
+		options.CredentialStores[hydrav1alpha1.CredentialStoreSecret] = credentials.NewSecretStore(c, options.ClientIDKey, options.ClientSecretKey)
+	}
+
+	if options.ClientsReader == nil {
+		options.ClientsReader = c
+	}
 
 	return &OAuth2ClientReconciler{
-		Client:              c,
-		HydraClient:         hydraClient,
-		Log:                 log,
-		ControllerNamespace: options.Namespace,
-		oauth2Clients:       make(map[clientKey]hydra.Client, 0),
-		oauth2ClientFactory: options.OAuth2ClientFactory,
+		Client:                 c,
+		HydraClient:            hydraClient,
+		Log:                    log,
+		oauth2Clients:          make(map[clientKey]hydra.Client, 0),
+		oauth2ClientFactory:    options.OAuth2ClientFactory,
+		credentialStores:       options.CredentialStores,
+		credentialsGenerator:   options.CredentialsGenerator,
+		controllerClass:        options.ControllerClass,
+		controllerInstance:     options.ControllerInstance,
+		lifespanBounds:         options.LifespanBounds,
+		recorder:               options.EventRecorder,
+		hydraURL:               options.HydraURL,
+		retryBaseDelay:         options.RetryBaseDelay,
+		retryMaxDelay:          options.RetryMaxDelay,
+		replacementGracePeriod: options.ReplacementGracePeriod,
+		scopeOwnership:         options.ScopeOwnership,
+		namespace:              options.Namespace,
+		clientsReader:          options.ClientsReader,
 	}
 }
 
 // +kubebuilder:rbac:groups=hydra.ory.sh,resources=oauth2clients,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=hydra.ory.sh,resources=oauth2clients/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=hydra.ory.sh,resources=oauth2clientpolicies;clusteroauth2clientpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=hydra.ory.sh,resources=oauth2scopes,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 func (r *OAuth2ClientReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	_ = r.Log.WithValues("oauth2client", req.NamespacedName)
+	ctx, span := startReconcileSpan(ctx, "OAuth2Client", req)
+	result, err := r.reconcile(ctx, req)
+	endReconcileSpan(span, result, err)
+
+	// transient errors are reported in the status of the client and retried
+	// with an exponential backoff
+	var retryErr *retryError
+	if errors.As(err, &retryErr) {
+		return ctrl.Result{RequeueAfter: retryErr.after}, nil
+	}
+	return result, err
+}
+
+// loggerInto returns ctx with the logger of the reconciliation of the client
+// with the given key, which carries its namespace/name and the ID under which
+// it is registered in ORY Hydra, if known. The lines logged for the client by
+// the controller and the hydra package can then be correlated.
+func (r *OAuth2ClientReconciler) loggerInto(ctx context.Context, key types.NamespacedName, clientID string) context.Context {
+	log := r.Log.WithValues("oauth2client", key)
+	if clientID != "" {
+		log = log.WithValues("clientID", clientID)
+	}
+	return ctrl.LoggerInto(ctx, log)
+}
+
+func (r *OAuth2ClientReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx = r.loggerInto(ctx, req.NamespacedName, "")
+
+	if r.namespace != "" && req.Namespace != r.namespace {
+		return ctrl.Result{}, nil
+	}
 
 	var oauth2client hydrav1alpha1.OAuth2Client
 	if err := r.Get(ctx, req.NamespacedName, &oauth2client); err != nil {
 		if apierrs.IsNotFound(err) {
-			if registerErr := r.unregisterOAuth2Clients(ctx, &oauth2client); registerErr != nil {
+			if registerErr := r.unregisterOAuth2Clients(ctx, &oauth2client, hydrav1alpha1.RevocationOnDelete); registerErr != nil {
 				return ctrl.Result{}, registerErr
 			}
 			return ctrl.Result{}, nil
@@ -134,17 +333,29 @@ func (r *OAuth2ClientReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
-	// Check request namespace
-	if r.ControllerNamespace != "" {
-		r.Log.Info(fmt.Sprintf("ControllerNamespace is set to: %s, working only on items in this namespace. Other namespaces are ignored.", r.ControllerNamespace))
-		if req.NamespacedName.Namespace != r.ControllerNamespace {
-			r.Log.Info(fmt.Sprintf("Requested resource %s is not in namespace: %s and will be ignored", req.String(), r.ControllerNamespace))
-			return ctrl.Result{}, nil
-		}
+	// the event filter already drops clients of other controller classes, but
+	// the class may have changed since the request was queued
+	if !r.ownsOAuth2Client(&oauth2client) {
+		return ctrl.Result{}, nil
+	}
+
+	clientID := oauth2client.Status.ClientID
+	if clientID == "" {
+		clientID = oauth2client.Spec.ClientID
 	}
+	ctx = r.loggerInto(ctx, req.NamespacedName, clientID)
+	log := ctrl.LoggerFrom(ctx)
 
 	// examine DeletionTimestamp to determine if object is under deletion
 	if oauth2client.ObjectMeta.DeletionTimestamp.IsZero() {
+		// neither ORY Hydra nor the credentials are touched while paused.
+		// The deletion of the resource is not paused, so that it does not
+		// hang until the annotation is removed.
+		if isPaused(&oauth2client) {
+			log.Info("reconciliation is paused")
+			return ctrl.Result{}, r.reportPaused(ctx, &oauth2client)
+		}
+
 		// The object is not being deleted, so if it does not have our finalizer,
 		// then lets add the finalizer and update the object. This is equivalent
 		// registering our finalizer.
@@ -161,12 +372,20 @@ func (r *OAuth2ClientReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		// The object is being deleted
 		if containsString(oauth2client.ObjectMeta.Finalizers, FinalizerName) {
 			// our finalizer is present, so lets handle any external dependency
-			if err := r.unregisterOAuth2Clients(ctx, &oauth2client); err != nil {
+			if err := r.unregisterOAuth2Clients(ctx, &oauth2client, hydrav1alpha1.RevocationOnDelete); err != nil {
 				// if fail to delete the external dependency here, return with error
 				// so that it can be retried
 				return ctrl.Result{}, err
 			}
 
+			if oauth2client.Spec.DeletionPolicy != hydrav1alpha1.OAuth2ClientDeletionPolicyOrphan {
+				if store, err := r.getCredentialStore(oauth2client); err == nil {
+					if err := store.Delete(ctx, &oauth2client); err != nil {
+						return ctrl.Result{}, err
+					}
+				}
+			}
+
 			// remove our finalizer from the list and update it.
 			oauth2client.ObjectMeta.Finalizers = removeString(oauth2client.ObjectMeta.Finalizers, FinalizerName)
 			if err := r.Update(ctx, &oauth2client); err != nil {
@@ -178,89 +397,346 @@ func (r *OAuth2ClientReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	}
 
-	var secret apiv1.Secret
-	if err := r.Get(ctx, types.NamespacedName{Name: oauth2client.Spec.SecretName, Namespace: req.Namespace}, &secret); err != nil {
-		if apierrs.IsNotFound(err) {
-			if registerErr := r.registerOAuth2Client(ctx, &oauth2client, nil); registerErr != nil {
-				return ctrl.Result{}, registerErr
+	if left, ok := retryBackoff(&oauth2client, time.Now()); ok {
+		return ctrl.Result{RequeueAfter: left}, nil
+	}
+
+	if left, err := r.continueReplacement(ctx, &oauth2client); err != nil || left > 0 {
+		return ctrl.Result{RequeueAfter: left}, err
+	}
+
+	if err := r.lifespanBounds.Validate(hydra.LifespansFromTokenLifespans(oauth2client.Spec.TokenLifespans)); err != nil {
+		if updateErr := r.updateReconciliationStatusError(ctx, &oauth2client, hydrav1alpha1.StatusInvalidTokenLifespans, err); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{}, nil
+	}
+
+	violations, err := policy.Evaluate(ctx, r.Client, r.clientsReader, &oauth2client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(violations) > 0 {
+		if updateErr := r.updateReconciliationStatusError(ctx, &oauth2client, hydrav1alpha1.StatusPolicyViolation, violations.ToAggregate()); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if r.scopeOwnership {
+		denied, err := policy.EvaluateScopes(ctx, r.Client, &oauth2client)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if len(denied) > 0 {
+			if updateErr := r.updateReconciliationStatusError(ctx, &oauth2client, hydrav1alpha1.StatusScopeDenied, denied.ToAggregate()); updateErr != nil {
+				return ctrl.Result{}, updateErr
 			}
 			return ctrl.Result{}, nil
 		}
-		return ctrl.Result{}, err
 	}
 
-	credentials, err := parseSecret(secret, oauth2client.Spec.TokenEndpointAuthMethod)
+	store, err := r.getCredentialStore(oauth2client)
+	if err != nil {
+		log.Error(err, "credential store is invalid", "credentialStore", oauth2client.Spec.CredentialStore)
+		if updateErr := r.updateReconciliationStatusError(ctx, &oauth2client, hydrav1alpha1.StatusInvalidCredentialStore, err); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{}, nil
+	}
+
+	creds, found, err := store.Get(ctx, &oauth2client)
 	if err != nil {
-		r.Log.Error(err, fmt.Sprintf("secret %s/%s is invalid", secret.Name, secret.Namespace))
+		if !credentials.IsInvalid(err) {
+			return ctrl.Result{}, err
+		}
+		log.Error(err, "secret is invalid", "secret", oauth2client.Spec.SecretName)
 		if updateErr := r.updateReconciliationStatusError(ctx, &oauth2client, hydrav1alpha1.StatusInvalidSecret, err); updateErr != nil {
 			return ctrl.Result{}, updateErr
 		}
 		return ctrl.Result{}, nil
 	}
 
-	hydraClient, err := r.getHydraClientForClient(oauth2client)
+	if !found {
+		if registerErr := r.registerOAuth2Client(ctx, &oauth2client, nil, false); registerErr != nil {
+			return ctrl.Result{}, registerErr
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// the client is registered under the ID of its credentials
+	if string(creds.ID) != clientID {
+		ctx = r.loggerInto(ctx, req.NamespacedName, string(creds.ID))
+		log = ctrl.LoggerFrom(ctx)
+	}
+
+	if oauth2client.Spec.ClientID != "" {
+		clientID, _, err := r.credentialsGenerator.ClientID(&oauth2client)
+		if err != nil {
+			if updateErr := r.updateReconciliationStatusError(ctx, &oauth2client, hydrav1alpha1.StatusRegistrationFailed, err); updateErr != nil {
+				return ctrl.Result{}, updateErr
+			}
+			return ctrl.Result{}, nil
+		}
+
+		if clientID != string(creds.ID) {
+			// spec.clientId has been changed, register the client again under
+			// the new ID and keep its secret
+			if registerErr := r.changeClientID(ctx, &oauth2client, clientID, creds); registerErr != nil {
+				return ctrl.Result{}, registerErr
+			}
+			return ctrl.Result{}, nil
+		}
+	}
+
+	hydraClient, err := r.getHydraClientForClient(ctx, oauth2client)
 	if err != nil {
-		r.Log.Error(err, fmt.Sprintf(
-			"hydra address %s:%d%s is invalid",
-			oauth2client.Spec.HydraAdmin.URL,
-			oauth2client.Spec.HydraAdmin.Port,
-			oauth2client.Spec.HydraAdmin.Endpoint,
-		))
+		log.Error(err, "hydra address is invalid",
+			"url", oauth2client.Spec.HydraAdmin.URL,
+			"port", oauth2client.Spec.HydraAdmin.Port,
+			"endpoint", oauth2client.Spec.HydraAdmin.Endpoint,
+		)
 		if updateErr := r.updateReconciliationStatusError(ctx, &oauth2client, hydrav1alpha1.StatusInvalidHydraAddress, err); updateErr != nil {
 			return ctrl.Result{}, updateErr
 		}
 		return ctrl.Result{}, nil
 	}
 
-	fetched, found, err := hydraClient.GetOAuth2Client(string(credentials.ID))
+	fetched, found, err := hydraClient.GetOAuth2Client(ctx, string(creds.ID))
 	if err != nil {
+		if updateErr := r.updateReconciliationStatusError(ctx, &oauth2client, hydrav1alpha1.StatusLookupFailed, err); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
 		return ctrl.Result{}, err
 	}
 
 	if found {
 		//conclude reconciliation if the client exists and has not been updated
-		if oauth2client.Generation == oauth2client.Status.ObservedGeneration {
-			return ctrl.Result{}, nil
+		jwksChanged := r.jwksChanged(ctx, &oauth2client)
+		// the clients violating a policy or denied a scope are updated once
+		// allowed
+		code := oauth2client.Status.ReconciliationError.Code
+		resync := resyncRequested(&oauth2client) || retryPending(&oauth2client) ||
+			code == hydrav1alpha1.StatusPolicyViolation || code == hydrav1alpha1.StatusScopeDenied
+		secretChanged := r.secretChanged(ctx, &oauth2client)
+		if oauth2client.Generation == oauth2client.Status.ObservedGeneration && !jwksChanged && !secretChanged && !resync {
+			return keyPairResult(&oauth2client), nil
 		}
 
 		if fetched.Owner != fmt.Sprintf("%s/%s", oauth2client.Name, oauth2client.Namespace) {
-			conflictErr := fmt.Errorf("ID provided in secret %s/%s is assigned to another resource", secret.Name, secret.Namespace)
+			conflictErr := fmt.Errorf("ID provided in secret %s/%s is assigned to another resource", oauth2client.Namespace, oauth2client.Spec.SecretName)
 			if updateErr := r.updateReconciliationStatusError(ctx, &oauth2client, hydrav1alpha1.StatusInvalidSecret, conflictErr); updateErr != nil {
 				return ctrl.Result{}, updateErr
 			}
 			return ctrl.Result{}, nil
 		}
 
-		if updateErr := r.updateRegisteredOAuth2Client(ctx, &oauth2client, credentials); updateErr != nil {
+		if oauth2client.Status.SpecHash != "" && oauth2client.Status.SpecHash == specHash(oauth2client.Spec) && !jwksChanged && !secretChanged && !resync {
+			// only the token lifespans changed, which does not require a
+			// full update of the client
+			if updateErr := r.updateOAuth2ClientLifespans(ctx, &oauth2client, creds); updateErr != nil {
+				return ctrl.Result{}, updateErr
+			}
+			return ctrl.Result{}, nil
+		}
+
+		if updateErr := r.updateRegisteredOAuth2Client(ctx, &oauth2client, creds); updateErr != nil {
 			return ctrl.Result{}, updateErr
 		}
-		return ctrl.Result{}, nil
+		return keyPairResult(&oauth2client), nil
 	}
 
-	if registerErr := r.registerOAuth2Client(ctx, &oauth2client, credentials); registerErr != nil {
+	if registerErr := r.registerOAuth2Client(ctx, &oauth2client, creds, false); registerErr != nil {
 		return ctrl.Result{}, registerErr
 	}
 
-	return ctrl.Result{}, nil
+	return keyPairResult(&oauth2client), nil
 }
 
 func (r *OAuth2ClientReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&hydrav1alpha1.OAuth2Client{}).
-		Complete(r)
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&hydrav1alpha1.OAuth2Client{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			c, ok := o.(*hydrav1alpha1.OAuth2Client)
+			return ok && r.ownsOAuth2Client(c)
+		}))).
+		Watches(&apiv1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.oauth2ClientsForJwks)).
+		Watches(&apiv1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.oauth2ClientsForSecret)).
+		Watches(&hydrav1alpha1.OAuth2ClientPolicy{}, handler.EnqueueRequestsFromMapFunc(r.oauth2ClientsForPolicy)).
+		Watches(&hydrav1alpha1.ClusterOAuth2ClientPolicy{}, handler.EnqueueRequestsFromMapFunc(r.oauth2ClientsForPolicy)).
+		Watches(&apiv1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.oauth2ClientsForPolicy), builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		Watches(&hydrav1alpha1.OAuth2Client{}, handler.EnqueueRequestsFromMapFunc(r.oauth2ClientsViolatingPolicy), builder.WithPredicates(predicate.Funcs{
+			CreateFunc:  func(event.CreateEvent) bool { return false },
+			UpdateFunc:  func(event.UpdateEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		}))
+	if r.scopeOwnership {
+		b = b.Watches(&hydrav1alpha1.OAuth2Scope{}, handler.EnqueueRequestsFromMapFunc(r.oauth2ClientsForScope))
+	}
+	return b.Complete(r)
 }
 
-func (r *OAuth2ClientReconciler) registerOAuth2Client(ctx context.Context, c *hydrav1alpha1.OAuth2Client, credentials *hydra.Oauth2ClientCredentials) error {
-	if err := r.unregisterOAuth2Clients(ctx, c); err != nil {
-		return err
+// oauth2ClientsForScope maps an OAuth2Scope to the clients requesting its
+// scope.
+func (r *OAuth2ClientReconciler) oauth2ClientsForScope(ctx context.Context, o client.Object) []reconcile.Request {
+	scope := o.(*hydrav1alpha1.OAuth2Scope).Spec.Scope
+
+	var oauth2clients hydrav1alpha1.OAuth2ClientList
+	if err := r.List(ctx, &oauth2clients); err != nil {
+		r.Log.Error(err, "unable to list clients", "object", client.ObjectKeyFromObject(o))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, c := range oauth2clients.Items {
+		if !r.ownsOAuth2Client(&c) {
+			continue
+		}
+		if slices.Contains(c.Spec.ScopeArray, scope) || slices.Contains(strings.Fields(c.Spec.Scope), scope) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: c.Name, Namespace: c.Namespace}})
+		}
+	}
+	return requests
+}
+
+// oauth2ClientsForPolicy maps a policy, or a namespace whose labels may be
+// selected by a policy, to the clients it may apply to.
+func (r *OAuth2ClientReconciler) oauth2ClientsForPolicy(ctx context.Context, o client.Object) []reconcile.Request {
+	var opts []client.ListOption
+	switch o.(type) {
+	case *hydrav1alpha1.OAuth2ClientPolicy:
+		opts = append(opts, client.InNamespace(o.GetNamespace()))
+	case *apiv1.Namespace:
+		opts = append(opts, client.InNamespace(o.GetName()))
+	}
+
+	var oauth2clients hydrav1alpha1.OAuth2ClientList
+	if err := r.List(ctx, &oauth2clients, opts...); err != nil {
+		r.Log.Error(err, "unable to list clients", "object", client.ObjectKeyFromObject(o))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, c := range oauth2clients.Items {
+		if r.ownsOAuth2Client(&c) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: c.Name, Namespace: c.Namespace}})
+		}
+	}
+	return requests
+}
+
+// oauth2ClientsViolatingPolicy maps a deleted client to the clients of its
+// namespace violating a policy, which may have exceeded the maximum number
+// of clients in the namespace.
+func (r *OAuth2ClientReconciler) oauth2ClientsViolatingPolicy(ctx context.Context, o client.Object) []reconcile.Request {
+	var oauth2clients hydrav1alpha1.OAuth2ClientList
+	if err := r.List(ctx, &oauth2clients, client.InNamespace(o.GetNamespace())); err != nil {
+		r.Log.Error(err, "unable to list clients", "object", client.ObjectKeyFromObject(o))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, c := range oauth2clients.Items {
+		if c.Status.ReconciliationError.Code == hydrav1alpha1.StatusPolicyViolation && r.ownsOAuth2Client(&c) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: c.Name, Namespace: c.Namespace}})
+		}
+	}
+	return requests
+}
+
+// oauth2ClientsForSecret maps a Secret to the clients whose credentials it
+// holds, so that a rotated client secret is pushed to ORY Hydra, and to the
+// clients taking their JSON Web Key Set from it.
+func (r *OAuth2ClientReconciler) oauth2ClientsForSecret(ctx context.Context, o client.Object) []reconcile.Request {
+	var oauth2clients hydrav1alpha1.OAuth2ClientList
+	if err := r.List(ctx, &oauth2clients, client.InNamespace(o.GetNamespace())); err != nil {
+		r.Log.Error(err, "unable to list clients", "object", client.ObjectKeyFromObject(o))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, c := range oauth2clients.Items {
+		if c.Spec.SecretName != o.GetName() || !r.ownsOAuth2Client(&c) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: c.Name, Namespace: c.Namespace}})
+	}
+	for _, req := range r.oauth2ClientsForJwks(ctx, o) {
+		if !slices.Contains(requests, req) {
+			requests = append(requests, req)
+		}
+	}
+	return requests
+}
+
+// oauth2ClientsForJwks maps a ConfigMap or a Secret to the clients taking
+// their JSON Web Key Set from it.
+func (r *OAuth2ClientReconciler) oauth2ClientsForJwks(ctx context.Context, o client.Object) []reconcile.Request {
+	var oauth2clients hydrav1alpha1.OAuth2ClientList
+	if err := r.List(ctx, &oauth2clients, client.InNamespace(o.GetNamespace())); err != nil {
+		r.Log.Error(err, "unable to list clients", "object", client.ObjectKeyFromObject(o))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, c := range oauth2clients.Items {
+		from := c.Spec.JwksFrom
+		if from == nil || !r.ownsOAuth2Client(&c) {
+			continue
+		}
+
+		switch o.(type) {
+		case *apiv1.ConfigMap:
+			if from.ConfigMapKeyRef == nil || from.ConfigMapKeyRef.Name != o.GetName() {
+				continue
+			}
+		case *apiv1.Secret:
+			if from.SecretKeyRef == nil || from.SecretKeyRef.Name != o.GetName() {
+				continue
+			}
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: c.Name, Namespace: c.Namespace}})
+	}
+	return requests
+}
+
+// ReadyzCheck is a healthz.Checker that fails unless the default ORY Hydra
+// admin server is ready. The admin servers configured by the clients are not
+// probed, as a client could otherwise make the controller unready: whether
+// they are reachable is reported in the status of their clients instead.
+func (r *OAuth2ClientReconciler) ReadyzCheck(req *http.Request) error {
+	if r.HydraClient == nil {
+		return nil
 	}
+	if err := r.HydraClient.Ready(req.Context()); err != nil {
+		return fmt.Errorf("default hydra admin is not ready: %w", err)
+	}
+	return nil
+}
+
+func (r *OAuth2ClientReconciler) ownsOAuth2Client(c *hydrav1alpha1.OAuth2Client) bool {
+	return c.Spec.ControllerClass == r.controllerClass
+}
+
+func (r *OAuth2ClientReconciler) controllerName() string {
+	if r.controllerClass == "" {
+		return r.controllerInstance
+	}
+	return r.controllerClass + "/" + r.controllerInstance
+}
 
-	hydraClient, err := r.getHydraClientForClient(*c)
+// registerOAuth2Client registers c in ORY Hydra under the given credentials,
+// and persists them afterwards if persist is set. If nil, credentials are
+// generated and persisted first. The clients registered before for c under
+// other credentials are replaced without downtime: they are only deleted by
+// continueReplacement once the new client is registered, its credentials are
+// persisted and the grace period elapsed.
+func (r *OAuth2ClientReconciler) registerOAuth2Client(ctx context.Context, c *hydrav1alpha1.OAuth2Client, creds *hydra.Oauth2ClientCredentials, persist bool) error {
+	hydraClient, err := r.getHydraClientForClient(ctx, *c)
 	if err != nil {
 		return err
 	}
 
-	oauth2client, err := hydra.FromOAuth2Client(c)
+	oauth2client, err := hydra.FromOAuth2Client(ctx, c)
 	if err != nil {
 		if updateErr := r.updateReconciliationStatusError(ctx, c, hydrav1alpha1.StatusRegistrationFailed, err); updateErr != nil {
 			return updateErr
@@ -269,59 +745,145 @@ func (r *OAuth2ClientReconciler) registerOAuth2Client(ctx context.Context, c *hy
 		return fmt.Errorf("failed to construct hydra client for object: %w", err)
 	}
 
-	if credentials != nil {
-		if _, err := hydraClient.PostOAuth2Client(oauth2client.WithCredentials(credentials)); err != nil {
-			if updateErr := r.updateReconciliationStatusError(ctx, c, hydrav1alpha1.StatusRegistrationFailed, err); updateErr != nil {
-				return updateErr
-			}
+	store, err := r.getCredentialStore(*c)
+	if err != nil {
+		return err
+	}
+
+	if creds == nil {
+		// Generate the credentials and persist them before registering the
+		// client, so that they are never lost if anything fails afterwards.
+		// The next reconciliation then registers the same credentials again.
+		creds, err = r.credentialsGenerator.Generate(c)
+		if err != nil {
+			return r.updateReconciliationStatusError(ctx, c, hydrav1alpha1.StatusRegistrationFailed, err)
 		}
-		return r.ensureEmptyStatusError(ctx, c)
+		ctx = r.loggerInto(ctx, client.ObjectKeyFromObject(c), string(creds.ID))
+		if err := r.persistCredentials(ctx, store, c, creds); err != nil {
+			return err
+		}
+		persist = false
 	}
 
-	created, err := hydraClient.PostOAuth2Client(oauth2client)
+	previous, err := r.previousOAuth2Clients(ctx, hydraClient, c, string(creds.ID))
 	if err != nil {
 		if updateErr := r.updateReconciliationStatusError(ctx, c, hydrav1alpha1.StatusRegistrationFailed, err); updateErr != nil {
 			return updateErr
 		}
+		return err
+	}
+
+	if len(previous) > 0 {
+		// the new client is recorded before it is registered, so that it is
+		// deleted again if the reconciliation does not complete
+		if err := r.setReplacement(ctx, c, &hydrav1alpha1.ReplacementStatus{
+			Phase:             hydrav1alpha1.ReplacementCreated,
+			ClientID:          string(creds.ID),
+			PreviousClientIDs: previous,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// generated key pairs are kept next to the persisted credentials once
+	// registered
+	jwks, keyPair, err := r.clientJwks(ctx, c)
+	if err != nil {
+		return r.jwksError(ctx, c, err)
+	}
+	oauth2client.WithJwks(jwks)
+
+	// the token lifespans are managed through their dedicated endpoint
+	lifespans := oauth2client.OAuth2ClientLifespans
+	oauth2client.OAuth2ClientLifespans = hydra.OAuth2ClientLifespans{}
+
+	registered, err := hydraClient.PostOAuth2Client(ctx, oauth2client.WithCredentials(creds))
+	if err != nil {
+		if updateErr := r.updateReconciliationStatusError(ctx, c, hydraErrorStatus(err, hydrav1alpha1.StatusRegistrationFailed), err); updateErr != nil {
+			return updateErr
+		}
 		return nil
 	}
 
-	clientSecret := apiv1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      c.Spec.SecretName,
-			Namespace: c.Namespace,
-			OwnerReferences: []metav1.OwnerReference{{
-				APIVersion: c.TypeMeta.APIVersion,
-				Kind:       c.TypeMeta.Kind,
-				Name:       c.ObjectMeta.Name,
-				UID:        c.ObjectMeta.UID,
-			}},
-		},
-		Data: map[string][]byte{
-			ClientIDKey: []byte(*created.ClientID),
-		},
+	if !lifespans.IsZero() {
+		updated, err := hydraClient.PutOAuth2ClientLifespans(ctx, string(creds.ID), &lifespans)
+		if err != nil {
+			if updateErr := r.updateReconciliationStatusError(ctx, c, hydraErrorStatus(err, hydrav1alpha1.StatusRegistrationFailed), err); updateErr != nil {
+				return updateErr
+			}
+			return nil
+		}
+		if updated != nil {
+			registered = updated
+		}
 	}
 
-	if created.Secret != nil {
-		clientSecret.Data[ClientSecretKey] = []byte(*created.Secret)
+	if persist {
+		if err := r.persistCredentials(ctx, store, c, creds); err != nil {
+			return err
+		}
+	}
+	if err := r.storeKeyPair(ctx, c, keyPair); err != nil {
+		return err
+	}
+	if _, err := r.storeClientSecretHash(ctx, c, creds.Password); err != nil {
+		return err
 	}
 
-	if err := r.Create(ctx, &clientSecret); err != nil {
+	if len(previous) > 0 {
+		ctrl.LoggerFrom(ctx).Info("replacing clients", "previousClientIDs", previous)
+		if err := r.setReplacement(ctx, c, &hydrav1alpha1.ReplacementStatus{
+			Phase:             hydrav1alpha1.ReplacementSecretUpdated,
+			ClientID:          string(creds.ID),
+			PreviousClientIDs: previous,
+			SecretUpdateTime:  &metav1.Time{Time: time.Now()},
+		}); err != nil {
+			return err
+		}
+	}
+
+	setRegisteredStatus(c, string(creds.ID), registered)
+	c.Status.JwksHash = jwksHash(jwks)
+	return r.ensureEmptyStatusError(ctx, c)
+}
+
+// persistCredentials puts the credentials of c in its credential store.
+func (r *OAuth2ClientReconciler) persistCredentials(ctx context.Context, store credentials.Store, c *hydrav1alpha1.OAuth2Client, creds *hydra.Oauth2ClientCredentials) error {
+	if err := store.Put(ctx, c, creds); err != nil {
 		if updateErr := r.updateReconciliationStatusError(ctx, c, hydrav1alpha1.StatusCreateSecretFailed, err); updateErr != nil {
 			return updateErr
 		}
+		return err
+	}
+	return nil
+}
+
+func (r *OAuth2ClientReconciler) changeClientID(ctx context.Context, c *hydrav1alpha1.OAuth2Client, clientID string, creds *hydra.Oauth2ClientCredentials) error {
+	ctrl.LoggerFrom(ctx).Info("client ID changed", "newClientID", clientID)
+	ctx = r.loggerInto(ctx, client.ObjectKeyFromObject(c), clientID)
+
+	changed := &hydra.Oauth2ClientCredentials{
+		ID:       []byte(clientID),
+		Password: creds.Password,
+	}
+	if changed.Password == nil && credentials.UsesSecret(c) {
+		generated, err := r.credentialsGenerator.Generate(c)
+		if err != nil {
+			return r.updateReconciliationStatusError(ctx, c, hydrav1alpha1.StatusRegistrationFailed, err)
+		}
+		changed.Password = generated.Password
 	}
 
-	return r.ensureEmptyStatusError(ctx, c)
+	return r.registerOAuth2Client(ctx, c, changed, true)
 }
 
 func (r *OAuth2ClientReconciler) updateRegisteredOAuth2Client(ctx context.Context, c *hydrav1alpha1.OAuth2Client, credentials *hydra.Oauth2ClientCredentials) error {
-	hydraClient, err := r.getHydraClientForClient(*c)
+	hydraClient, err := r.getHydraClientForClient(ctx, *c)
 	if err != nil {
 		return err
 	}
 
-	oauth2client, err := hydra.FromOAuth2Client(c)
+	oauth2client, err := hydra.FromOAuth2Client(ctx, c)
 	if err != nil {
 		if updateErr := r.updateReconciliationStatusError(ctx, c, hydrav1alpha1.StatusUpdateFailed, err); updateErr != nil {
 			return updateErr
@@ -330,27 +892,94 @@ func (r *OAuth2ClientReconciler) updateRegisteredOAuth2Client(ctx context.Contex
 		return fmt.Errorf("failed to construct hydra client for object: %w", err)
 	}
 
-	if _, err := hydraClient.PutOAuth2Client(oauth2client.WithCredentials(credentials)); err != nil {
-		if updateErr := r.updateReconciliationStatusError(ctx, c, hydrav1alpha1.StatusUpdateFailed, err); updateErr != nil {
+	var keyID string
+	if c.Status.KeyPair != nil {
+		keyID = c.Status.KeyPair.KeyID
+	}
+	jwks, keyPair, err := r.clientJwks(ctx, c)
+	if err != nil {
+		return r.jwksError(ctx, c, err)
+	}
+	oauth2client.WithJwks(jwks)
+
+	lifespans := oauth2client.OAuth2ClientLifespans
+	oauth2client.OAuth2ClientLifespans = hydra.OAuth2ClientLifespans{}
+
+	updated, err := hydraClient.PutOAuth2Client(ctx, oauth2client.WithCredentials(credentials))
+	if err != nil {
+		if updateErr := r.updateReconciliationStatusError(ctx, c, hydraErrorStatus(err, hydrav1alpha1.StatusUpdateFailed), err); updateErr != nil {
+			return updateErr
+		}
+		return nil
+	}
+
+	// the new public key is registered, the Secret may now hold its private
+	// key
+	if err := r.storeKeyPair(ctx, c, keyPair); err != nil {
+		return err
+	}
+
+	secretRotated, err := r.storeClientSecretHash(ctx, c, credentials.Password)
+	if err != nil {
+		return err
+	}
+
+	if secretRotated || (keyID != "" && c.Status.KeyPair != nil && c.Status.KeyPair.KeyID != keyID) {
+		// the client secret or the generated key pair has been rotated
+		r.revokeOAuth2Client(ctx, hydraClient, c, string(credentials.ID), hydrav1alpha1.RevocationOnRekey)
+	}
+
+	// also reset the lifespans ORY Hydra kept from a previous spec
+	if !lifespans.IsZero() || (updated != nil && !updated.OAuth2ClientLifespans.IsZero()) {
+		withLifespans, err := hydraClient.PutOAuth2ClientLifespans(ctx, string(credentials.ID), &lifespans)
+		if err != nil {
+			if updateErr := r.updateReconciliationStatusError(ctx, c, hydraErrorStatus(err, hydrav1alpha1.StatusUpdateFailed), err); updateErr != nil {
+				return updateErr
+			}
+			return nil
+		}
+		if withLifespans != nil {
+			updated = withLifespans
+		}
+	}
+
+	setRegisteredStatus(c, string(credentials.ID), updated)
+	c.Status.JwksHash = jwksHash(jwks)
+	return r.ensureEmptyStatusError(ctx, c)
+}
+
+func (r *OAuth2ClientReconciler) updateOAuth2ClientLifespans(ctx context.Context, c *hydrav1alpha1.OAuth2Client, credentials *hydra.Oauth2ClientCredentials) error {
+	hydraClient, err := r.getHydraClientForClient(ctx, *c)
+	if err != nil {
+		return err
+	}
+
+	lifespans := hydra.LifespansFromTokenLifespans(c.Spec.TokenLifespans)
+	updated, err := hydraClient.PutOAuth2ClientLifespans(ctx, string(credentials.ID), &lifespans)
+	if err != nil {
+		if updateErr := r.updateReconciliationStatusError(ctx, c, hydraErrorStatus(err, hydrav1alpha1.StatusUpdateFailed), err); updateErr != nil {
 			return updateErr
 		}
+		return nil
 	}
+
+	setRegisteredStatus(c, string(credentials.ID), updated)
 	return r.ensureEmptyStatusError(ctx, c)
 }
 
-func (r *OAuth2ClientReconciler) unregisterOAuth2Clients(ctx context.Context, c *hydrav1alpha1.OAuth2Client) error {
+func (r *OAuth2ClientReconciler) unregisterOAuth2Clients(ctx context.Context, c *hydrav1alpha1.OAuth2Client, trigger hydrav1alpha1.RevocationTrigger) error {
 	// if a required field is empty, that means this is deleted after
 	// the finalizers have done their job, so just return
 	if (c.Spec.Scope == "" && len(c.Spec.ScopeArray) == 0) || c.Spec.SecretName == "" {
 		return nil
 	}
 
-	h, err := r.getHydraClientForClient(*c)
+	h, err := r.getHydraClientForClient(ctx, *c)
 	if err != nil {
 		return err
 	}
 
-	clients, err := h.ListOAuth2Client()
+	clients, err := h.ListOAuth2Client(ctx)
 	if err != nil {
 		return err
 	}
@@ -359,10 +988,11 @@ func (r *OAuth2ClientReconciler) unregisterOAuth2Clients(ctx context.Context, c
 		if cJSON.Owner == fmt.Sprintf("%s/%s", c.Name, c.Namespace) {
 			if c.Spec.DeletionPolicy == hydrav1alpha1.OAuth2ClientDeletionPolicyOrphan {
 				// Do not delete the OAuth2 client.
-				r.Log.Info("oauth2 client deletion, leave the row orphan")
+				ctrl.LoggerFrom(ctx).Info("client deleted, leaving it orphan in ORY Hydra")
 				return nil
 			}
-			if err := h.DeleteOAuth2Client(*cJSON.ClientID); err != nil {
+			r.revokeOAuth2Client(ctx, h, c, *cJSON.ClientID, trigger)
+			if err := h.DeleteOAuth2Client(ctx, *cJSON.ClientID); err != nil {
 				return err
 			}
 		}
@@ -371,14 +1001,41 @@ func (r *OAuth2ClientReconciler) unregisterOAuth2Clients(ctx context.Context, c
 	return nil
 }
 
+// updateReconciliationStatusError reports err in the status of the client.
+// Once reported, a transient error is returned as a retryError, so that the
+// client is retried after a delay growing with each consecutive transient
+// error.
 func (r *OAuth2ClientReconciler) updateReconciliationStatusError(ctx context.Context, c *hydrav1alpha1.OAuth2Client, code hydrav1alpha1.StatusCode, err error) error {
-	r.Log.Error(err, fmt.Sprintf("error processing client %s/%s ", c.Name, c.Namespace), "oauth2client", "register")
-
+	ctrl.LoggerFrom(ctx).Error(err, "error processing client", "code", code)
+
+	hydraVersion := r.getHydraVersion(ctx, *c)
+	resyncAt := c.Annotations[ResyncAtAnnotation]
+	transient := isTransient(err)
+	var retryErr *retryError
+	// the last revocation is set by the caller, and lost when the object is
+	// fetched again
+	revocation := c.Status.Revocation
+	reconcileErr := err
 	_, err = controllerutil.CreateOrPatch(ctx, r.Client, c, func() error {
 		c.Status.ObservedGeneration = c.Generation
+		c.Status.ResyncAt = resyncAt
+		c.Status.Revocation = revocation
+		if transient {
+			c.Status.RetryCount++
+			retryErr = &retryError{after: r.retryDelay(c.Status.RetryCount), err: reconcileErr}
+			c.Status.NextRetryTime = &metav1.Time{Time: time.Now().Add(retryErr.after)}
+		} else {
+			c.Status.RetryCount = 0
+			c.Status.NextRetryTime = nil
+		}
+		if hydraVersion != "" {
+			c.Status.HydraVersion = hydraVersion
+		}
+		c.Status.Controller = r.controllerName()
+		c.Status.Hydra = r.hydraAddress(c)
 		c.Status.ReconciliationError = hydrav1alpha1.ReconciliationError{
 			Code:        code,
-			Description: err.Error(),
+			Description: reconcileErr.Error(),
 		}
 		c.Status.Conditions = []hydrav1alpha1.OAuth2ClientCondition{
 			{
@@ -386,20 +1043,55 @@ func (r *OAuth2ClientReconciler) updateReconciliationStatusError(ctx context.Con
 				Status: hydrav1alpha1.ConditionFalse,
 			},
 		}
+		if hydra.IsUnavailable(reconcileErr) {
+			c.Status.Conditions = append(c.Status.Conditions, hydrav1alpha1.OAuth2ClientCondition{
+				Type:   hydrav1alpha1.OAuth2ClientConditionHydraReachable,
+				Status: hydrav1alpha1.ConditionFalse,
+			})
+		}
 
 		return nil
 	})
 	if err != nil {
-		r.Log.Error(err, fmt.Sprintf("status update failed for client %s/%s ", c.Name, c.Namespace), "oauth2client", "update status")
+		ctrl.LoggerFrom(ctx).Error(err, "status update failed")
+		return err
+	}
+	if retryErr != nil {
+		return retryErr
 	}
 
-	return err
+	return nil
 }
 
 func (r *OAuth2ClientReconciler) ensureEmptyStatusError(ctx context.Context, c *hydrav1alpha1.OAuth2Client) error {
+	hydraVersion := r.getHydraVersion(ctx, *c)
+	secretName, secretVersion := r.syncedSecret(ctx, c)
+	resyncAt := c.Annotations[ResyncAtAnnotation]
+	// the client as registered in ORY Hydra, the hash of the applied JSON
+	// Web Key Set, the generated key pair and the last revocation are set by
+	// the caller, and lost when the object is fetched again
+	registered := c.Status.DeepCopy()
 	_, err := controllerutil.CreateOrPatch(ctx, r.Client, c, func() error {
 		c.Status.ObservedGeneration = c.Generation
+		if hydraVersion != "" {
+			c.Status.HydraVersion = hydraVersion
+		}
+		c.Status.Controller = r.controllerName()
+		c.Status.ClientID = registered.ClientID
+		c.Status.Hydra = r.hydraAddress(c)
+		c.Status.CreatedAt = registered.CreatedAt
+		c.Status.UpdatedAt = registered.UpdatedAt
+		c.Status.SecretName = secretName
+		c.Status.SecretResourceVersion = secretVersion
+		c.Status.LastSyncTime = &metav1.Time{Time: time.Now()}
+		c.Status.ResyncAt = resyncAt
+		c.Status.RetryCount = 0
+		c.Status.NextRetryTime = nil
 		c.Status.ReconciliationError = hydrav1alpha1.ReconciliationError{}
+		c.Status.SpecHash = specHash(c.Spec)
+		c.Status.JwksHash = registered.JwksHash
+		c.Status.KeyPair = registered.KeyPair
+		c.Status.Revocation = registered.Revocation
 		c.Status.Conditions = []hydrav1alpha1.OAuth2ClientCondition{
 			{
 				Type:   hydrav1alpha1.OAuth2ClientConditionReady,
@@ -410,31 +1102,13 @@ func (r *OAuth2ClientReconciler) ensureEmptyStatusError(ctx context.Context, c *
 		return nil
 	})
 	if err != nil {
-		r.Log.Error(err, fmt.Sprintf("status update failed for client %s/%s ", c.Name, c.Namespace), "oauth2client", "update status")
+		ctrl.LoggerFrom(ctx).Error(err, "status update failed")
 	}
 
 	return err
 }
 
-func parseSecret(secret apiv1.Secret, authMethod hydrav1alpha1.TokenEndpointAuthMethod) (*hydra.Oauth2ClientCredentials, error) {
-	id, found := secret.Data[ClientIDKey]
-	if !found {
-		return nil, fmt.Errorf("%s property missing", ClientIDKey)
-	}
-
-	psw, found := secret.Data[ClientSecretKey]
-	if !found && authMethod != "none" {
-		return nil, fmt.Errorf("%s property missing", ClientSecretKey)
-	}
-
-	return &hydra.Oauth2ClientCredentials{
-		ID:       id,
-		Password: psw,
-	}, nil
-}
-
-func (r *OAuth2ClientReconciler) getHydraClientForClient(
-	oauth2client hydrav1alpha1.OAuth2Client) (hydra.Client, error) {
+func (r *OAuth2ClientReconciler) getHydraClientForClient(ctx context.Context, oauth2client hydrav1alpha1.OAuth2Client) (hydra.Client, error) {
 	spec := oauth2client.Spec
 	if spec.HydraAdmin.URL != "" {
 		key := clientKey{
@@ -462,12 +1136,232 @@ func (r *OAuth2ClientReconciler) getHydraClientForClient(
 		return nil, fmt.Errorf("no default client configured")
 	}
 
-	r.Log.Info("Using default client")
+	ctrl.LoggerFrom(ctx).V(1).Info("using default hydra client")
 
 	return r.HydraClient, nil
 
 }
 
+// hydraAddress returns the address of the ORY Hydra admin server the client
+// is registered in.
+func (r *OAuth2ClientReconciler) hydraAddress(c *hydrav1alpha1.OAuth2Client) string {
+	admin := c.Spec.HydraAdmin
+	if admin.URL == "" {
+		return r.hydraURL
+	}
+	if admin.Port == 0 {
+		return admin.URL
+	}
+	return fmt.Sprintf("%s:%d", admin.URL, admin.Port)
+}
+
+// syncedSecret returns the name and resourceVersion of the Secret holding
+// the credentials of the client, or empty strings if they are not held in a
+// Secret.
+func (r *OAuth2ClientReconciler) syncedSecret(ctx context.Context, c *hydrav1alpha1.OAuth2Client) (string, string) {
+	if c.Spec.CredentialStore != "" && c.Spec.CredentialStore != hydrav1alpha1.CredentialStoreSecret {
+		return "", ""
+	}
+
+	var secret apiv1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Name: c.Spec.SecretName, Namespace: c.Namespace}, &secret); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "unable to get secret", "secret", c.Spec.SecretName)
+		return c.Spec.SecretName, ""
+	}
+	return secret.Name, secret.ResourceVersion
+}
+
+// secretChanged returns true if the Secret holding the credentials of the
+// client changed since the client was last synced, e.g. because its client
+// secret was rotated.
+func (r *OAuth2ClientReconciler) secretChanged(ctx context.Context, c *hydrav1alpha1.OAuth2Client) bool {
+	if c.Status.SecretResourceVersion == "" {
+		return false
+	}
+	_, resourceVersion := r.syncedSecret(ctx, c)
+	return resourceVersion != "" && resourceVersion != c.Status.SecretResourceVersion
+}
+
+// setRegisteredStatus records the client as registered in ORY Hydra under
+// clientID in its status.
+func setRegisteredStatus(c *hydrav1alpha1.OAuth2Client, clientID string, registered *hydra.OAuth2ClientJSON) {
+	c.Status.ClientID = clientID
+	if registered == nil {
+		return
+	}
+	if t, err := time.Parse(time.RFC3339Nano, registered.CreatedAt); err == nil {
+		c.Status.CreatedAt = &metav1.Time{Time: t}
+	}
+	if t, err := time.Parse(time.RFC3339Nano, registered.UpdatedAt); err == nil {
+		c.Status.UpdatedAt = &metav1.Time{Time: t}
+	}
+}
+
+// getHydraVersion returns the version of the ORY Hydra instance of the
+// client, or an empty string if it is unknown.
+func (r *OAuth2ClientReconciler) getHydraVersion(ctx context.Context, oauth2client hydrav1alpha1.OAuth2Client) string {
+	hydraClient, err := r.getHydraClientForClient(ctx, oauth2client)
+	if err != nil {
+		return ""
+	}
+
+	version, err := hydraClient.Version(ctx)
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "unable to get hydra version")
+		return ""
+	}
+	return version
+}
+
+// specHash returns a hash of the spec applied in ORY Hydra, ignoring the token
+// lifespans, which can be updated on their own.
+func specHash(spec hydrav1alpha1.OAuth2ClientSpec) string {
+	spec.TokenLifespans = hydrav1alpha1.TokenLifespans{}
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// resolveJwks returns the JSON Web Key Set the client takes from
+// spec.jwksFrom, or nil if it is not set.
+func (r *OAuth2ClientReconciler) resolveJwks(ctx context.Context, c *hydrav1alpha1.OAuth2Client) (*hydra.JSONWebKeySet, error) {
+	from := c.Spec.JwksFrom
+	if from == nil {
+		return nil, nil
+	}
+
+	var raw string
+	switch {
+	case from.ConfigMapKeyRef != nil:
+		var configMap apiv1.ConfigMap
+		if err := r.Get(ctx, types.NamespacedName{Name: from.ConfigMapKeyRef.Name, Namespace: c.Namespace}, &configMap); err != nil {
+			if apierrs.IsNotFound(err) {
+				return nil, &invalidJWKError{fmt.Errorf("config map %s/%s does not exist", c.Namespace, from.ConfigMapKeyRef.Name)}
+			}
+			return nil, err
+		}
+		var ok bool
+		if raw, ok = configMap.Data[from.ConfigMapKeyRef.Key]; !ok {
+			return nil, &invalidJWKError{fmt.Errorf("config map %s/%s has no key %s", c.Namespace, from.ConfigMapKeyRef.Name, from.ConfigMapKeyRef.Key)}
+		}
+	case from.SecretKeyRef != nil:
+		var secret apiv1.Secret
+		if err := r.Get(ctx, types.NamespacedName{Name: from.SecretKeyRef.Name, Namespace: c.Namespace}, &secret); err != nil {
+			if apierrs.IsNotFound(err) {
+				return nil, &invalidJWKError{fmt.Errorf("secret %s/%s does not exist", c.Namespace, from.SecretKeyRef.Name)}
+			}
+			return nil, err
+		}
+		b, ok := secret.Data[from.SecretKeyRef.Key]
+		if !ok {
+			return nil, &invalidJWKError{fmt.Errorf("secret %s/%s has no key %s", c.Namespace, from.SecretKeyRef.Name, from.SecretKeyRef.Key)}
+		}
+		raw = string(b)
+	default:
+		return nil, &invalidJWKError{errors.New("neither configMapKeyRef nor secretKeyRef is set in jwksFrom")}
+	}
+
+	var jwks hydra.JSONWebKeySet
+	if err := json.Unmarshal([]byte(raw), &jwks); err != nil {
+		return nil, &invalidJWKError{fmt.Errorf("invalid JSON Web Key Set: %w", err)}
+	}
+	if len(jwks.Keys) == 0 {
+		return nil, &invalidJWKError{errors.New("invalid JSON Web Key Set: no keys")}
+	}
+
+	// only the public keys are sent to ORY Hydra, even if the set holds the
+	// private ones
+	public := &hydra.JSONWebKeySet{Keys: make([]hydra.JSONWebKey, 0, len(jwks.Keys))}
+	for _, k := range jwks.Keys {
+		p := k.Public()
+		if p == nil {
+			return nil, &invalidJWKError{fmt.Errorf("invalid JSON Web Key Set: key %q is symmetric and has no public form", k.KeyID())}
+		}
+		public.Keys = append(public.Keys, p)
+	}
+	return public, nil
+}
+
+// clientJwks returns the JSON Web Key Set sent inline to ORY Hydra for the
+// client, either taken from spec.jwksFrom or generated, or nil, and the
+// Secret holding the generated key pair to store once it is registered, if
+// any.
+func (r *OAuth2ClientReconciler) clientJwks(ctx context.Context, c *hydrav1alpha1.OAuth2Client) (*hydra.JSONWebKeySet, *apiv1.Secret, error) {
+	if c.Spec.KeyPairGeneration != nil {
+		return r.ensureKeyPair(ctx, c)
+	}
+	jwks, err := r.resolveJwks(ctx, c)
+	return jwks, nil, err
+}
+
+// jwksChanged returns true if the JSON Web Key Set taken from spec.jwksFrom
+// differs from the one last applied in ORY Hydra, or if the generated key
+// pair is due for rotation.
+func (r *OAuth2ClientReconciler) jwksChanged(ctx context.Context, c *hydrav1alpha1.OAuth2Client) bool {
+	if c.Spec.KeyPairGeneration != nil {
+		return keyPairDue(c, time.Now())
+	}
+	if c.Spec.JwksFrom == nil {
+		return false
+	}
+
+	jwks, err := r.resolveJwks(ctx, c)
+	if err != nil {
+		// let the update report the error
+		return true
+	}
+	return jwksHash(jwks) != c.Status.JwksHash
+}
+
+// jwksError reports the error of resolving the JSON Web Key Set of a client.
+func (r *OAuth2ClientReconciler) jwksError(ctx context.Context, c *hydrav1alpha1.OAuth2Client, err error) error {
+	if !isInvalidJWK(err) {
+		return err
+	}
+	// the ConfigMap and Secret watches trigger a new reconciliation once it
+	// is fixed
+	return r.updateReconciliationStatusError(ctx, c, hydrav1alpha1.StatusInvalidJWK, err)
+}
+
+// jwksHash returns a hash of a JSON Web Key Set, or an empty string for nil.
+func jwksHash(jwks *hydra.JSONWebKeySet) string {
+	if jwks == nil {
+		return ""
+	}
+	b, err := json.Marshal(jwks)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// hydraErrorStatus returns the status code reported for an error returned by
+// ORY Hydra, defaulting to code.
+func hydraErrorStatus(err error, code hydrav1alpha1.StatusCode) hydrav1alpha1.StatusCode {
+	if hydra.IsUnsupported(err) {
+		return hydrav1alpha1.StatusUnsupportedField
+	}
+	return code
+}
+
+func (r *OAuth2ClientReconciler) getCredentialStore(oauth2client hydrav1alpha1.OAuth2Client) (credentials.Store, error) {
+	t := oauth2client.Spec.CredentialStore
+	if t == "" {
+		t = hydrav1alpha1.CredentialStoreSecret
+	}
+
+	store, ok := r.credentialStores[t]
+	if !ok {
+		return nil, fmt.Errorf("credential store %q is not configured", t)
+	}
+
+	return store, nil
+}
+
 // Helper functions to check and remove string from a slice of strings.
 func containsString(slice []string, s string) bool {
 	for _, item := range slice {
@@ -0,0 +1,295 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/controllers"
+	"github.com/ory/hydra-maester/hydra"
+	"github.com/ory/hydra-maester/hydra/hydratest"
+)
+
+var _ = Describe("OAuth2Client Controller against a fake ORY Hydra", func() {
+
+	// startManager starts a manager running the reconciler with the hydra
+	// clients of the OAuth2Clients, which talk to the fake over HTTP.
+	startManager := func(port int, opts ...controllers.Option) client.Client {
+		s := runtime.NewScheme()
+		Expect(hydrav1alpha1.AddToScheme(s)).To(Succeed())
+		Expect(apiv1.AddToScheme(s)).To(Succeed())
+
+		mgr, err := manager.New(cfg, manager.Options{
+			Scheme: s,
+			Metrics: server.Options{
+				BindAddress: fmt.Sprintf(":%d", port),
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		r := controllers.New(mgr.GetClient(), nil, ctrl.Log.WithName("controllers").WithName("OAuth2Client"),
+			append([]controllers.Option{controllers.WithRetryBackoff(100*time.Millisecond, time.Second)}, opts...)...)
+		recFn, requests := SetupTestReconcile(r)
+		Expect(add(mgr, recFn)).To(Succeed())
+
+		// the specs wait for the outcome of several reconciliations
+		go func() {
+			for range requests {
+			}
+		}()
+
+		StartTestManager(mgr)
+		return mgr.GetClient()
+	}
+
+	fakeInstance := func(name, secretName string, fake *hydratest.Server) *hydrav1alpha1.OAuth2Client {
+		instance := testInstance(name, secretName)
+		instance.Spec.HydraAdmin = fake.HydraAdmin()
+		return instance
+	}
+
+	It("register, update and delete the client", func() {
+		tstName, tstSecretName := "test-fake", "my-secret-fake"
+		key := types.NamespacedName{Name: tstName, Namespace: tstNamespace}
+
+		// list the clients one by one to go through the pages
+		fake := hydratest.NewServer(hydratest.WithPageSize(1))
+		defer fake.Close()
+		fake.Add(hydra.OAuth2ClientJSON{ClientID: ptr.To("stranger"), Owner: "other/" + tstNamespace})
+
+		c := startManager(8103)
+
+		instance := fakeInstance(tstName, tstSecretName, fake)
+		instance.Spec.DeletionPolicy = hydrav1alpha1.OAuth2ClientDeletionPolicyDelete
+		Expect(c.Create(context.TODO(), instance)).To(Succeed())
+
+		var retrieved hydrav1alpha1.OAuth2Client
+		Eventually(func() []hydrav1alpha1.OAuth2ClientCondition {
+			Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+			return retrieved.Status.Conditions
+		}, timeout).Should(Equal([]hydrav1alpha1.OAuth2ClientCondition{{
+			Type:   hydrav1alpha1.OAuth2ClientConditionReady,
+			Status: hydrav1alpha1.ConditionTrue,
+		}}))
+
+		// Verify the client is registered with the credentials of the secret
+		var createdSecret apiv1.Secret
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Name: tstSecretName, Namespace: tstNamespace}, &createdSecret)).To(Succeed())
+		clientID := string(createdSecret.Data[controllers.ClientIDKey])
+		registered, ok := fake.Client(clientID)
+		Expect(ok).To(BeTrue())
+		Expect(registered.Owner).To(Equal(fmt.Sprintf("%s/%s", tstName, tstNamespace)))
+		Expect(registered.Scope).To(Equal("a b c"))
+		Expect(registered.Secret).To(Equal(ptr.To(string(createdSecret.Data[controllers.ClientSecretKey]))))
+
+		// Verify the client is updated with the resource
+		Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+		retrieved.Spec.Scope = "a b"
+		Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+		Eventually(func() string {
+			registered, _ := fake.Client(clientID)
+			return registered.Scope
+		}, timeout).Should(Equal("a b"))
+
+		// Verify the client is deleted with the resource, but not the
+		// clients of other resources
+		Expect(c.Delete(context.TODO(), &retrieved)).To(Succeed())
+		Eventually(func() bool {
+			_, ok := fake.Client(clientID)
+			return ok
+		}, timeout).Should(BeFalse())
+		_, ok = fake.Client("stranger")
+		Expect(ok).To(BeTrue())
+	})
+
+	It("retry while ORY Hydra fails", func() {
+		tstName, tstSecretName := "test-fake-faults", "my-secret-fake-faults"
+		key := types.NamespacedName{Name: tstName, Namespace: tstNamespace}
+
+		fake := hydratest.NewServer()
+		defer fake.Close()
+		fake.Inject(hydratest.Fault{Method: http.MethodPost, StatusCode: http.StatusServiceUnavailable, Times: 2})
+
+		c := startManager(8104)
+
+		instance := fakeInstance(tstName, tstSecretName, fake)
+		Expect(c.Create(context.TODO(), instance)).To(Succeed())
+
+		var retrieved hydrav1alpha1.OAuth2Client
+		Eventually(func() []hydrav1alpha1.OAuth2ClientCondition {
+			Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+			return retrieved.Status.Conditions
+		}, timeout).Should(Equal([]hydrav1alpha1.OAuth2ClientCondition{{
+			Type:   hydrav1alpha1.OAuth2ClientConditionReady,
+			Status: hydrav1alpha1.ConditionTrue,
+		}}))
+		Expect(retrieved.Status.RetryCount).To(BeZero())
+		Expect(fake.Clients()).To(HaveLen(1))
+
+		var posts int
+		for _, req := range fake.Requests() {
+			if req == "POST /admin/clients" {
+				posts++
+			}
+		}
+		Expect(posts).To(Equal(3))
+
+		Expect(c.Delete(context.TODO(), instance)).To(Succeed())
+	})
+
+	It("not take over the client of another resource", func() {
+		tstName, tstSecretName := "test-fake-conflict", "my-secret-fake-conflict"
+		key := types.NamespacedName{Name: tstName, Namespace: tstNamespace}
+		owner := "other/" + tstNamespace
+
+		fake := hydratest.NewServer()
+		defer fake.Close()
+		fake.Add(hydra.OAuth2ClientJSON{ClientID: ptr.To("taken"), Owner: owner, Scope: "x"})
+
+		c := startManager(8105)
+
+		instance := fakeInstance(tstName, tstSecretName, fake)
+		instance.Spec.ClientID = "taken"
+		Expect(c.Create(context.TODO(), instance)).To(Succeed())
+
+		var retrieved hydrav1alpha1.OAuth2Client
+		Eventually(func() hydrav1alpha1.StatusCode {
+			Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+			return retrieved.Status.ReconciliationError.Code
+		}, timeout).ShouldNot(BeEmpty())
+		Expect(retrieved.Status.ReconciliationError.Code).To(BeElementOf(hydrav1alpha1.StatusRegistrationFailed, hydrav1alpha1.StatusInvalidSecret))
+
+		registered, ok := fake.Client("taken")
+		Expect(ok).To(BeTrue())
+		Expect(registered.Owner).To(Equal(owner))
+		Expect(registered.Scope).To(Equal("x"))
+
+		Expect(c.Delete(context.TODO(), instance)).To(Succeed())
+	})
+
+	It("not register a client violating a policy until the policy allows it", func() {
+		tstName, tstSecretName := "test-fake-policy", "my-secret-fake-policy"
+		key := types.NamespacedName{Name: tstName, Namespace: tstNamespace}
+
+		fake := hydratest.NewServer()
+		defer fake.Close()
+
+		c := startManager(8106)
+
+		policy := &hydrav1alpha1.OAuth2ClientPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-fake-policy", Namespace: tstNamespace},
+			Spec: hydrav1alpha1.OAuth2ClientPolicySpec{
+				AllowedScopes: []string{"a", "b"},
+			},
+		}
+		Expect(c.Create(context.TODO(), policy)).To(Succeed())
+
+		instance := fakeInstance(tstName, tstSecretName, fake)
+		Expect(c.Create(context.TODO(), instance)).To(Succeed())
+
+		var retrieved hydrav1alpha1.OAuth2Client
+		Eventually(func() hydrav1alpha1.StatusCode {
+			Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+			return retrieved.Status.ReconciliationError.Code
+		}, timeout).Should(Equal(hydrav1alpha1.StatusPolicyViolation))
+		Expect(retrieved.Status.ReconciliationError.Description).To(ContainSubstring(`spec.scope: Forbidden: scope "c" is not allowed by OAuth2ClientPolicy default/test-fake-policy`))
+		Expect(fake.Clients()).To(BeEmpty())
+
+		// Verify the client is registered once the policy allows it
+		policy.Spec.AllowedScopes = append(policy.Spec.AllowedScopes, "c")
+		Expect(c.Update(context.TODO(), policy)).To(Succeed())
+		// the test controller does not watch the policies
+		Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+		retrieved.Annotations = map[string]string{"test": "policy-updated"}
+		Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+
+		Eventually(func() []hydrav1alpha1.OAuth2ClientCondition {
+			Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+			return retrieved.Status.Conditions
+		}, timeout).Should(Equal([]hydrav1alpha1.OAuth2ClientCondition{{
+			Type:   hydrav1alpha1.OAuth2ClientConditionReady,
+			Status: hydrav1alpha1.ConditionTrue,
+		}}))
+		Expect(retrieved.Status.ReconciliationError.Code).To(BeEmpty())
+		Expect(fake.Clients()).To(HaveLen(1))
+
+		Expect(c.Delete(context.TODO(), instance)).To(Succeed())
+		Expect(c.Delete(context.TODO(), policy)).To(Succeed())
+	})
+
+	It("not register a client requesting an unapproved scope until it is approved", func() {
+		tstName, tstSecretName := "test-fake-scope", "my-secret-fake-scope"
+		key := types.NamespacedName{Name: tstName, Namespace: tstNamespace}
+
+		fake := hydratest.NewServer()
+		defer fake.Close()
+
+		c := startManager(8107, controllers.WithScopeOwnership())
+
+		// the scopes a and b are owned by the namespace of the client, and c
+		// by another namespace
+		var scopes []*hydrav1alpha1.OAuth2Scope
+		for _, scope := range []struct{ namespace, name string }{
+			{tstNamespace, "a"},
+			{tstNamespace, "b"},
+			{"test-fake-scope-owner", "c"},
+		} {
+			s := &hydrav1alpha1.OAuth2Scope{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-fake-scope-" + scope.name},
+				Spec:       hydrav1alpha1.OAuth2ScopeSpec{Scope: scope.name, Namespace: scope.namespace},
+			}
+			Expect(c.Create(context.TODO(), s)).To(Succeed())
+			scopes = append(scopes, s)
+		}
+
+		instance := fakeInstance(tstName, tstSecretName, fake)
+		Expect(c.Create(context.TODO(), instance)).To(Succeed())
+
+		var retrieved hydrav1alpha1.OAuth2Client
+		Eventually(func() hydrav1alpha1.StatusCode {
+			Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+			return retrieved.Status.ReconciliationError.Code
+		}, timeout).Should(Equal(hydrav1alpha1.StatusScopeDenied))
+		Expect(retrieved.Status.ReconciliationError.Description).To(Equal(`spec.scope: Forbidden: scope "c" is not approved for the client by OAuth2Scope test-fake-scope-c`))
+		Expect(fake.Clients()).To(BeEmpty())
+
+		// Verify the client is registered once the scope is approved
+		scopes[2].Spec.Approvals = []hydrav1alpha1.OAuth2ScopeApproval{{Namespace: tstNamespace, Clients: []string{tstName}}}
+		Expect(c.Update(context.TODO(), scopes[2])).To(Succeed())
+		// the test controller does not watch the scopes
+		Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+		retrieved.Annotations = map[string]string{"test": "scope-approved"}
+		Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+
+		Eventually(func() []hydrav1alpha1.OAuth2ClientCondition {
+			Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+			return retrieved.Status.Conditions
+		}, timeout).Should(Equal([]hydrav1alpha1.OAuth2ClientCondition{{
+			Type:   hydrav1alpha1.OAuth2ClientConditionReady,
+			Status: hydrav1alpha1.ConditionTrue,
+		}}))
+		Expect(fake.Clients()).To(HaveLen(1))
+
+		Expect(c.Delete(context.TODO(), instance)).To(Succeed())
+		for _, s := range scopes {
+			Expect(c.Delete(context.TODO(), s)).To(Succeed())
+		}
+	})
+})
@@ -4,19 +4,23 @@
 package controllers_test
 
 import (
+	"bytes"
 	"context"
-	"errors"
+	"encoding/json"
 	"fmt"
 	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
-	. "github.com/stretchr/testify/mock"
+	"io"
 	apiv1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
 	"k8s.io/utils/ptr"
+	"net/http"
+	"net/http/httptest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -25,12 +29,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sync"
 	"time"
 
 	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
 	"github.com/ory/hydra-maester/controllers"
-	mocks "github.com/ory/hydra-maester/controllers/mocks/hydra"
+	"github.com/ory/hydra-maester/credentials"
 	"github.com/ory/hydra-maester/hydra"
+	"github.com/ory/hydra-maester/hydra/hydratest"
 )
 
 const (
@@ -47,7 +53,7 @@ var _ = Describe("OAuth2Client Controller", func() {
 
 			It("create a Secret if it does not exist", func() {
 
-				tstName, tstClientID, tstSecretName := "test", "testClientID", "my-secret-123"
+				tstName, tstSecretName := "test", "my-secret-123"
 				expectedRequest := &reconcile.Request{NamespacedName: types.NamespacedName{Name: tstName, Namespace: tstNamespace}}
 
 				s := runtime.NewScheme()
@@ -68,26 +74,10 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(err).NotTo(HaveOccurred())
 				c := mgr.GetClient()
 
-				mch := &mocks.Client{}
-				mch.On("GetOAuth2Client", Anything).Return(nil, false, nil)
-				mch.On("DeleteOAuth2Client", Anything).Return(nil)
-				mch.On("ListOAuth2Client", Anything).Return(nil, nil)
-				mch.On("PostOAuth2Client", AnythingOfType("*hydra.OAuth2ClientJSON")).Return(func(o *hydra.OAuth2ClientJSON) *hydra.OAuth2ClientJSON {
-					return &hydra.OAuth2ClientJSON{
-						ClientID:      &tstClientID,
-						Secret:        ptr.To(tstSecret),
-						GrantTypes:    o.GrantTypes,
-						ResponseTypes: o.ResponseTypes,
-						RedirectURIs:  o.RedirectURIs,
-						Scope:         o.Scope,
-						Audience:      o.Audience,
-						Owner:         o.Owner,
-					}
-				}, func(o *hydra.OAuth2ClientJSON) error {
-					return nil
-				})
+				fake, hc := newFakeHydra()
+				defer fake.Close()
 
-				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, mch))
+				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc))
 
 				Expect(add(mgr, recFn)).To(Succeed())
 
@@ -113,15 +103,28 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(retrieved.Status.ReconciliationError.Code).To(BeEmpty())
 				Expect(retrieved.Status.ReconciliationError.Description).To(BeEmpty())
 
-				//Verify the created Secret
+				//Verify the created Secret holds the generated credentials the client was registered with
 				var createdSecret apiv1.Secret
 				ok = client.ObjectKey{Name: tstSecretName, Namespace: tstNamespace}
 				err = k8sClient.Get(context.TODO(), ok, &createdSecret)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(createdSecret.Data[controllers.ClientIDKey]).To(Equal([]byte(tstClientID)))
-				Expect(createdSecret.Data[controllers.ClientSecretKey]).To(Equal([]byte(tstSecret)))
+				registered, found := fake.Client(string(createdSecret.Data[controllers.ClientIDKey]))
+				Expect(found).To(BeTrue())
+				Expect(registered.Owner).To(Equal(fmt.Sprintf("%s/%s", tstName, tstNamespace)))
+				Expect(registered.Secret).NotTo(BeNil())
+				Expect(createdSecret.Data[controllers.ClientSecretKey]).To(Equal([]byte(*registered.Secret)))
+				Expect(createdSecret.Data[controllers.ClientSecretKey]).To(HaveLen(credentials.DefaultSecretLength))
 				Expect(createdSecret.OwnerReferences).To(Equal(getOwnerReferenceTo(retrieved)))
 
+				//Verify the status describes the registered client
+				Expect(retrieved.Status.ClientID).To(Equal(*registered.ClientID))
+				Expect(retrieved.Status.Hydra).To(Equal("http://hydra-admin:4445"))
+				Expect(retrieved.Status.CreatedAt).NotTo(BeNil())
+				Expect(retrieved.Status.CreatedAt.UTC().Format(time.RFC3339)).To(Equal(registered.CreatedAt))
+				Expect(retrieved.Status.UpdatedAt).NotTo(BeNil())
+				Expect(retrieved.Status.SecretName).To(Equal(tstSecretName))
+				Expect(retrieved.Status.LastSyncTime).NotTo(BeNil())
+
 				//delete instance
 				c.Delete(context.TODO(), instance)
 
@@ -151,13 +154,11 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(err).NotTo(HaveOccurred())
 				c := mgr.GetClient()
 
-				mch := &mocks.Client{}
-				mch.On("GetOAuth2Client", Anything).Return(nil, false, nil)
-				mch.On("PostOAuth2Client", Anything).Return(nil, errors.New("error"))
-				mch.On("DeleteOAuth2Client", Anything).Return(nil)
-				mch.On("ListOAuth2Client", Anything).Return(nil, nil)
+				fake, hc := newFakeHydra()
+				defer fake.Close()
+				fake.Inject(hydratest.Fault{Method: http.MethodPost, StatusCode: http.StatusBadRequest})
 
-				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, mch))
+				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc))
 
 				Expect(add(mgr, recFn)).To(Succeed())
 
@@ -183,14 +184,15 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(retrieved.Status.ReconciliationError).NotTo(BeNil())
 
 				Expect(retrieved.Status.ReconciliationError.Code).To(Equal(hydrav1alpha1.StatusRegistrationFailed))
-				Expect(retrieved.Status.ReconciliationError.Description).To(Equal("error"))
+				Expect(retrieved.Status.ReconciliationError.Description).To(ContainSubstring("400 Bad Request"))
 
-				//Verify no secret has been created
+				//Verify the generated credentials have been persisted, so that the registration can be retried with them
 				var createdSecret apiv1.Secret
 				ok = client.ObjectKey{Name: tstSecretName, Namespace: tstNamespace}
 				err = k8sClient.Get(context.TODO(), ok, &createdSecret)
-				Expect(err).To(HaveOccurred())
-				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(createdSecret.Data[controllers.ClientIDKey]).NotTo(BeEmpty())
+				Expect(createdSecret.Data[controllers.ClientSecretKey]).NotTo(BeEmpty())
 
 				//delete instance
 				c.Delete(context.TODO(), instance)
@@ -202,7 +204,6 @@ var _ = Describe("OAuth2Client Controller", func() {
 			It("use provided Secret if it exists", func() {
 
 				tstName, tstClientID, tstSecretName := "test3", "testClientID-3", "my-secret-789"
-				var postedClient *hydra.OAuth2ClientJSON
 				expectedRequest := &reconcile.Request{NamespacedName: types.NamespacedName{Name: tstName, Namespace: tstNamespace}}
 
 				s := runtime.NewScheme()
@@ -221,28 +222,10 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(err).NotTo(HaveOccurred())
 				c := mgr.GetClient()
 
-				mch := mocks.Client{}
-				mch.On("GetOAuth2Client", Anything).Return(nil, false, nil)
-				mch.On("DeleteOAuth2Client", Anything).Return(nil)
-				mch.On("ListOAuth2Client", Anything).Return(nil, nil)
-				mch.On("GetOAuth2Client", Anything).Return(nil, false, nil)
-				mch.On("PostOAuth2Client", AnythingOfType("*hydra.OAuth2ClientJSON")).Return(func(o *hydra.OAuth2ClientJSON) *hydra.OAuth2ClientJSON {
-					postedClient = &hydra.OAuth2ClientJSON{
-						ClientID:      o.ClientID,
-						Secret:        o.Secret,
-						GrantTypes:    o.GrantTypes,
-						ResponseTypes: o.ResponseTypes,
-						RedirectURIs:  o.RedirectURIs,
-						Audience:      o.Audience,
-						Scope:         o.Scope,
-						Owner:         o.Owner,
-					}
-					return postedClient
-				}, func(o *hydra.OAuth2ClientJSON) error {
-					return nil
-				})
+				fake, hc := newFakeHydra()
+				defer fake.Close()
 
-				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, &mch))
+				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc))
 
 				Expect(add(mgr, recFn)).To(Succeed())
 
@@ -288,6 +271,16 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(err).To(BeNil())
 				Expect(len(secret.OwnerReferences)).To(Equal(0))
 
+				// Ensure that the status records the synced secret
+				Expect(retrieved.Status.ClientID).To(Equal(tstClientID))
+				Expect(retrieved.Status.SecretName).To(Equal(tstSecretName))
+				Expect(retrieved.Status.SecretResourceVersion).To(Equal(secret.ResourceVersion))
+
+				// Ensure that the client is registered with the credentials of the secret
+				registered, found := fake.Client(tstClientID)
+				Expect(found).To(BeTrue())
+				Expect(registered.Secret).To(Equal(ptr.To(tstSecret)))
+
 				//delete instance
 				c.Delete(context.TODO(), instance)
 
@@ -315,12 +308,10 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(err).NotTo(HaveOccurred())
 				c := mgr.GetClient()
 
-				mch := mocks.Client{}
-				mch.On("GetOAuth2Client", Anything).Return(nil, false, nil)
-				mch.On("DeleteOAuth2Client", Anything).Return(nil)
-				mch.On("ListOAuth2Client", Anything).Return(nil, nil)
+				fake, hc := newFakeHydra()
+				defer fake.Close()
 
-				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, &mch))
+				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc))
 
 				Expect(add(mgr, recFn)).To(Succeed())
 
@@ -360,6 +351,7 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(retrieved.Status.ReconciliationError).NotTo(BeNil())
 				Expect(retrieved.Status.ReconciliationError.Code).To(Equal(hydrav1alpha1.StatusInvalidSecret))
 				Expect(retrieved.Status.ReconciliationError.Description).To(Equal("CLIENT_SECRET property missing"))
+				Expect(fake.Clients()).To(BeEmpty())
 
 				//delete instance
 				c.Delete(context.TODO(), instance)
@@ -369,7 +361,7 @@ var _ = Describe("OAuth2Client Controller", func() {
 			})
 
 			It("tolerate nil client_secret if tokenEndpointAuthMethod is none", func() {
-				tstName, tstClientID, tstSecretName := "test5", "testClientID-5", "my-secret-without-client-secret"
+				tstName, tstSecretName := "test5", "my-secret-without-client-secret"
 				expectedRequest := &reconcile.Request{NamespacedName: types.NamespacedName{Name: tstName, Namespace: tstNamespace}}
 
 				s := runtime.NewScheme()
@@ -387,26 +379,10 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(err).NotTo(HaveOccurred())
 				c := mgr.GetClient()
 
-				mch := &mocks.Client{}
-				mch.On("GetOAuth2Client", Anything).Return(nil, false, nil)
-				mch.On("DeleteOAuth2Client", Anything).Return(nil)
-				mch.On("ListOAuth2Client", Anything).Return(nil, nil)
-				mch.On("PostOAuth2Client", AnythingOfType("*hydra.OAuth2ClientJSON")).Return(func(o *hydra.OAuth2ClientJSON) *hydra.OAuth2ClientJSON {
-					return &hydra.OAuth2ClientJSON{
-						ClientID:      &tstClientID,
-						Secret:        nil,
-						GrantTypes:    o.GrantTypes,
-						ResponseTypes: o.ResponseTypes,
-						RedirectURIs:  o.RedirectURIs,
-						Scope:         o.Scope,
-						Audience:      o.Audience,
-						Owner:         o.Owner,
-					}
-				}, func(o *hydra.OAuth2ClientJSON) error {
-					return nil
-				})
+				fake, hc := newFakeHydra()
+				defer fake.Close()
 
-				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, mch))
+				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc))
 
 				Expect(add(mgr, recFn)).To(Succeed())
 
@@ -438,7 +414,9 @@ var _ = Describe("OAuth2Client Controller", func() {
 				ok = client.ObjectKey{Name: tstSecretName, Namespace: tstNamespace}
 				err = k8sClient.Get(context.TODO(), ok, &createdSecret)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(createdSecret.Data[controllers.ClientIDKey]).To(Equal([]byte(tstClientID)))
+				registered, found := fake.Client(string(createdSecret.Data[controllers.ClientIDKey]))
+				Expect(found).To(BeTrue())
+				Expect(registered.Secret).To(BeNil())
 				Expect(createdSecret.Data[controllers.ClientSecretKey]).To(BeNil())
 				Expect(createdSecret.OwnerReferences).To(Equal(getOwnerReferenceTo(retrieved)))
 
@@ -450,7 +428,7 @@ var _ = Describe("OAuth2Client Controller", func() {
 			})
 
 			It("not delete OAuth2 clients with Orphan deletion policy", func() {
-				tstName, tstClientID, tstSecretName := "test-orphan", "testClientID-orphan", "my-secret-orphan"
+				tstName, tstSecretName := "test-orphan", "my-secret-orphan"
 				expectedRequest := &reconcile.Request{NamespacedName: types.NamespacedName{Name: tstName, Namespace: tstNamespace}}
 
 				s := runtime.NewScheme()
@@ -471,38 +449,10 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(err).NotTo(HaveOccurred())
 				c := mgr.GetClient()
 
-				deleteHasHappened := false
-				mch := &mocks.Client{}
-				mch.On("GetOAuth2Client", Anything).Return(nil, false, nil)
-				mch.On("DeleteOAuth2Client", Anything).Return(func(id string) error {
-					deleteHasHappened = true
-					return nil
-				})
-				mch.On("ListOAuth2Client", Anything).Return(func() []*hydra.OAuth2ClientJSON {
-					return []*hydra.OAuth2ClientJSON{
-						{
-							ClientID: &tstClientID,
-							Secret:   ptr.To(tstSecret),
-							Owner:    fmt.Sprintf("%s/%s", tstName, tstNamespace),
-						},
-					}
-				}, nil)
-				mch.On("PostOAuth2Client", AnythingOfType("*hydra.OAuth2ClientJSON")).Return(func(o *hydra.OAuth2ClientJSON) *hydra.OAuth2ClientJSON {
-					return &hydra.OAuth2ClientJSON{
-						ClientID:      &tstClientID,
-						Secret:        ptr.To(tstSecret),
-						GrantTypes:    o.GrantTypes,
-						ResponseTypes: o.ResponseTypes,
-						RedirectURIs:  o.RedirectURIs,
-						Scope:         o.Scope,
-						Audience:      o.Audience,
-						Owner:         o.Owner,
-					}
-				}, func(o *hydra.OAuth2ClientJSON) error {
-					return nil
-				})
+				fake, hc := newFakeHydra()
+				defer fake.Close()
 
-				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, mch))
+				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc))
 				Expect(add(mgr, recFn)).To(Succeed())
 
 				//Start the manager and the controller
@@ -530,14 +480,15 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Eventually(requests, timeout).Should(Receive(Equal(*expectedRequest)))
 
-				Expect(deleteHasHappened).To(BeFalse())
+				Expect(fake.Clients()).To(HaveLen(1))
+				Expect(fake.Clients()[0].Owner).To(Equal(fmt.Sprintf("%s/%s", tstName, tstNamespace)))
 
 				//Ensure manager is stopped properly
 				stopMgr.Done()
 			})
 
 			It("delete OAuth2 clients with Delete deletion policy", func() {
-				tstName, tstClientID, tstSecretName := "test-delete", "testClientID-delete", "my-secret-delete"
+				tstName, tstSecretName := "test-delete", "my-secret-delete"
 				expectedRequest := &reconcile.Request{NamespacedName: types.NamespacedName{Name: tstName, Namespace: tstNamespace}}
 
 				s := runtime.NewScheme()
@@ -558,38 +509,10 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(err).NotTo(HaveOccurred())
 				c := mgr.GetClient()
 
-				deleteHasHappened := false
-				mch := &mocks.Client{}
-				mch.On("GetOAuth2Client", Anything).Return(nil, false, nil)
-				mch.On("DeleteOAuth2Client", AnythingOfType("string")).Return(func(id string) error {
-					deleteHasHappened = true
-					return nil
-				})
-				mch.On("ListOAuth2Client", Anything).Return(func() []*hydra.OAuth2ClientJSON {
-					return []*hydra.OAuth2ClientJSON{
-						{
-							ClientID: &tstClientID,
-							Secret:   ptr.To(tstSecret),
-							Owner:    fmt.Sprintf("%s/%s", tstName, tstNamespace),
-						},
-					}
-				}, nil)
-				mch.On("PostOAuth2Client", AnythingOfType("*hydra.OAuth2ClientJSON")).Return(func(o *hydra.OAuth2ClientJSON) *hydra.OAuth2ClientJSON {
-					return &hydra.OAuth2ClientJSON{
-						ClientID:      &tstClientID,
-						Secret:        ptr.To(tstSecret),
-						GrantTypes:    o.GrantTypes,
-						ResponseTypes: o.ResponseTypes,
-						RedirectURIs:  o.RedirectURIs,
-						Scope:         o.Scope,
-						Audience:      o.Audience,
-						Owner:         o.Owner,
-					}
-				}, func(o *hydra.OAuth2ClientJSON) error {
-					return nil
-				})
+				fake, hc := newFakeHydra()
+				defer fake.Close()
 
-				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, mch))
+				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc))
 				Expect(add(mgr, recFn)).To(Succeed())
 
 				//Start the manager and the controller
@@ -607,6 +530,7 @@ var _ = Describe("OAuth2Client Controller", func() {
 				}
 				Expect(err).NotTo(HaveOccurred())
 				Eventually(requests, timeout).Should(Receive(Equal(*expectedRequest)))
+				Expect(fake.Clients()).To(HaveLen(1))
 
 				// Call deletion API, which should really delete the CRD because we are in orphan mode.
 				err = c.Delete(context.TODO(), instance)
@@ -617,7 +541,9 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Eventually(requests, timeout).Should(Receive(Equal(*expectedRequest)))
 
-				Expect(deleteHasHappened).To(BeTrue())
+				// the previous client is kept for the grace period of its
+				// replacement, and deleted with the resource
+				Eventually(fake.Clients, timeout).Should(BeEmpty())
 
 				// Ensure manager is stopped properly.
 				stopMgr.Done()
@@ -627,7 +553,7 @@ var _ = Describe("OAuth2Client Controller", func() {
 				// Regression test: when Spec.Scope is empty but Spec.ScopeArray is
 				// populated, the controller must still unregister the client from
 				// Hydra on deletion (previously the guard short-circuited).
-				tstName, tstClientID, tstSecretName := "test-delete-scopearray", "testClientID-delete-scopearray", "my-secret-delete-scopearray"
+				tstName, tstSecretName := "test-delete-scopearray", "my-secret-delete-scopearray"
 				expectedRequest := &reconcile.Request{NamespacedName: types.NamespacedName{Name: tstName, Namespace: tstNamespace}}
 
 				s := runtime.NewScheme()
@@ -646,38 +572,10 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(err).NotTo(HaveOccurred())
 				c := mgr.GetClient()
 
-				deleteHasHappened := false
-				mch := &mocks.Client{}
-				mch.On("GetOAuth2Client", Anything).Return(nil, false, nil)
-				mch.On("DeleteOAuth2Client", AnythingOfType("string")).Return(func(id string) error {
-					deleteHasHappened = true
-					return nil
-				})
-				mch.On("ListOAuth2Client", Anything).Return(func() []*hydra.OAuth2ClientJSON {
-					return []*hydra.OAuth2ClientJSON{
-						{
-							ClientID: &tstClientID,
-							Secret:   ptr.To(tstSecret),
-							Owner:    fmt.Sprintf("%s/%s", tstName, tstNamespace),
-						},
-					}
-				}, nil)
-				mch.On("PostOAuth2Client", AnythingOfType("*hydra.OAuth2ClientJSON")).Return(func(o *hydra.OAuth2ClientJSON) *hydra.OAuth2ClientJSON {
-					return &hydra.OAuth2ClientJSON{
-						ClientID:      &tstClientID,
-						Secret:        ptr.To(tstSecret),
-						GrantTypes:    o.GrantTypes,
-						ResponseTypes: o.ResponseTypes,
-						RedirectURIs:  o.RedirectURIs,
-						Scope:         o.Scope,
-						Audience:      o.Audience,
-						Owner:         o.Owner,
-					}
-				}, func(o *hydra.OAuth2ClientJSON) error {
-					return nil
-				})
+				fake, hc := newFakeHydra()
+				defer fake.Close()
 
-				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, mch))
+				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc))
 				Expect(add(mgr, recFn)).To(Succeed())
 
 				stopMgr := StartTestManager(mgr)
@@ -696,6 +594,7 @@ var _ = Describe("OAuth2Client Controller", func() {
 				}
 				Expect(err).NotTo(HaveOccurred())
 				Eventually(requests, timeout).Should(Receive(Equal(*expectedRequest)))
+				Expect(fake.Clients()).To(HaveLen(1))
 
 				err = c.Delete(context.TODO(), instance)
 				if apierrors.IsInvalid(err) {
@@ -705,7 +604,9 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Eventually(requests, timeout).Should(Receive(Equal(*expectedRequest)))
 
-				Expect(deleteHasHappened).To(BeTrue())
+				// the previous client is kept for the grace period of its
+				// replacement, and deleted with the resource
+				Eventually(fake.Clients, timeout).Should(BeEmpty())
 
 				stopMgr.Done()
 			})
@@ -732,28 +633,10 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(err).NotTo(HaveOccurred())
 				c := mgr.GetClient()
 
-				var createdClient *hydra.OAuth2ClientJSON
-				mch := mocks.Client{}
-				mch.On("GetOAuth2Client", Anything).Return(nil, false, nil)
-				mch.On("ListOAuth2Client", Anything).Return(nil, nil)
-				mch.On("DeleteOAuth2Client", Anything).Return(nil)
-				mch.On("PostOAuth2Client", AnythingOfType("*hydra.OAuth2ClientJSON")).Return(func(o *hydra.OAuth2ClientJSON) *hydra.OAuth2ClientJSON {
-					createdClient = &hydra.OAuth2ClientJSON{
-						ClientID:      o.ClientID,
-						Secret:        o.Secret,
-						GrantTypes:    o.GrantTypes,
-						ResponseTypes: o.ResponseTypes,
-						RedirectURIs:  o.RedirectURIs,
-						Audience:      o.Audience,
-						Scope:         o.Scope,
-						Owner:         o.Owner,
-					}
-					return createdClient
-				}, func(o *hydra.OAuth2ClientJSON) error {
-					return nil
-				})
+				fake, hc := newFakeHydra()
+				defer fake.Close()
 
-				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, &mch))
+				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc))
 				Expect(add(mgr, recFn)).To(Succeed())
 
 				// Start the manager and the controller
@@ -787,9 +670,9 @@ var _ = Describe("OAuth2Client Controller", func() {
 				Expect(retrieved.Status.ReconciliationError.Description).To(BeEmpty())
 
 				// Ensure the created client has the expected client ID and client secret
-				Expect(createdClient.ClientID).ShouldNot(BeNil())
+				createdClient, found := fake.Client(tstClientID)
+				Expect(found).To(BeTrue())
 				Expect(createdClient.Secret).ShouldNot(BeNil())
-				Expect(*createdClient.ClientID).To(Equal(tstClientID))
 				Expect(*createdClient.Secret).To(Equal(tstSecret))
 
 				// Delete instance
@@ -798,6 +681,1035 @@ var _ = Describe("OAuth2Client Controller", func() {
 				// Ensure manager is stopped properly
 				stopMgr.Done()
 			})
+
+			It("register the declared client ID and generate only the secret", func() {
+				tstName, tstSecretName := "test-declared-client-id", "my-secret-declared-client-id"
+				expectedRequest := &reconcile.Request{NamespacedName: types.NamespacedName{Name: tstName, Namespace: tstNamespace}}
+
+				s := runtime.NewScheme()
+				err := hydrav1alpha1.AddToScheme(s)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = apiv1.AddToScheme(s)
+				Expect(err).NotTo(HaveOccurred())
+
+				mgr, err := manager.New(cfg, manager.Options{
+					Scheme: s,
+					Metrics: server.Options{
+						BindAddress: ":8090",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				c := mgr.GetClient()
+
+				fake, hc := newFakeHydra()
+				defer fake.Close()
+
+				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc))
+				Expect(add(mgr, recFn)).To(Succeed())
+
+				stopMgr := StartTestManager(mgr)
+
+				instance := testInstance(tstName, tstSecretName)
+				instance.Spec.ClientID = "{{ .Namespace }}-{{ .Name }}"
+				err = c.Create(context.TODO(), instance)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(requests, timeout).Should(Receive(Equal(*expectedRequest)))
+
+				postedClient, found := fake.Client(tstNamespace + "-" + tstName)
+				Expect(found).To(BeTrue())
+				Expect(postedClient.Secret).NotTo(BeNil())
+
+				var createdSecret apiv1.Secret
+				err = k8sClient.Get(context.TODO(), client.ObjectKey{Name: tstSecretName, Namespace: tstNamespace}, &createdSecret)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(createdSecret.Data[controllers.ClientIDKey]).To(Equal([]byte(tstNamespace + "-" + tstName)))
+				Expect(createdSecret.Data[controllers.ClientSecretKey]).To(Equal([]byte(*postedClient.Secret)))
+
+				c.Delete(context.TODO(), instance)
+				stopMgr.Done()
+			})
+
+			It("store created credentials in the selected credential store", func() {
+				tstName, tstSecretName := "test-file-credential-store", "my-secret-in-file-store"
+				expectedRequest := &reconcile.Request{NamespacedName: types.NamespacedName{Name: tstName, Namespace: tstNamespace}}
+
+				s := runtime.NewScheme()
+				err := hydrav1alpha1.AddToScheme(s)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = apiv1.AddToScheme(s)
+				Expect(err).NotTo(HaveOccurred())
+
+				mgr, err := manager.New(cfg, manager.Options{
+					Scheme: s,
+					Metrics: server.Options{
+						BindAddress: ":8089",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				c := mgr.GetClient()
+
+				fake, hc := newFakeHydra()
+				defer fake.Close()
+
+				store := credentials.NewFileStore(GinkgoT().TempDir())
+				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc,
+					controllers.WithCredentialStore(hydrav1alpha1.CredentialStoreFile, store)))
+				Expect(add(mgr, recFn)).To(Succeed())
+
+				stopMgr := StartTestManager(mgr)
+
+				instance := testInstance(tstName, tstSecretName)
+				instance.Spec.CredentialStore = hydrav1alpha1.CredentialStoreFile
+				err = c.Create(context.TODO(), instance)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(requests, timeout).Should(Receive(Equal(*expectedRequest)))
+
+				// Verify the credentials ended up in the file store and no Secret was created
+				creds, found, err := store.Get(context.TODO(), instance)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(BeTrue())
+				postedClient, found := fake.Client(string(creds.ID))
+				Expect(found).To(BeTrue())
+				Expect(creds.Password).To(Equal([]byte(*postedClient.Secret)))
+
+				var createdSecret apiv1.Secret
+				err = k8sClient.Get(context.TODO(), client.ObjectKey{Name: tstSecretName, Namespace: tstNamespace}, &createdSecret)
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+				c.Delete(context.TODO(), instance)
+				stopMgr.Done()
+			})
+
+			It("only reconcile clients of its controller class", func() {
+				tstName, tstSecretName := "test-controller-class", "my-secret-controller-class"
+				otherName, otherSecretName := "test-other-controller-class", "my-secret-other-controller-class"
+				expectedRequest := &reconcile.Request{NamespacedName: types.NamespacedName{Name: tstName, Namespace: tstNamespace}}
+				otherRequest := &reconcile.Request{NamespacedName: types.NamespacedName{Name: otherName, Namespace: tstNamespace}}
+
+				s := runtime.NewScheme()
+				err := hydrav1alpha1.AddToScheme(s)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = apiv1.AddToScheme(s)
+				Expect(err).NotTo(HaveOccurred())
+
+				mgr, err := manager.New(cfg, manager.Options{
+					Scheme: s,
+					Metrics: server.Options{
+						BindAddress: ":8091",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				c := mgr.GetClient()
+
+				fake, hc := newFakeHydra()
+				defer fake.Close()
+
+				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc,
+					controllers.WithControllerClass("shard-a"),
+					controllers.WithControllerInstance("test-instance")))
+				Expect(add(mgr, recFn)).To(Succeed())
+
+				stopMgr := StartTestManager(mgr)
+
+				other := testInstance(otherName, otherSecretName)
+				other.Spec.ControllerClass = "shard-b"
+				err = c.Create(context.TODO(), other)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(requests, timeout).Should(Receive(Equal(*otherRequest)))
+
+				instance := testInstance(tstName, tstSecretName)
+				instance.Spec.ControllerClass = "shard-a"
+				err = c.Create(context.TODO(), instance)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(requests, timeout).Should(Receive(Equal(*expectedRequest)))
+
+				// Verify the client of another class was left untouched
+				var retrieved hydrav1alpha1.OAuth2Client
+				err = c.Get(context.TODO(), otherRequest.NamespacedName, &retrieved)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(retrieved.Finalizers).To(BeEmpty())
+				Expect(retrieved.Status.Controller).To(BeEmpty())
+
+				var otherSecret apiv1.Secret
+				err = k8sClient.Get(context.TODO(), client.ObjectKey{Name: otherSecretName, Namespace: tstNamespace}, &otherSecret)
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				for _, registered := range fake.Clients() {
+					Expect(registered.Owner).NotTo(Equal(fmt.Sprintf("%s/%s", otherName, tstNamespace)))
+				}
+
+				// Verify the status records the instance that handled the client
+				err = c.Get(context.TODO(), expectedRequest.NamespacedName, &retrieved)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(retrieved.Status.Controller).To(Equal("shard-a/test-instance"))
+				Expect(retrieved.Status.HydraVersion).To(Equal("v2.2.0"))
+				Expect(retrieved.Status.ReconciliationError).To(BeZero())
+
+				c.Delete(context.TODO(), other)
+				c.Delete(context.TODO(), instance)
+				stopMgr.Done()
+			})
+
+			It("update object status if ORY Hydra does not support a field", func() {
+				tstName, tstSecretName := "test-unsupported-field", "my-secret-unsupported-field"
+				expectedRequest := &reconcile.Request{NamespacedName: types.NamespacedName{Name: tstName, Namespace: tstNamespace}}
+
+				s := runtime.NewScheme()
+				err := hydrav1alpha1.AddToScheme(s)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = apiv1.AddToScheme(s)
+				Expect(err).NotTo(HaveOccurred())
+
+				mgr, err := manager.New(cfg, manager.Options{
+					Scheme: s,
+					Metrics: server.Options{
+						BindAddress: ":8092",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				c := mgr.GetClient()
+
+				fake, hc := newFakeHydra(hydratest.WithVersion("v2.1.2"))
+				defer fake.Close()
+
+				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc))
+				Expect(add(mgr, recFn)).To(Succeed())
+
+				stopMgr := StartTestManager(mgr)
+
+				instance := testInstance(tstName, tstSecretName)
+				instance.Spec.SkipConsent = true
+				err = c.Create(context.TODO(), instance)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(requests, timeout).Should(Receive(Equal(*expectedRequest)))
+
+				var retrieved hydrav1alpha1.OAuth2Client
+				err = c.Get(context.TODO(), expectedRequest.NamespacedName, &retrieved)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(retrieved.Status.ReconciliationError.Code).To(Equal(hydrav1alpha1.StatusUnsupportedField))
+				Expect(retrieved.Status.ReconciliationError.Description).To(Equal("fields skip_consent are not supported by ORY Hydra v2.1.2"))
+				Expect(retrieved.Status.HydraVersion).To(Equal("v2.1.2"))
+				Expect(fake.Clients()).To(BeEmpty())
+
+				c.Delete(context.TODO(), instance)
+				stopMgr.Done()
+			})
+
+			It("update only the token lifespans if nothing else changed", func() {
+				tstName, tstSecretName := "test-lifespans", "my-secret-lifespans"
+				key := types.NamespacedName{Name: tstName, Namespace: tstNamespace}
+
+				s := runtime.NewScheme()
+				err := hydrav1alpha1.AddToScheme(s)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = apiv1.AddToScheme(s)
+				Expect(err).NotTo(HaveOccurred())
+
+				mgr, err := manager.New(cfg, manager.Options{
+					Scheme: s,
+					Metrics: server.Options{
+						BindAddress: ":8093",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				c := mgr.GetClient()
+
+				fake, hc := newFakeHydra()
+				defer fake.Close()
+
+				recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc,
+					controllers.WithLifespanBounds(hydra.LifespanBounds{Max: 24 * time.Hour})))
+				Expect(add(mgr, recFn)).To(Succeed())
+
+				// the spec waits for the outcome of several reconciliations
+				go func() {
+					for range requests {
+					}
+				}()
+
+				stopMgr := StartTestManager(mgr)
+
+				instance := testInstance(tstName, tstSecretName)
+				instance.Spec.TokenLifespans.RefreshTokenGrantRefreshTokenLifespan = "1h30m"
+				err = c.Create(context.TODO(), instance)
+				Expect(err).NotTo(HaveOccurred())
+
+				var retrieved hydrav1alpha1.OAuth2Client
+				Eventually(func() string {
+					Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+					return retrieved.Status.SpecHash
+				}, timeout).ShouldNot(BeEmpty())
+				Expect(retrieved.Status.ReconciliationError).To(BeZero())
+
+				// the lifespans are set through their own endpoint
+				clientID := retrieved.Status.ClientID
+				registered, found := fake.Client(clientID)
+				Expect(found).To(BeTrue())
+				Expect(registered.RefreshTokenGrantRefreshTokenLifespan).To(Equal("1h30m"))
+				Expect(fake.Requests()).To(ContainElement("PUT /admin/clients/" + clientID + "/lifespans"))
+				puts := func() int {
+					return countRequests(fake, "PUT /admin/clients/"+clientID)
+				}
+				putsBefore := puts()
+
+				// Change only the lifespans
+				retrieved.Spec.TokenLifespans.RefreshTokenGrantRefreshTokenLifespan = "2h"
+				Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+
+				Eventually(func() string {
+					registered, _ := fake.Client(clientID)
+					return registered.RefreshTokenGrantRefreshTokenLifespan
+				}, timeout).Should(Equal("2h"))
+				Expect(puts()).To(Equal(putsBefore))
+
+				// Exceed the maximum lifespan
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				retrieved.Spec.TokenLifespans.RefreshTokenGrantRefreshTokenLifespan = "48h"
+				Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+
+				Eventually(func() hydrav1alpha1.StatusCode {
+					Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+					return retrieved.Status.ReconciliationError.Code
+				}, timeout).Should(Equal(hydrav1alpha1.StatusInvalidTokenLifespans))
+				Expect(retrieved.Status.ReconciliationError.Description).To(Equal("refresh_token_grant_refresh_token_lifespan: 48h exceeds the maximum of 24h0m0s"))
+
+				c.Delete(context.TODO(), instance)
+				stopMgr.Done()
+			})
+		})
+	})
+
+	Context("with a JSON Web Key Set taken from a ConfigMap", func() {
+
+		It("send it inline and update the client when it changes", func() {
+			tstName, tstSecretName, tstConfigMapName := "test-jwks-from", "my-secret-jwks-from", "my-jwks"
+			key := types.NamespacedName{Name: tstName, Namespace: tstNamespace}
+
+			s := runtime.NewScheme()
+			Expect(hydrav1alpha1.AddToScheme(s)).To(Succeed())
+			Expect(apiv1.AddToScheme(s)).To(Succeed())
+
+			mgr, err := manager.New(cfg, manager.Options{
+				Scheme: s,
+				Metrics: server.Options{
+					BindAddress: ":8096",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			c := mgr.GetClient()
+
+			fake, hc := newFakeHydra()
+			defer fake.Close()
+
+			// the key IDs of the key set registered for the client
+			sent := func() []string {
+				registered, found := fake.Client(tstNamespace + "-" + tstName)
+				if !found {
+					return nil
+				}
+				Expect(registered.JwksUri).To(BeEmpty())
+				Expect(registered.Jwks).NotTo(BeNil())
+				var kids []string
+				for _, k := range registered.Jwks.Keys {
+					kids = append(kids, k.KeyID())
+				}
+				return kids
+			}
+
+			recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc))
+			Expect(add(mgr, recFn)).To(Succeed())
+			go func() {
+				for range requests {
+				}
+			}()
+
+			stopMgr := StartTestManager(mgr)
+
+			configMap := &apiv1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: tstConfigMapName, Namespace: tstNamespace},
+				Data:       map[string]string{"jwks.json": `{"keys":[{"kty":"RSA","kid":"key-1","n":"modulus","e":"AQAB"}]}`},
+			}
+			Expect(c.Create(context.TODO(), configMap)).To(Succeed())
+
+			instance := testInstance(tstName, tstSecretName)
+			instance.Spec.ClientID = "{{ .Namespace }}-{{ .Name }}"
+			instance.Spec.TokenEndpointAuthMethod = "private_key_jwt"
+			instance.Spec.JwksFrom = &hydrav1alpha1.JwksSource{
+				ConfigMapKeyRef: &apiv1.ConfigMapKeySelector{
+					LocalObjectReference: apiv1.LocalObjectReference{Name: tstConfigMapName},
+					Key:                  "jwks.json",
+				},
+			}
+			Expect(c.Create(context.TODO(), instance)).To(Succeed())
+
+			var retrieved hydrav1alpha1.OAuth2Client
+			Eventually(func() string {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.JwksHash
+			}, timeout).ShouldNot(BeEmpty())
+			Expect(retrieved.Status.ReconciliationError).To(BeZero())
+			Expect(sent()).To(Equal([]string{"key-1"}))
+
+			// Change the keys, then trigger a reconciliation without changing
+			// the spec, as the ConfigMap is only watched by SetupWithManager
+			configMap.Data["jwks.json"] = `{"keys":[{"kty":"RSA","kid":"key-2","n":"modulus","e":"AQAB","d":"private"}]}`
+			Expect(c.Update(context.TODO(), configMap)).To(Succeed())
+			jwksHash := retrieved.Status.JwksHash
+			retrieved.Annotations = map[string]string{"test": "touch"}
+			Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+
+			Eventually(func() string {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.JwksHash
+			}, timeout).ShouldNot(Equal(jwksHash))
+
+			Eventually(sent, timeout).Should(Equal([]string{"key-2"}))
+			// Verify only the public key is sent
+			registered, _ := fake.Client(tstNamespace + "-" + tstName)
+			Expect(registered.Jwks.Keys[0]).NotTo(HaveKey("d"))
+
+			// A symmetric key is reported as an invalid JSON Web Key Set
+			configMap.Data["jwks.json"] = `{"keys":[{"kty":"oct","kid":"key-3","k":"secret"}]}`
+			Expect(c.Update(context.TODO(), configMap)).To(Succeed())
+			retrieved.Annotations = map[string]string{"test": "touch-again"}
+			Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+
+			Eventually(func() hydrav1alpha1.StatusCode {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.ReconciliationError.Code
+			}, timeout).Should(Equal(hydrav1alpha1.StatusInvalidJWK))
+			Expect(retrieved.Status.ReconciliationError.Description).To(ContainSubstring(`key "key-3" is symmetric`))
+
+			c.Delete(context.TODO(), instance)
+			c.Delete(context.TODO(), configMap)
+			stopMgr.Done()
+		})
+	})
+
+	Context("with a generated key pair", func() {
+
+		It("store the private key in the secret, send the public keys inline and rotate them", func() {
+			tstName, tstSecretName := "test-key-pair", "my-secret-key-pair"
+			key := types.NamespacedName{Name: tstName, Namespace: tstNamespace}
+
+			s := runtime.NewScheme()
+			Expect(hydrav1alpha1.AddToScheme(s)).To(Succeed())
+			Expect(apiv1.AddToScheme(s)).To(Succeed())
+
+			mgr, err := manager.New(cfg, manager.Options{
+				Scheme: s,
+				Metrics: server.Options{
+					BindAddress: ":8097",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			c := mgr.GetClient()
+
+			fake, hc := newFakeHydra()
+			defer fake.Close()
+
+			// the key IDs of the public keys registered for the client
+			sent := func() []string {
+				registered, found := fake.Client(tstNamespace + "-" + tstName)
+				if !found {
+					return nil
+				}
+				Expect(registered.Secret).To(BeNil())
+				Expect(registered.Jwks).NotTo(BeNil())
+				var kids []string
+				for _, k := range registered.Jwks.Keys {
+					Expect(k).NotTo(HaveKey("d"))
+					kids = append(kids, k.KeyID())
+				}
+				return kids
+			}
+
+			recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc, controllers.WithRetryBackoff(time.Second, time.Second)))
+			Expect(add(mgr, recFn)).To(Succeed())
+			go func() {
+				for range requests {
+				}
+			}()
+
+			stopMgr := StartTestManager(mgr)
+
+			instance := testInstance(tstName, tstSecretName)
+			instance.Spec.ClientID = "{{ .Namespace }}-{{ .Name }}"
+			instance.Spec.TokenEndpointAuthMethod = "private_key_jwt"
+			instance.Spec.KeyPairGeneration = &hydrav1alpha1.KeyPairGeneration{
+				Algorithm:      "ES256",
+				RotationPeriod: metav1.Duration{Duration: 5 * time.Second},
+				OverlapPeriod:  metav1.Duration{Duration: time.Hour},
+			}
+			Expect(c.Create(context.TODO(), instance)).To(Succeed())
+
+			var retrieved hydrav1alpha1.OAuth2Client
+			Eventually(func() *hydrav1alpha1.KeyPairStatus {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.KeyPair
+			}, timeout).ShouldNot(BeNil())
+			Expect(retrieved.Status.ReconciliationError).To(BeZero())
+			kid := retrieved.Status.KeyPair.KeyID
+
+			// Verify the secret holds the private key but no client secret
+			var secret apiv1.Secret
+			Expect(k8sClient.Get(context.TODO(), types.NamespacedName{Name: tstSecretName, Namespace: tstNamespace}, &secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKey(controllers.ClientIDKey))
+			Expect(secret.Data).NotTo(HaveKey(controllers.ClientSecretKey))
+			Expect(string(secret.Data[controllers.ClientKeyIDKey])).To(Equal(kid))
+			Expect(string(secret.Data[controllers.ClientPrivateKeyKey])).To(ContainSubstring("PRIVATE KEY"))
+			Expect(secret.Annotations).To(HaveKey(controllers.KeyRotatedAtAnnotation))
+
+			// The key pair is rotated on schedule, keeping the previous key
+			Eventually(func() string {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.KeyPair.KeyID
+			}, 3*timeout).ShouldNot(Equal(kid))
+			Expect(retrieved.Status.KeyPair.PreviousKeyID).To(Equal(kid))
+			Expect(retrieved.Status.KeyPair.PreviousKeyExpiresAt).NotTo(BeNil())
+
+			Eventually(sent, timeout).Should(Equal([]string{retrieved.Status.KeyPair.KeyID, kid}))
+
+			// The Secret keeps its key pair while the new public key cannot be
+			// registered
+			rotated := retrieved.Status.KeyPair.KeyID
+			storedKeyID := func() string {
+				Expect(k8sClient.Get(context.TODO(), types.NamespacedName{Name: tstSecretName, Namespace: tstNamespace}, &secret)).To(Succeed())
+				return string(secret.Data[controllers.ClientKeyIDKey])
+			}
+			fake.Inject(hydratest.Fault{Method: http.MethodPut, StatusCode: http.StatusServiceUnavailable})
+			Consistently(storedKeyID, 8*time.Second, time.Second).Should(Equal(rotated))
+			Expect(sent()).To(ContainElement(rotated))
+
+			fake.ClearFaults()
+			Eventually(storedKeyID, 3*timeout).ShouldNot(Equal(rotated))
+			Expect(sent()).To(Equal([]string{storedKeyID(), rotated}))
+
+			c.Delete(context.TODO(), instance)
+			stopMgr.Done()
+		})
+	})
+
+	Context("with a revocation policy", func() {
+
+		It("revoke the tokens when the client is re-keyed or deleted", func() {
+			tstName, tstSecretName := "test-revocation", "my-secret-revocation"
+			key := types.NamespacedName{Name: tstName, Namespace: tstNamespace}
+
+			s := runtime.NewScheme()
+			Expect(hydrav1alpha1.AddToScheme(s)).To(Succeed())
+			Expect(apiv1.AddToScheme(s)).To(Succeed())
+
+			mgr, err := manager.New(cfg, manager.Options{
+				Scheme: s,
+				Metrics: server.Options{
+					BindAddress: ":8098",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			c := mgr.GetClient()
+
+			fake, hc := newFakeHydra()
+			defer fake.Close()
+
+			recorder := events.NewFakeRecorder(10)
+			recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc, controllers.WithEventRecorder(recorder), controllers.WithReplacementGracePeriod(0)))
+			Expect(add(mgr, recFn)).To(Succeed())
+			go func() {
+				for range requests {
+				}
+			}()
+
+			stopMgr := StartTestManager(mgr)
+
+			instance := testInstance(tstName, tstSecretName)
+			instance.Spec.ClientID = "first-id"
+			instance.Spec.RevocationPolicy = &hydrav1alpha1.RevocationPolicy{}
+			Expect(c.Create(context.TODO(), instance)).To(Succeed())
+
+			var retrieved hydrav1alpha1.OAuth2Client
+			Eventually(func() int64 {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.ObservedGeneration
+			}, timeout).Should(Equal(retrieved.Generation))
+			Expect(retrieved.Spec.RevocationPolicy.On).To(ConsistOf(hydrav1alpha1.RevocationOnDelete, hydrav1alpha1.RevocationOnRekey))
+			Expect(retrieved.Status.Revocation).To(BeNil())
+
+			// Re-key the client by changing its ID
+			retrieved.Spec.ClientID = "second-id"
+			Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+
+			Eventually(func() *hydrav1alpha1.RevocationStatus {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.Revocation
+			}, timeout).ShouldNot(BeNil())
+			Expect(retrieved.Status.Revocation.ClientID).To(Equal("first-id"))
+			Expect(retrieved.Status.Revocation.Trigger).To(Equal(hydrav1alpha1.RevocationOnRekey))
+			Expect(retrieved.Spec.RevocationPolicy.Revoke).To(Equal([]hydrav1alpha1.RevocationTarget{hydrav1alpha1.RevokeTokens}))
+			Expect(retrieved.Status.Revocation.Revoked).To(Equal([]hydrav1alpha1.RevocationTarget{hydrav1alpha1.RevokeTokens}))
+			Expect(retrieved.Status.Revocation.Error).To(BeEmpty())
+			Eventually(recorder.Events, timeout).Should(Receive(ContainSubstring("Normal Revoked")))
+
+			// Delete the client
+			Expect(c.Delete(context.TODO(), &retrieved)).To(Succeed())
+			Eventually(fake.Revocations, timeout).Should(Equal([]hydratest.Revocation{
+				{Target: hydrav1alpha1.RevokeTokens, ClientID: "first-id"},
+				{Target: hydrav1alpha1.RevokeTokens, ClientID: "second-id"},
+			}))
+			Expect(fake.Clients()).To(BeEmpty())
+
+			stopMgr.Done()
+		})
+
+		It("push a rotated client secret and revoke the tokens", func() {
+			tstName, tstSecretName := "test-revocation-secret", "my-secret-revocation-secret"
+			key := types.NamespacedName{Name: tstName, Namespace: tstNamespace}
+
+			s := runtime.NewScheme()
+			Expect(hydrav1alpha1.AddToScheme(s)).To(Succeed())
+			Expect(apiv1.AddToScheme(s)).To(Succeed())
+
+			mgr, err := manager.New(cfg, manager.Options{
+				Scheme: s,
+				Metrics: server.Options{
+					BindAddress: ":8109",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			c := mgr.GetClient()
+
+			fake, hc := newFakeHydra()
+			defer fake.Close()
+
+			recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc))
+			Expect(add(mgr, recFn)).To(Succeed())
+			go func() {
+				for range requests {
+				}
+			}()
+
+			stopMgr := StartTestManager(mgr)
+
+			instance := testInstance(tstName, tstSecretName)
+			instance.Spec.RevocationPolicy = &hydrav1alpha1.RevocationPolicy{}
+			Expect(c.Create(context.TODO(), instance)).To(Succeed())
+
+			var retrieved hydrav1alpha1.OAuth2Client
+			Eventually(func() int64 {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.ObservedGeneration
+			}, timeout).Should(Equal(retrieved.Generation))
+			Expect(retrieved.Status.Revocation).To(BeNil())
+
+			// Verify the hash of the registered client secret is recorded
+			var secret apiv1.Secret
+			Expect(k8sClient.Get(context.TODO(), types.NamespacedName{Name: tstSecretName, Namespace: tstNamespace}, &secret)).To(Succeed())
+			clientID := string(secret.Data[controllers.ClientIDKey])
+			Expect(secret.Annotations).To(HaveKey(controllers.ClientSecretHashAnnotation))
+
+			// Rotate the client secret, the test controller does not watch
+			// the Secret so the client is touched to reconcile it
+			secret.Data[controllers.ClientSecretKey] = []byte("rotated-secret")
+			Expect(k8sClient.Update(context.TODO(), &secret)).To(Succeed())
+			Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+			retrieved.Annotations = map[string]string{"touched": "true"}
+			Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+
+			Eventually(func() *string {
+				registered, _ := fake.Client(clientID)
+				return registered.Secret
+			}, timeout).Should(Equal(ptr.To("rotated-secret")))
+			Eventually(fake.Revocations, timeout).Should(Equal([]hydratest.Revocation{
+				{Target: hydrav1alpha1.RevokeTokens, ClientID: clientID},
+			}))
+			Eventually(func() *hydrav1alpha1.RevocationStatus {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.Revocation
+			}, timeout).ShouldNot(BeNil())
+			Expect(retrieved.Status.Revocation.ClientID).To(Equal(clientID))
+			Expect(retrieved.Status.Revocation.Trigger).To(Equal(hydrav1alpha1.RevocationOnRekey))
+
+			// Verify another change of the Secret does not revoke the tokens
+			Expect(k8sClient.Get(context.TODO(), types.NamespacedName{Name: tstSecretName, Namespace: tstNamespace}, &secret)).To(Succeed())
+			secret.Labels = map[string]string{"touched": "true"}
+			Expect(k8sClient.Update(context.TODO(), &secret)).To(Succeed())
+			Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+			retrieved.Annotations = map[string]string{"touched": "again"}
+			Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+			Eventually(func() string {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.SecretResourceVersion
+			}, timeout).Should(Equal(secret.ResourceVersion))
+			Expect(fake.Revocations()).To(HaveLen(1))
+
+			Expect(c.Delete(context.TODO(), &retrieved)).To(Succeed())
+			stopMgr.Done()
+		})
+	})
+
+	Context("when the client is registered again", func() {
+
+		It("replace the previous client without downtime", func() {
+			tstName, tstSecretName := "test-replacement", "my-secret-replacement"
+			key := types.NamespacedName{Name: tstName, Namespace: tstNamespace}
+
+			s := runtime.NewScheme()
+			Expect(hydrav1alpha1.AddToScheme(s)).To(Succeed())
+			Expect(apiv1.AddToScheme(s)).To(Succeed())
+
+			mgr, err := manager.New(cfg, manager.Options{
+				Scheme: s,
+				Metrics: server.Options{
+					BindAddress: ":8102",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			c := mgr.GetClient()
+
+			fake, hc := newFakeHydra()
+			defer fake.Close()
+
+			// record the client ID in the Secret when each client is
+			// registered
+			var (
+				mu           sync.Mutex
+				secretAtPost = map[string]string{}
+			)
+			fake.OnRequest(func(r *http.Request) {
+				defer GinkgoRecover()
+				if r.Method != http.MethodPost {
+					return
+				}
+				var o hydra.OAuth2ClientJSON
+				body, err := io.ReadAll(r.Body)
+				Expect(err).NotTo(HaveOccurred())
+				r.Body = io.NopCloser(bytes.NewReader(body))
+				Expect(json.Unmarshal(body, &o)).To(Succeed())
+
+				var secret apiv1.Secret
+				_ = k8sClient.Get(context.TODO(), types.NamespacedName{Name: tstSecretName, Namespace: tstNamespace}, &secret)
+				mu.Lock()
+				defer mu.Unlock()
+				secretAtPost[*o.ClientID] = string(secret.Data[controllers.ClientIDKey])
+			})
+			registered := func(id string) bool {
+				_, found := fake.Client(id)
+				return found
+			}
+
+			recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc, controllers.WithReplacementGracePeriod(time.Hour)))
+			Expect(add(mgr, recFn)).To(Succeed())
+
+			// the spec waits for the outcome of several reconciliations
+			go func() {
+				for range requests {
+				}
+			}()
+
+			stopMgr := StartTestManager(mgr)
+
+			instance := testInstance(tstName, tstSecretName)
+			instance.Spec.ClientID = "first-id"
+			Expect(c.Create(context.TODO(), instance)).To(Succeed())
+
+			var retrieved hydrav1alpha1.OAuth2Client
+			Eventually(func() string {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.ClientID
+			}, timeout).Should(Equal("first-id"))
+			Expect(retrieved.Status.Replacement).To(BeNil())
+
+			// Change the client ID
+			retrieved.Spec.ClientID = "second-id"
+			Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+
+			Eventually(func() *hydrav1alpha1.ReplacementStatus {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.Replacement
+			}, timeout).ShouldNot(BeNil())
+			Expect(retrieved.Status.Replacement.Phase).To(Equal(hydrav1alpha1.ReplacementSecretUpdated))
+			Expect(retrieved.Status.Replacement.ClientID).To(Equal("second-id"))
+			Expect(retrieved.Status.Replacement.PreviousClientIDs).To(Equal([]string{"first-id"}))
+			Expect(retrieved.Status.Replacement.SecretUpdateTime).NotTo(BeNil())
+
+			// Verify the new client was registered before the Secret was
+			// updated, and the previous one is kept for the grace period
+			var secret apiv1.Secret
+			Expect(k8sClient.Get(context.TODO(), types.NamespacedName{Name: tstSecretName, Namespace: tstNamespace}, &secret)).To(Succeed())
+			Expect(string(secret.Data[controllers.ClientIDKey])).To(Equal("second-id"))
+			mu.Lock()
+			Expect(secretAtPost["second-id"]).To(Equal("first-id"))
+			mu.Unlock()
+			Consistently(func() bool {
+				return registered("first-id") && registered("second-id")
+			}, time.Second).Should(BeTrue())
+
+			// Acknowledge the replacement
+			retrieved.Annotations = map[string]string{controllers.ReplacementAcknowledgedAnnotation: "second-id"}
+			Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+
+			Eventually(func() bool {
+				return registered("first-id")
+			}, timeout).Should(BeFalse())
+			Eventually(func() *hydrav1alpha1.ReplacementStatus {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.Replacement
+			}, timeout).Should(BeNil())
+			Expect(registered("second-id")).To(BeTrue())
+
+			c.Delete(context.TODO(), instance)
+			stopMgr.Done()
+		})
+	})
+
+	Context("with failing reconciliations", func() {
+
+		It("retry transient errors with a backoff", func() {
+			tstName, tstSecretName := "test-transient", "my-secret-transient"
+			key := types.NamespacedName{Name: tstName, Namespace: tstNamespace}
+
+			s := runtime.NewScheme()
+			Expect(hydrav1alpha1.AddToScheme(s)).To(Succeed())
+			Expect(apiv1.AddToScheme(s)).To(Succeed())
+
+			mgr, err := manager.New(cfg, manager.Options{
+				Scheme: s,
+				Metrics: server.Options{
+					BindAddress: ":8100",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			c := mgr.GetClient()
+
+			fake, hc := newFakeHydra()
+			defer fake.Close()
+			fake.Inject(hydratest.Fault{Method: http.MethodPost, StatusCode: http.StatusServiceUnavailable})
+
+			recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc, controllers.WithRetryBackoff(100*time.Millisecond, time.Second)))
+			Expect(add(mgr, recFn)).To(Succeed())
+
+			// the spec waits for the outcome of several reconciliations
+			go func() {
+				for range requests {
+				}
+			}()
+
+			stopMgr := StartTestManager(mgr)
+
+			instance := testInstance(tstName, tstSecretName)
+			Expect(c.Create(context.TODO(), instance)).To(Succeed())
+
+			// Verify the registration is retried and the retries are reported
+			var retrieved hydrav1alpha1.OAuth2Client
+			Eventually(func() int32 {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.RetryCount
+			}, timeout).Should(BeNumerically(">=", 2))
+			Expect(retrieved.Status.NextRetryTime).NotTo(BeNil())
+			Expect(retrieved.Status.ReconciliationError.Code).To(Equal(hydrav1alpha1.StatusRegistrationFailed))
+			Expect(retrieved.Status.Conditions).To(Equal([]hydrav1alpha1.OAuth2ClientCondition{{
+				Type:   hydrav1alpha1.OAuth2ClientConditionReady,
+				Status: hydrav1alpha1.ConditionFalse,
+			}, {
+				Type:   hydrav1alpha1.OAuth2ClientConditionHydraReachable,
+				Status: hydrav1alpha1.ConditionFalse,
+			}}))
+
+			// Verify the client is registered once ORY Hydra is back
+			fake.ClearFaults()
+
+			Eventually(func() []hydrav1alpha1.OAuth2ClientCondition {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.Conditions
+			}, timeout).Should(Equal([]hydrav1alpha1.OAuth2ClientCondition{{
+				Type:   hydrav1alpha1.OAuth2ClientConditionReady,
+				Status: hydrav1alpha1.ConditionTrue,
+			}}))
+			Expect(retrieved.Status.RetryCount).To(BeZero())
+			Expect(retrieved.Status.NextRetryTime).To(BeNil())
+			Expect(retrieved.Status.ReconciliationError).To(Equal(hydrav1alpha1.ReconciliationError{}))
+
+			c.Delete(context.TODO(), instance)
+			stopMgr.Done()
+		})
+
+		It("not retry terminal errors", func() {
+			tstName, tstSecretName := "test-terminal", "my-secret-terminal"
+			key := types.NamespacedName{Name: tstName, Namespace: tstNamespace}
+
+			s := runtime.NewScheme()
+			Expect(hydrav1alpha1.AddToScheme(s)).To(Succeed())
+			Expect(apiv1.AddToScheme(s)).To(Succeed())
+
+			mgr, err := manager.New(cfg, manager.Options{
+				Scheme: s,
+				Metrics: server.Options{
+					BindAddress: ":8101",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			c := mgr.GetClient()
+
+			fake, hc := newFakeHydra()
+			defer fake.Close()
+			fake.Inject(hydratest.Fault{Method: http.MethodPost, StatusCode: http.StatusBadRequest})
+			posts := func() int {
+				return countRequests(fake, "POST /admin/clients")
+			}
+
+			recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc, controllers.WithRetryBackoff(100*time.Millisecond, time.Second)))
+			Expect(add(mgr, recFn)).To(Succeed())
+
+			// the spec waits for the outcome of several reconciliations
+			go func() {
+				for range requests {
+				}
+			}()
+
+			stopMgr := StartTestManager(mgr)
+
+			instance := testInstance(tstName, tstSecretName)
+			Expect(c.Create(context.TODO(), instance)).To(Succeed())
+
+			var retrieved hydrav1alpha1.OAuth2Client
+			Eventually(func() hydrav1alpha1.StatusCode {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.ReconciliationError.Code
+			}, timeout).Should(Equal(hydrav1alpha1.StatusRegistrationFailed))
+			Expect(retrieved.Status.RetryCount).To(BeZero())
+			Expect(retrieved.Status.NextRetryTime).To(BeNil())
+
+			// Verify the registration is not retried, once the reconciliations
+			// triggered by the status updates settled
+			Eventually(posts, timeout).Should(BeNumerically(">", 0))
+			time.Sleep(time.Second)
+			settled := posts()
+			Consistently(posts, 2*time.Second).Should(Equal(settled))
+
+			c.Delete(context.TODO(), instance)
+			stopMgr.Done()
+		})
+	})
+
+	Context("with the reconcile and resync-at annotations", func() {
+
+		It("not touch a paused client and fully sync it when asked to", func() {
+			tstName, tstSecretName := "test-paused", "my-secret-paused"
+			key := types.NamespacedName{Name: tstName, Namespace: tstNamespace}
+
+			s := runtime.NewScheme()
+			Expect(hydrav1alpha1.AddToScheme(s)).To(Succeed())
+			Expect(apiv1.AddToScheme(s)).To(Succeed())
+
+			mgr, err := manager.New(cfg, manager.Options{
+				Scheme: s,
+				Metrics: server.Options{
+					BindAddress: ":8099",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			c := mgr.GetClient()
+
+			fake, hc := newFakeHydra()
+			defer fake.Close()
+			posts := func() int {
+				return countRequests(fake, "POST /admin/clients")
+			}
+
+			recFn, requests := SetupTestReconcile(getAPIReconciler(mgr, hc))
+			Expect(add(mgr, recFn)).To(Succeed())
+
+			// the spec waits for the outcome of several reconciliations
+			go func() {
+				for range requests {
+				}
+			}()
+
+			stopMgr := StartTestManager(mgr)
+
+			instance := testInstance(tstName, tstSecretName)
+			instance.Annotations = map[string]string{controllers.ReconcileAnnotation: controllers.ReconcilePaused}
+			Expect(c.Create(context.TODO(), instance)).To(Succeed())
+
+			// Verify a paused client is neither registered nor given a secret
+			var retrieved hydrav1alpha1.OAuth2Client
+			Eventually(func() []hydrav1alpha1.OAuth2ClientCondition {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.Conditions
+			}, timeout).Should(ContainElement(hydrav1alpha1.OAuth2ClientCondition{
+				Type:   hydrav1alpha1.OAuth2ClientConditionPaused,
+				Status: hydrav1alpha1.ConditionTrue,
+			}))
+			Expect(posts()).To(BeZero())
+			var secret apiv1.Secret
+			err = k8sClient.Get(context.TODO(), types.NamespacedName{Name: tstSecretName, Namespace: tstNamespace}, &secret)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			// Resume the reconciliation
+			delete(retrieved.Annotations, controllers.ReconcileAnnotation)
+			Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+
+			Eventually(func() []hydrav1alpha1.OAuth2ClientCondition {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.Conditions
+			}, timeout).Should(Equal([]hydrav1alpha1.OAuth2ClientCondition{{
+				Type:   hydrav1alpha1.OAuth2ClientConditionReady,
+				Status: hydrav1alpha1.ConditionTrue,
+			}}))
+			Expect(posts()).To(Equal(1))
+			puts := func() int {
+				return countRequests(fake, "PUT /admin/clients/"+retrieved.Status.ClientID)
+			}
+			putsBefore := puts()
+
+			// Force a full sync without changing the spec
+			retrieved.Annotations = map[string]string{controllers.ResyncAtAnnotation: "2023-01-01T00:00:00Z"}
+			Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+
+			Eventually(puts, timeout).Should(Equal(putsBefore + 1))
+			Eventually(func() string {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.ResyncAt
+			}, timeout).Should(Equal("2023-01-01T00:00:00Z"))
+
+			// the same value does not sync the client again
+			Consistently(puts, time.Second).Should(Equal(putsBefore + 1))
+
+			// Verify a paused client is deleted from ORY Hydra along with the
+			// resource
+			retrieved.Annotations = map[string]string{controllers.ReconcileAnnotation: controllers.ReconcilePaused}
+			Expect(c.Update(context.TODO(), &retrieved)).To(Succeed())
+			Expect(c.Delete(context.TODO(), &retrieved)).To(Succeed())
+
+			Eventually(func() bool {
+				return apierrors.IsNotFound(c.Get(context.TODO(), key, &hydrav1alpha1.OAuth2Client{}))
+			}, timeout).Should(BeTrue())
+			_, found := fake.Client(retrieved.Status.ClientID)
+			Expect(found).To(BeFalse())
+
+			stopMgr.Done()
+		})
+	})
+
+	Context("the readiness check", func() {
+
+		It("fail while ORY Hydra is not ready", func() {
+			fake, hc := newFakeHydra()
+			defer fake.Close()
+			r := controllers.New(k8sClient, hc, ctrl.Log.WithName("controllers").WithName("OAuth2Client"))
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+			fake.Inject(hydratest.Fault{Path: "/health/ready", StatusCode: http.StatusServiceUnavailable, Times: 1})
+			err := r.ReadyzCheck(req)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("default hydra admin is not ready"))
+			Expect(err.Error()).To(ContainSubstring("503 Service Unavailable"))
+
+			Expect(r.ReadyzCheck(req)).To(Succeed())
 		})
 	})
 })
@@ -829,19 +1741,42 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	return nil
 }
 
-func getAPIReconciler(mgr ctrl.Manager, mock hydra.Client) reconcile.Reconciler {
-	clientMocker := func(spec hydrav1alpha1.OAuth2ClientSpec, tlsTrustStore string, insecureSkipVerify bool) (hydra.Client, error) {
-		return mock, nil
+// getAPIReconciler returns a reconciler talking to ORY Hydra through hc,
+// whatever the admin endpoint of the clients.
+func getAPIReconciler(mgr ctrl.Manager, hc hydra.Client, opts ...controllers.Option) reconcile.Reconciler {
+	clientFactory := func(spec hydrav1alpha1.OAuth2ClientSpec, tlsTrustStore string, insecureSkipVerify bool) (hydra.Client, error) {
+		return hc, nil
 	}
 
 	return controllers.New(
 		mgr.GetClient(),
-		mock,
+		hc,
 		ctrl.Log.WithName("controllers").WithName("OAuth2Client"),
-		controllers.WithClientFactory(clientMocker),
+		append([]controllers.Option{controllers.WithClientFactory(clientFactory)}, opts...)...,
 	)
 }
 
+// newFakeHydra starts a fake ORY Hydra, which the caller should Close, and
+// returns it with a client of the hydra package for it.
+func newFakeHydra(opts ...hydratest.Option) (*hydratest.Server, hydra.Client) {
+	fake := hydratest.NewServer(opts...)
+	hc, err := fake.NewClient()
+	Expect(err).NotTo(HaveOccurred())
+	return fake, hc
+}
+
+// countRequests returns the number of requests the fake served with the
+// given method and path, such as "POST /admin/clients".
+func countRequests(fake *hydratest.Server, request string) int {
+	n := 0
+	for _, r := range fake.Requests() {
+		if r == request {
+			n++
+		}
+	}
+	return n
+}
+
 func testInstance(name, secretName string) *hydrav1alpha1.OAuth2Client {
 
 	return &hydrav1alpha1.OAuth2Client{
@@ -0,0 +1,241 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/credentials"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+const (
+	// ClientPrivateKeyKey is the key of the client's Secret holding the PEM
+	// encoded private key generated for the client.
+	ClientPrivateKeyKey = "PRIVATE_KEY"
+	// ClientKeyIDKey is the key of the client's Secret holding the ID of the
+	// generated private key.
+	ClientKeyIDKey = "KEY_ID"
+	// ClientJwksKey is the key of the client's Secret holding the public JSON
+	// Web Key Set registered in ORY Hydra.
+	ClientJwksKey = "JWKS"
+
+	// KeyRotatedAtAnnotation is the annotation of the client's Secret holding
+	// the time the current key pair was generated at.
+	KeyRotatedAtAnnotation = "hydra.ory.sh/key-rotated-at"
+	// PreviousKeyExpiresAtAnnotation is the annotation of the client's Secret
+	// holding the time the previous public key is unregistered at.
+	PreviousKeyExpiresAtAnnotation = "hydra.ory.sh/previous-key-expires-at"
+)
+
+// ensureKeyPair returns the public keys to register in ORY Hydra for a valid
+// key pair for spec.keyPairGeneration, rotating it if it is due, and the
+// client's Secret holding the key pair to store once they are registered, or
+// nil if the Secret is up to date. The Secret is the source of truth of the
+// key pair, its state is reported in the status of the client.
+//
+// A rotated key is registered along with the new one, even without an
+// overlap period, so that the key the Secret holds is always registered: a
+// failure between registering the keys and storing the Secret only leaves an
+// extra key registered until the next rotation.
+func (r *OAuth2ClientReconciler) ensureKeyPair(ctx context.Context, c *hydrav1alpha1.OAuth2Client) (*hydra.JSONWebKeySet, *apiv1.Secret, error) {
+	gen := c.Spec.KeyPairGeneration
+
+	var secret apiv1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Name: c.Spec.SecretName, Namespace: c.Namespace}, &secret); err != nil {
+		return nil, nil, fmt.Errorf("unable to get secret %s/%s holding the key pair: %w", c.Namespace, c.Spec.SecretName, err)
+	}
+	original := secret.DeepCopy()
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+
+	var stored hydra.JSONWebKeySet
+	if b, ok := secret.Data[ClientJwksKey]; ok {
+		// an invalid set only loses the previous key
+		_ = json.Unmarshal(b, &stored)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	rotatedAt, rotatedAtErr := time.Parse(time.RFC3339, secret.Annotations[KeyRotatedAtAnnotation])
+	previousExpiresAt, _ := time.Parse(time.RFC3339, secret.Annotations[PreviousKeyExpiresAtAnnotation])
+
+	current, err := credentials.ParseKeyPair(secret.Data[ClientPrivateKeyKey], gen.Algorithm)
+	var previous hydra.JSONWebKey
+	switch {
+	case err != nil || current.KeyID != string(secret.Data[ClientKeyIDKey]):
+		// the key is missing, invalid or of another algorithm, the public key
+		// registered for it is kept during the overlap period if known
+		previous = stored.Key(string(secret.Data[ClientKeyIDKey]))
+		current = nil
+	case rotatedAtErr != nil:
+		// the key has been set by hand, its rotation schedule starts now
+		rotatedAt = now
+	case gen.RotationPeriod.Duration > 0 && !now.Before(rotatedAt.Add(gen.RotationPeriod.Duration)):
+		previous = current.PublicKey
+		current = nil
+	default:
+		if now.Before(previousExpiresAt) {
+			for _, k := range stored.Keys {
+				if k.KeyID() != current.KeyID {
+					previous = k
+					break
+				}
+			}
+		}
+	}
+
+	if current == nil {
+		if current, err = credentials.GenerateKeyPair(gen.Algorithm); err != nil {
+			return nil, nil, err
+		}
+		ctrl.LoggerFrom(ctx).Info("generated key pair", "keyID", current.KeyID)
+		rotatedAt = now
+		// without an overlap period, the previous key is unregistered by the
+		// next reconciliation
+		previousExpiresAt = now.Add(max(gen.OverlapPeriod.Duration, 0))
+	}
+
+	jwks := &hydra.JSONWebKeySet{Keys: []hydra.JSONWebKey{current.PublicKey}}
+	if previous != nil {
+		jwks.Keys = append(jwks.Keys, previous.Public())
+	}
+	b, err := json.Marshal(jwks)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	secret.Data[ClientPrivateKeyKey] = current.PrivateKey
+	secret.Data[ClientKeyIDKey] = []byte(current.KeyID)
+	secret.Data[ClientJwksKey] = b
+	secret.Annotations[KeyRotatedAtAnnotation] = rotatedAt.Format(time.RFC3339)
+	if previous != nil {
+		secret.Annotations[PreviousKeyExpiresAtAnnotation] = previousExpiresAt.Format(time.RFC3339)
+	} else {
+		delete(secret.Annotations, PreviousKeyExpiresAtAnnotation)
+	}
+
+	var changed *apiv1.Secret
+	if !reflect.DeepEqual(original.Data, secret.Data) || !reflect.DeepEqual(original.Annotations, secret.Annotations) {
+		changed = &secret
+	}
+
+	status := &hydrav1alpha1.KeyPairStatus{
+		KeyID:     current.KeyID,
+		RotatedAt: &metav1.Time{Time: rotatedAt},
+	}
+	if previous != nil {
+		status.PreviousKeyID = previous.KeyID()
+		status.PreviousKeyExpiresAt = &metav1.Time{Time: previousExpiresAt}
+	}
+	c.Status.KeyPair = status
+
+	return jwks, changed, nil
+}
+
+// storeKeyPair stores the key pair of secret, as returned by ensureKeyPair, in
+// the client's Secret once its public keys are registered in ORY Hydra. The
+// Secret is fetched again as the credentials may have been stored in the
+// meantime.
+func (r *OAuth2ClientReconciler) storeKeyPair(ctx context.Context, c *hydrav1alpha1.OAuth2Client, secret *apiv1.Secret) error {
+	if secret == nil {
+		return nil
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var latest apiv1.Secret
+		if err := r.Get(ctx, client.ObjectKeyFromObject(secret), &latest); err != nil {
+			return err
+		}
+		if latest.Data == nil {
+			latest.Data = map[string][]byte{}
+		}
+		if latest.Annotations == nil {
+			latest.Annotations = map[string]string{}
+		}
+		for _, key := range []string{ClientPrivateKeyKey, ClientKeyIDKey, ClientJwksKey} {
+			latest.Data[key] = secret.Data[key]
+		}
+		for _, key := range []string{KeyRotatedAtAnnotation, PreviousKeyExpiresAtAnnotation} {
+			if value, ok := secret.Annotations[key]; ok {
+				latest.Annotations[key] = value
+			} else {
+				delete(latest.Annotations, key)
+			}
+		}
+		return r.Update(ctx, &latest)
+	})
+	if err != nil {
+		err = fmt.Errorf("unable to store the key pair in secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		if updateErr := r.updateReconciliationStatusError(ctx, c, hydrav1alpha1.StatusCreateSecretFailed, err); updateErr != nil {
+			return updateErr
+		}
+		return err
+	}
+	return nil
+}
+
+// keyPairDue returns true if the key pair generated for the client has to be
+// rotated, or its previous public key unregistered.
+func keyPairDue(c *hydrav1alpha1.OAuth2Client, now time.Time) bool {
+	status := c.Status.KeyPair
+	if status == nil || status.KeyID == "" || status.RotatedAt == nil {
+		return true
+	}
+	return keyPairRequeueAfter(c, now) < 0
+}
+
+// keyPairResult requeues the client when the key pair generated for it is
+// next due.
+func keyPairResult(c *hydrav1alpha1.OAuth2Client) ctrl.Result {
+	after := keyPairRequeueAfter(c, time.Now())
+	if after < 0 {
+		// overdue, e.g. the previous public key of a rotation without an
+		// overlap period
+		after = time.Second
+	}
+	return ctrl.Result{RequeueAfter: after}
+}
+
+// keyPairRequeueAfter returns the time left until the next rotation of the
+// key pair generated for the client, or until its previous public key is
+// unregistered. It is zero if there is nothing to wait for and negative if
+// either is overdue.
+func keyPairRequeueAfter(c *hydrav1alpha1.OAuth2Client, now time.Time) time.Duration {
+	gen, status := c.Spec.KeyPairGeneration, c.Status.KeyPair
+	if gen == nil || status == nil {
+		return 0
+	}
+
+	var next time.Time
+	if gen.RotationPeriod.Duration > 0 && status.RotatedAt != nil {
+		next = status.RotatedAt.Add(gen.RotationPeriod.Duration)
+	}
+	if expires := status.PreviousKeyExpiresAt; expires != nil && (next.IsZero() || expires.Time.Before(next)) {
+		next = expires.Time
+	}
+	if next.IsZero() {
+		return 0
+	}
+
+	if left := next.Sub(now); left != 0 {
+		return left
+	}
+	return -1
+}
@@ -0,0 +1,153 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/credentials"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+const (
+	// DefaultReplacementGracePeriod is how long the clients replaced by a new
+	// one are kept in ORY Hydra after the credentials of the new one have
+	// been persisted.
+	DefaultReplacementGracePeriod = time.Minute
+
+	// EventReasonReplaced is the reason of the events reporting the
+	// completion of a replacement.
+	EventReasonReplaced = "Replaced"
+)
+
+// previousOAuth2Clients returns the IDs of the clients registered in ORY Hydra
+// for c under another ID than clientID, which are replaced by the client
+// registered under clientID. They are left alone if c orphans its clients.
+func (r *OAuth2ClientReconciler) previousOAuth2Clients(ctx context.Context, h hydra.Client, c *hydrav1alpha1.OAuth2Client, clientID string) ([]string, error) {
+	if c.Spec.DeletionPolicy == hydrav1alpha1.OAuth2ClientDeletionPolicyOrphan {
+		return nil, nil
+	}
+
+	clients, err := h.ListOAuth2Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous []string
+	for _, cJSON := range clients {
+		if cJSON.Owner == fmt.Sprintf("%s/%s", c.Name, c.Namespace) && cJSON.ClientID != nil && *cJSON.ClientID != clientID {
+			previous = append(previous, *cJSON.ClientID)
+		}
+	}
+	return previous, nil
+}
+
+// continueReplacement advances the replacement of the clients registered in
+// ORY Hydra for c, if any. It returns the time left until the previous
+// clients may be deleted, during which c is not reconciled any further.
+func (r *OAuth2ClientReconciler) continueReplacement(ctx context.Context, c *hydrav1alpha1.OAuth2Client) (time.Duration, error) {
+	replacement := c.Status.Replacement
+	if replacement == nil {
+		return 0, nil
+	}
+
+	h, err := r.getHydraClientForClient(ctx, *c)
+	if err != nil {
+		return 0, err
+	}
+	log := ctrl.LoggerFrom(ctx)
+
+	if replacement.Phase == hydrav1alpha1.ReplacementCreated {
+		// the reconciliation registering the new client did not complete
+		persisted, err := r.replacementPersisted(ctx, c, replacement.ClientID)
+		if err != nil {
+			return 0, err
+		}
+		_, registered, err := h.GetOAuth2Client(ctx, replacement.ClientID)
+		if err != nil {
+			return 0, err
+		}
+
+		if !registered || !persisted {
+			// nothing can use the new client, so that it is deleted and the
+			// replacement starts over
+			log.Info("replacement did not complete, starting over", "previousClientIDs", replacement.PreviousClientIDs, "replacementClientID", replacement.ClientID)
+			if registered {
+				if err := h.DeleteOAuth2Client(ctx, replacement.ClientID); err != nil {
+					return 0, err
+				}
+			}
+			return 0, r.setReplacement(ctx, c, nil)
+		}
+
+		// only the status update was lost
+		replacement = replacement.DeepCopy()
+		replacement.Phase = hydrav1alpha1.ReplacementSecretUpdated
+		replacement.SecretUpdateTime = &metav1.Time{Time: time.Now()}
+		if err := r.setReplacement(ctx, c, replacement); err != nil {
+			return 0, err
+		}
+	}
+
+	if c.Annotations[ReplacementAcknowledgedAnnotation] != replacement.ClientID && replacement.SecretUpdateTime != nil {
+		if left := time.Until(replacement.SecretUpdateTime.Add(r.replacementGracePeriod)); left > 0 {
+			return left, nil
+		}
+	}
+
+	for _, id := range replacement.PreviousClientIDs {
+		r.revokeOAuth2Client(ctx, h, c, id, hydrav1alpha1.RevocationOnRekey)
+		if err := h.DeleteOAuth2Client(ctx, id); err != nil {
+			return 0, err
+		}
+	}
+	log.Info("replaced clients", "previousClientIDs", replacement.PreviousClientIDs, "replacementClientID", replacement.ClientID)
+	r.eventf(c, apiv1.EventTypeNormal, EventReasonReplaced, "Replace", "Replaced clients %v by client %s", replacement.PreviousClientIDs, replacement.ClientID)
+
+	return 0, r.setReplacement(ctx, c, nil)
+}
+
+// replacementPersisted returns true if the credential store of c holds the
+// credentials of the client registered under clientID.
+func (r *OAuth2ClientReconciler) replacementPersisted(ctx context.Context, c *hydrav1alpha1.OAuth2Client, clientID string) (bool, error) {
+	store, err := r.getCredentialStore(*c)
+	if err != nil {
+		return false, nil
+	}
+
+	creds, found, err := store.Get(ctx, c)
+	if err != nil {
+		if credentials.IsInvalid(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return found && string(creds.ID) == clientID, nil
+}
+
+// setReplacement records the state of the replacement of the clients
+// registered in ORY Hydra for c, or that there is none.
+func (r *OAuth2ClientReconciler) setReplacement(ctx context.Context, c *hydrav1alpha1.OAuth2Client, replacement *hydrav1alpha1.ReplacementStatus) error {
+	// the last revocation is set by the caller, and lost when the object is
+	// fetched again
+	revocation := c.Status.Revocation
+	_, err := controllerutil.CreateOrPatch(ctx, r.Client, c, func() error {
+		c.Status.Replacement = replacement
+		c.Status.Revocation = revocation
+		return nil
+	})
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "status update failed")
+	}
+
+	return err
+}
@@ -0,0 +1,148 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+const (
+	// EventReasonRevoked is the reason of the events reporting a revocation.
+	EventReasonRevoked = "Revoked"
+	// EventReasonRevocationFailed is the reason of the events reporting a
+	// failed revocation.
+	EventReasonRevocationFailed = "RevocationFailed"
+
+	// ClientSecretHashAnnotation is the annotation of the client's Secret
+	// holding the SHA-256 hash of the client secret registered in ORY Hydra,
+	// which tells a rotated client secret from the other changes of the
+	// Secret.
+	ClientSecretHashAnnotation = "hydra.ory.sh/client-secret-hash"
+)
+
+// revokeOAuth2Client revokes what the revocation policy of the client lists
+// if it applies to the trigger, for the client registered in ORY Hydra under
+// clientID. The result is reported in an event and in the status of the
+// client, a failed revocation does not fail the reconciliation.
+func (r *OAuth2ClientReconciler) revokeOAuth2Client(ctx context.Context, h hydra.Client, c *hydrav1alpha1.OAuth2Client, clientID string, trigger hydrav1alpha1.RevocationTrigger) {
+	policy := c.Spec.RevocationPolicy
+	if policy == nil {
+		return
+	}
+	on := policy.On
+	if len(on) == 0 {
+		on = []hydrav1alpha1.RevocationTrigger{hydrav1alpha1.RevocationOnDelete, hydrav1alpha1.RevocationOnRekey}
+	}
+	if !slices.Contains(on, trigger) {
+		return
+	}
+	targets := policy.Revoke
+	if len(targets) == 0 {
+		targets = []hydrav1alpha1.RevocationTarget{hydrav1alpha1.RevokeTokens}
+	}
+
+	now := metav1.Now()
+	status := &hydrav1alpha1.RevocationStatus{
+		ClientID: clientID,
+		Trigger:  trigger,
+		Time:     &now,
+	}
+
+	var errs []error
+	for _, target := range targets {
+		var err error
+		switch target {
+		case hydrav1alpha1.RevokeTokens:
+			err = h.RevokeOAuth2ClientTokens(ctx, clientID)
+		default:
+			err = fmt.Errorf("unknown revocation target %q", target)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to revoke %s: %w", target, err))
+			continue
+		}
+		status.Revoked = append(status.Revoked, target)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		status.Error = err.Error()
+		ctrl.LoggerFrom(ctx).Error(err, "revocation failed", "revokedClientID", clientID, "trigger", trigger)
+		r.eventf(c, apiv1.EventTypeWarning, EventReasonRevocationFailed, "Revoke", "Revocation for client %s on %s failed: %s", clientID, trigger, err)
+	} else {
+		ctrl.LoggerFrom(ctx).Info("revoked", "revoked", status.Revoked, "revokedClientID", clientID, "trigger", trigger)
+		r.eventf(c, apiv1.EventTypeNormal, EventReasonRevoked, "Revoke", "Revoked %v for client %s on %s", status.Revoked, clientID, trigger)
+	}
+	c.Status.Revocation = status
+}
+
+// storeClientSecretHash records the hash of the client secret registered in
+// ORY Hydra in the client's Secret, and returns true if it replaces the hash
+// of another client secret. Only the clients keeping their credentials in a
+// Secret are tracked, the Secret of a client registered before the hash was
+// recorded is not considered rotated.
+func (r *OAuth2ClientReconciler) storeClientSecretHash(ctx context.Context, c *hydrav1alpha1.OAuth2Client, password []byte) (bool, error) {
+	if c.Spec.CredentialStore != "" && c.Spec.CredentialStore != hydrav1alpha1.CredentialStoreSecret {
+		return false, nil
+	}
+
+	var hash string
+	if len(password) > 0 {
+		sum := sha256.Sum256(password)
+		hash = hex.EncodeToString(sum[:])
+	}
+
+	var rotated bool
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var secret apiv1.Secret
+		if err := r.Get(ctx, types.NamespacedName{Name: c.Spec.SecretName, Namespace: c.Namespace}, &secret); err != nil {
+			return err
+		}
+		previous, found := secret.Annotations[ClientSecretHashAnnotation]
+		rotated = found && previous != hash
+		if previous == hash {
+			return nil
+		}
+
+		if hash == "" {
+			delete(secret.Annotations, ClientSecretHashAnnotation)
+		} else {
+			if secret.Annotations == nil {
+				secret.Annotations = map[string]string{}
+			}
+			secret.Annotations[ClientSecretHashAnnotation] = hash
+		}
+		return r.Update(ctx, &secret)
+	})
+	if err != nil {
+		err = fmt.Errorf("unable to store the client secret hash in secret %s/%s: %w", c.Namespace, c.Spec.SecretName, err)
+		if updateErr := r.updateReconciliationStatusError(ctx, c, hydrav1alpha1.StatusCreateSecretFailed, err); updateErr != nil {
+			return false, updateErr
+		}
+		return false, err
+	}
+	return rotated, nil
+}
+
+// eventf records an event on the client if the reconciler has an event
+// recorder.
+func (r *OAuth2ClientReconciler) eventf(c *hydrav1alpha1.OAuth2Client, eventtype, reason, action, note string, args ...interface{}) {
+	if r.recorder == nil {
+		return
+	}
+	r.recorder.Eventf(c, nil, eventtype, reason, action, note, args...)
+}
@@ -0,0 +1,99 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/policy"
+)
+
+// OAuth2ScopeReconciler reports in the status of the OAuth2Scopes whether they
+// are ignored because an earlier OAuth2Scope declares the same scope.
+type OAuth2ScopeReconciler struct {
+	client.Client
+	Log logr.Logger
+}
+
+// NewOAuth2ScopeReconciler returns a new OAuth2ScopeReconciler.
+func NewOAuth2ScopeReconciler(c client.Client, log logr.Logger) *OAuth2ScopeReconciler {
+	return &OAuth2ScopeReconciler{
+		Client: c,
+		Log:    log,
+	}
+}
+
+// +kubebuilder:rbac:groups=hydra.ory.sh,resources=oauth2scopes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=hydra.ory.sh,resources=oauth2scopes/status,verbs=get;update;patch
+
+func (r *OAuth2ScopeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, span := startReconcileSpan(ctx, "OAuth2Scope", req)
+	defer func() { endReconcileSpan(span, result, err) }()
+
+	ctx = ctrl.LoggerInto(ctx, r.Log.WithValues("oauth2scope", req.Name))
+
+	var scope hydrav1alpha1.OAuth2Scope
+	if err := r.Get(ctx, req.NamespacedName, &scope); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	owners, err := policy.ScopeOwners(ctx, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	conflictsWith := ""
+	if owner := owners[scope.Spec.Scope]; owner != nil && owner.Name != scope.Name {
+		conflictsWith = owner.Name
+	}
+	if scope.Status.ConflictsWith == conflictsWith {
+		return ctrl.Result{}, nil
+	}
+
+	if conflictsWith != "" {
+		ctrl.LoggerFrom(ctx).Info("scope is declared by an earlier OAuth2Scope, ignoring it", "scope", scope.Spec.Scope, "owner", conflictsWith)
+	}
+	_, err = controllerutil.CreateOrPatch(ctx, r.Client, &scope, func() error {
+		scope.Status.ConflictsWith = conflictsWith
+		return nil
+	})
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "status update failed")
+	}
+	return ctrl.Result{}, err
+}
+
+func (r *OAuth2ScopeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&hydrav1alpha1.OAuth2Scope{}).
+		Watches(&hydrav1alpha1.OAuth2Scope{}, handler.EnqueueRequestsFromMapFunc(r.scopesDeclaring)).
+		Complete(r)
+}
+
+// scopesDeclaring maps an OAuth2Scope to the OAuth2Scopes declaring the same
+// scope, whose conflicts change when it is created, updated or deleted.
+func (r *OAuth2ScopeReconciler) scopesDeclaring(ctx context.Context, o client.Object) []reconcile.Request {
+	var scopes hydrav1alpha1.OAuth2ScopeList
+	if err := r.List(ctx, &scopes); err != nil {
+		r.Log.Error(err, "unable to list scopes", "oauth2scope", o.GetName())
+		return nil
+	}
+
+	declared := o.(*hydrav1alpha1.OAuth2Scope).Spec.Scope
+	var requests []reconcile.Request
+	for _, s := range scopes.Items {
+		if s.Spec.Scope == declared && s.Name != o.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: s.Name}})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,75 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/controllers"
+)
+
+var _ = Describe("OAuth2Scope Controller", func() {
+
+	It("report the scopes declared by an earlier OAuth2Scope until it is deleted", func() {
+		s := runtime.NewScheme()
+		Expect(hydrav1alpha1.AddToScheme(s)).To(Succeed())
+
+		mgr, err := manager.New(cfg, manager.Options{
+			Scheme: s,
+			Metrics: server.Options{
+				BindAddress: ":8108",
+			},
+			Controller: config.Controller{SkipNameValidation: ptr.To(true)},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		c := mgr.GetClient()
+
+		reconciler := controllers.NewOAuth2ScopeReconciler(c, ctrl.Log.WithName("controllers").WithName("OAuth2Scope"))
+		Expect(reconciler.SetupWithManager(mgr)).To(Succeed())
+		StartTestManager(mgr)
+
+		owner := &hydrav1alpha1.OAuth2Scope{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-scope-owner"},
+			Spec:       hydrav1alpha1.OAuth2ScopeSpec{Scope: "test:conflict", Namespace: "team-a"},
+		}
+		Expect(c.Create(context.TODO(), owner)).To(Succeed())
+		// the creation timestamps have a precision of a second
+		time.Sleep(time.Second)
+		later := &hydrav1alpha1.OAuth2Scope{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-scope-later"},
+			Spec:       hydrav1alpha1.OAuth2ScopeSpec{Scope: "test:conflict", Namespace: "team-b"},
+		}
+		Expect(c.Create(context.TODO(), later)).To(Succeed())
+
+		var retrieved hydrav1alpha1.OAuth2Scope
+		Eventually(func() string {
+			Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(later), &retrieved)).To(Succeed())
+			return retrieved.Status.ConflictsWith
+		}, timeout).Should(Equal(owner.Name))
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(owner), &retrieved)).To(Succeed())
+		Expect(retrieved.Status.ConflictsWith).To(BeEmpty())
+
+		// Verify the later OAuth2Scope owns the scope once the owner is deleted
+		Expect(c.Delete(context.TODO(), owner)).To(Succeed())
+		Eventually(func() string {
+			Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(later), &retrieved)).To(Succeed())
+			return retrieved.Status.ConflictsWith
+		}, timeout).Should(BeEmpty())
+
+		Expect(c.Delete(context.TODO(), later)).To(Succeed())
+	})
+})
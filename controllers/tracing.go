@@ -0,0 +1,43 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// TracerName is the name of the tracer of the reconciliations.
+const TracerName = "github.com/ory/hydra-maester/controllers"
+
+// startReconcileSpan starts the span of the reconciliation of the given kind
+// of resource. The requests sent to the Kubernetes API and to ORY Hydra with
+// the returned context are part of it.
+func startReconcileSpan(ctx context.Context, kind string, req ctrl.Request) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, "Reconcile "+kind,
+		trace.WithAttributes(
+			attribute.String("k8s.namespace.name", req.Namespace),
+			attribute.String("k8s.resource.kind", kind),
+			attribute.String("k8s.resource.name", req.Name),
+		),
+	)
+}
+
+// endReconcileSpan ends the span of a reconciliation, which failed if err is
+// set.
+func endReconcileSpan(span trace.Span, result ctrl.Result, err error) {
+	defer span.End()
+	if result.RequeueAfter > 0 {
+		span.SetAttributes(attribute.String("reconcile.requeue_after", result.RequeueAfter.String()))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,399 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	apiv1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+// TrustClientFactory is a function that creates trust relationship clients.
+// The TrustedJwtGrantIssuerReconciler defaults to use hydra.NewTrustClient and
+// the factory allows to override this behavior for mocks during tests.
+type TrustClientFactory func(
+	admin hydrav1alpha1.HydraAdmin,
+	tlsTrustStore string,
+	insecureSkipVerify bool,
+) (hydra.TrustClient, error)
+
+// TrustedJwtGrantIssuerReconciler reconciles a TrustedJwtGrantIssuer object.
+type TrustedJwtGrantIssuerReconciler struct {
+	client.Client
+	TrustClient hydra.TrustClient
+	Log         logr.Logger
+
+	trustClients       map[clientKey]hydra.TrustClient
+	trustClientFactory TrustClientFactory
+	mu                 sync.Mutex
+}
+
+// TrustedJwtGrantIssuerOptions represent options to pass to the trusted jwt
+// grant issuer reconciler.
+type TrustedJwtGrantIssuerOptions struct {
+	TrustClientFactory TrustClientFactory
+}
+
+// TrustedJwtGrantIssuerOption is a functional option.
+type TrustedJwtGrantIssuerOption func(*TrustedJwtGrantIssuerOptions)
+
+// WithTrustClientFactory sets a function to create new trust relationship
+// clients during the reconciliation logic.
+func WithTrustClientFactory(factory TrustClientFactory) TrustedJwtGrantIssuerOption {
+	return func(o *TrustedJwtGrantIssuerOptions) {
+		o.TrustClientFactory = factory
+	}
+}
+
+// NewTrustedJwtGrantIssuerReconciler returns a new TrustedJwtGrantIssuerReconciler.
+func NewTrustedJwtGrantIssuerReconciler(c client.Client, trustClient hydra.TrustClient, log logr.Logger, opts ...TrustedJwtGrantIssuerOption) *TrustedJwtGrantIssuerReconciler {
+	options := &TrustedJwtGrantIssuerOptions{
+		TrustClientFactory: hydra.NewTrustClient,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &TrustedJwtGrantIssuerReconciler{
+		Client:             c,
+		TrustClient:        trustClient,
+		Log:                log,
+		trustClients:       make(map[clientKey]hydra.TrustClient),
+		trustClientFactory: options.TrustClientFactory,
+	}
+}
+
+// +kubebuilder:rbac:groups=hydra.ory.sh,resources=trustedjwtgrantissuers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=hydra.ory.sh,resources=trustedjwtgrantissuers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+func (r *TrustedJwtGrantIssuerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, span := startReconcileSpan(ctx, "TrustedJwtGrantIssuer", req)
+	defer func() { endReconcileSpan(span, result, err) }()
+
+	ctx = ctrl.LoggerInto(ctx, r.Log.WithValues("trustedjwtgrantissuer", req.NamespacedName))
+
+	var issuer hydrav1alpha1.TrustedJwtGrantIssuer
+	if err := r.Get(ctx, req.NamespacedName, &issuer); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if issuer.ObjectMeta.DeletionTimestamp.IsZero() {
+		if !containsString(issuer.ObjectMeta.Finalizers, FinalizerName) {
+			typeMeta := issuer.TypeMeta
+			issuer.ObjectMeta.Finalizers = append(issuer.ObjectMeta.Finalizers, FinalizerName)
+			if err := r.Update(ctx, &issuer); err != nil {
+				return ctrl.Result{}, err
+			}
+			// restore the TypeMeta object as it is removed during Update, but need to be accessed later
+			issuer.TypeMeta = typeMeta
+		}
+	} else {
+		if containsString(issuer.ObjectMeta.Finalizers, FinalizerName) {
+			if issuer.Status.ID != "" && issuer.Spec.DeletionPolicy != hydrav1alpha1.OAuth2ClientDeletionPolicyOrphan {
+				trustClient, err := r.getTrustClient(issuer)
+				if err != nil {
+					return ctrl.Result{}, err
+				}
+				if err := trustClient.DeleteTrustedJwtGrantIssuer(ctx, issuer.Status.ID); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+
+			issuer.ObjectMeta.Finalizers = removeString(issuer.ObjectMeta.Finalizers, FinalizerName)
+			if err := r.Update(ctx, &issuer); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		return ctrl.Result{}, nil
+	}
+
+	trustClient, err := r.getTrustClient(issuer)
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "hydra address is invalid",
+			"url", issuer.Spec.HydraAdmin.URL,
+			"port", issuer.Spec.HydraAdmin.Port,
+			"endpoint", issuer.Spec.HydraAdmin.Endpoint,
+		)
+		if updateErr := r.updateReconciliationStatusError(ctx, &issuer, hydrav1alpha1.StatusInvalidHydraAddress, err); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{}, nil
+	}
+
+	trust, err := r.trustRequest(ctx, &issuer)
+	if err != nil {
+		if !isInvalidJWK(err) {
+			return ctrl.Result{}, err
+		}
+		// the Secret watch triggers a new reconciliation once it is fixed
+		if updateErr := r.updateReconciliationStatusError(ctx, &issuer, hydrav1alpha1.StatusInvalidJWK, err); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{}, nil
+	}
+
+	hash, err := requestHash(trust)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if issuer.Status.ID != "" && issuer.Status.RequestHash == hash {
+		_, found, err := trustClient.GetTrustedJwtGrantIssuer(ctx, issuer.Status.ID)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if found {
+			if issuer.Generation == issuer.Status.ObservedGeneration {
+				return ctrl.Result{}, nil
+			}
+			return ctrl.Result{}, r.ensureEmptyStatusError(ctx, &issuer, issuer.Status.ID, hash)
+		}
+	}
+
+	// trust relationships cannot be updated, so they are created again. ORY
+	// Hydra refuses two relationships of the same issuer, subject and key, so
+	// the previous one is deleted first, and forgotten once deleted so that
+	// a failed creation is retried rather than taken for the relationship.
+	if issuer.Status.ID != "" {
+		if err := trustClient.DeleteTrustedJwtGrantIssuer(ctx, issuer.Status.ID); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.forgetTrust(ctx, &issuer); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	created, err := trustClient.CreateTrustedJwtGrantIssuer(ctx, trust)
+	if err != nil {
+		if updateErr := r.updateReconciliationStatusError(ctx, &issuer, hydrav1alpha1.StatusTrustFailed, err); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		if isTransient(err) {
+			// requeue with the backoff of the controller
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	return ctrl.Result{}, r.ensureEmptyStatusError(ctx, &issuer, created.ID, hash)
+}
+
+func (r *TrustedJwtGrantIssuerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&hydrav1alpha1.TrustedJwtGrantIssuer{}).
+		Watches(&apiv1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.issuersForSecret)).
+		Complete(r)
+}
+
+// issuersForSecret maps a Secret to the issuers taking their JSON Web Key
+// from it.
+func (r *TrustedJwtGrantIssuerReconciler) issuersForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	var issuers hydrav1alpha1.TrustedJwtGrantIssuerList
+	if err := r.List(ctx, &issuers); err != nil {
+		r.Log.Error(err, "unable to list trusted jwt grant issuers", "secret", client.ObjectKeyFromObject(secret))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, issuer := range issuers.Items {
+		if ref := issuer.Spec.JWKSecretRef; ref != nil && ref.Name == secret.GetName() && ref.Namespace == secret.GetNamespace() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: issuer.Name}})
+		}
+	}
+	return requests
+}
+
+// invalidJWKError is returned when the JSON Web Key of an issuer cannot be
+// read.
+type invalidJWKError struct {
+	err error
+}
+
+func (e *invalidJWKError) Error() string {
+	return e.err.Error()
+}
+
+func (e *invalidJWKError) Unwrap() error {
+	return e.err
+}
+
+func isInvalidJWK(err error) bool {
+	var invalidErr *invalidJWKError
+	return errors.As(err, &invalidErr)
+}
+
+// trustRequest returns the trust relationship to create in ORY Hydra for the
+// issuer.
+func (r *TrustedJwtGrantIssuerReconciler) trustRequest(ctx context.Context, issuer *hydrav1alpha1.TrustedJwtGrantIssuer) (*hydra.TrustJwtGrantIssuerJSON, error) {
+	spec := issuer.Spec
+
+	var raw []byte
+	switch {
+	case spec.JWK != nil:
+		raw = spec.JWK.Raw
+	case spec.JWKSecretRef != nil:
+		ref := spec.JWKSecretRef
+		var secret apiv1.Secret
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ref.Namespace}, &secret); err != nil {
+			if apierrs.IsNotFound(err) {
+				return nil, &invalidJWKError{fmt.Errorf("secret %s/%s does not exist", ref.Namespace, ref.Name)}
+			}
+			return nil, err
+		}
+		var ok bool
+		if raw, ok = secret.Data[ref.Key]; !ok {
+			return nil, &invalidJWKError{fmt.Errorf("secret %s/%s has no key %s", ref.Namespace, ref.Name, ref.Key)}
+		}
+	default:
+		return nil, &invalidJWKError{errors.New("neither jwk nor jwkSecretRef is set")}
+	}
+
+	var jwk hydra.JSONWebKey
+	if err := json.Unmarshal(raw, &jwk); err != nil {
+		return nil, &invalidJWKError{fmt.Errorf("invalid JSON Web Key: %w", err)}
+	}
+	if jwk.KeyID() == "" {
+		return nil, &invalidJWKError{errors.New("invalid JSON Web Key: kid is missing")}
+	}
+	public := jwk.Public()
+	if public == nil {
+		return nil, &invalidJWKError{errors.New("invalid JSON Web Key: symmetric keys cannot be trusted")}
+	}
+
+	scope := spec.Scope
+	if scope == nil {
+		scope = []string{}
+	}
+
+	return &hydra.TrustJwtGrantIssuerJSON{
+		Issuer:          spec.Issuer,
+		Subject:         spec.Subject,
+		AllowAnySubject: spec.AllowAnySubject,
+		Scope:           scope,
+		ExpiresAt:       spec.ExpiresAt.UTC(),
+		JWK:             public,
+	}, nil
+}
+
+func (r *TrustedJwtGrantIssuerReconciler) updateReconciliationStatusError(ctx context.Context, i *hydrav1alpha1.TrustedJwtGrantIssuer, code hydrav1alpha1.StatusCode, err error) error {
+	ctrl.LoggerFrom(ctx).Error(err, "error processing trusted jwt grant issuer", "code", code)
+
+	_, err = controllerutil.CreateOrPatch(ctx, r.Client, i, func() error {
+		i.Status.ObservedGeneration = i.Generation
+		i.Status.ReconciliationError = hydrav1alpha1.ReconciliationError{
+			Code:        code,
+			Description: err.Error(),
+		}
+		i.Status.Conditions = []hydrav1alpha1.OAuth2ClientCondition{
+			{
+				Type:   hydrav1alpha1.OAuth2ClientConditionReady,
+				Status: hydrav1alpha1.ConditionFalse,
+			},
+		}
+
+		return nil
+	})
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "status update failed")
+	}
+
+	return err
+}
+
+// forgetTrust clears the trust relationship deleted from ORY Hydra from the
+// status of the issuer.
+func (r *TrustedJwtGrantIssuerReconciler) forgetTrust(ctx context.Context, i *hydrav1alpha1.TrustedJwtGrantIssuer) error {
+	_, err := controllerutil.CreateOrPatch(ctx, r.Client, i, func() error {
+		i.Status.ID = ""
+		i.Status.RequestHash = ""
+		return nil
+	})
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "status update failed")
+	}
+
+	return err
+}
+
+func (r *TrustedJwtGrantIssuerReconciler) ensureEmptyStatusError(ctx context.Context, i *hydrav1alpha1.TrustedJwtGrantIssuer, id, hash string) error {
+	_, err := controllerutil.CreateOrPatch(ctx, r.Client, i, func() error {
+		i.Status.ObservedGeneration = i.Generation
+		i.Status.ReconciliationError = hydrav1alpha1.ReconciliationError{}
+		i.Status.ID = id
+		i.Status.RequestHash = hash
+		i.Status.Conditions = []hydrav1alpha1.OAuth2ClientCondition{
+			{
+				Type:   hydrav1alpha1.OAuth2ClientConditionReady,
+				Status: hydrav1alpha1.ConditionTrue,
+			},
+		}
+
+		return nil
+	})
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "status update failed")
+	}
+
+	return err
+}
+
+func (r *TrustedJwtGrantIssuerReconciler) getTrustClient(issuer hydrav1alpha1.TrustedJwtGrantIssuer) (hydra.TrustClient, error) {
+	admin := issuer.Spec.HydraAdmin
+	if admin.URL != "" {
+		key := clientKey{
+			url:            admin.URL,
+			port:           admin.Port,
+			endpoint:       admin.Endpoint,
+			forwardedProto: admin.ForwardedProto,
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if c, ok := r.trustClients[key]; ok {
+			return c, nil
+		}
+
+		c, err := r.trustClientFactory(admin, "", false)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create trust client from CRD: %w", err)
+		}
+
+		r.trustClients[key] = c
+		return c, nil
+	}
+
+	if r.TrustClient == nil {
+		return nil, fmt.Errorf("no default client configured")
+	}
+
+	return r.TrustClient, nil
+}
+
+// requestHash returns a hash of the trust relationship created in ORY Hydra.
+func requestHash(trust *hydra.TrustJwtGrantIssuerJSON) (string, error) {
+	b, err := json.Marshal(trust)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,200 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/stretchr/testify/mock"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/controllers"
+	mocks "github.com/ory/hydra-maester/controllers/mocks/hydra"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+var _ = Describe("TrustedJwtGrantIssuer Controller", func() {
+
+	Context("in a happy-path scenario", func() {
+
+		It("trust the issuer, trust it again when its key changes and delete it", func() {
+			tstName, tstSecretName := "test-issuer", "test-issuer-key"
+			key := client.ObjectKey{Name: tstName}
+
+			s := runtime.NewScheme()
+			Expect(hydrav1alpha1.AddToScheme(s)).To(Succeed())
+			Expect(apiv1.AddToScheme(s)).To(Succeed())
+
+			mgr, err := manager.New(cfg, manager.Options{
+				Scheme: s,
+				Metrics: server.Options{
+					BindAddress: ":8095",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			c := mgr.GetClient()
+
+			var mu sync.Mutex
+			var created []*hydra.TrustJwtGrantIssuerJSON
+			var deleted []string
+			var unavailable bool
+			mtc := &mocks.TrustClient{}
+			mtc.On("GetTrustedJwtGrantIssuer", Anything, AnythingOfType("string")).Return(&hydra.TrustedJwtGrantIssuerJSON{}, true, nil)
+			mtc.On("CreateTrustedJwtGrantIssuer", Anything, AnythingOfType("*hydra.TrustJwtGrantIssuerJSON")).Return(func(_ context.Context, t *hydra.TrustJwtGrantIssuerJSON) *hydra.TrustedJwtGrantIssuerJSON {
+				mu.Lock()
+				defer mu.Unlock()
+				if unavailable {
+					return nil
+				}
+				created = append(created, t)
+				return &hydra.TrustedJwtGrantIssuerJSON{ID: fmt.Sprintf("trust-%d", len(created))}
+			}, func(context.Context, *hydra.TrustJwtGrantIssuerJSON) error {
+				mu.Lock()
+				defer mu.Unlock()
+				if unavailable {
+					// ORY Hydra is back for the retry
+					unavailable = false
+					return &hydra.StatusError{Method: "POST", StatusCode: 503, Err: errors.New("service unavailable")}
+				}
+				return nil
+			})
+			mtc.On("DeleteTrustedJwtGrantIssuer", Anything, AnythingOfType("string")).Return(func(_ context.Context, id string) error {
+				mu.Lock()
+				defer mu.Unlock()
+				deleted = append(deleted, id)
+				return nil
+			})
+
+			recFn, requests := SetupTestReconcile(getTrustReconciler(mgr, mtc))
+			Expect(addTrustController(mgr, recFn)).To(Succeed())
+			go func() {
+				for range requests {
+				}
+			}()
+
+			stopMgr := StartTestManager(mgr)
+
+			instance := &hydrav1alpha1.TrustedJwtGrantIssuer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: tstName,
+				},
+				Spec: hydrav1alpha1.TrustedJwtGrantIssuerSpec{
+					Issuer:    "https://issuer",
+					Subject:   "alice",
+					Scope:     []string{"read"},
+					ExpiresAt: metav1.NewTime(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)),
+					JWKSecretRef: &hydrav1alpha1.NamespacedSecretKeySelector{
+						SecretKeySelector: apiv1.SecretKeySelector{
+							LocalObjectReference: apiv1.LocalObjectReference{Name: tstSecretName},
+							Key:                  "jwk.json",
+						},
+						Namespace: tstNamespace,
+					},
+				},
+			}
+			Expect(c.Create(context.TODO(), instance)).To(Succeed())
+
+			// The Secret does not exist yet
+			var retrieved hydrav1alpha1.TrustedJwtGrantIssuer
+			Eventually(func() hydrav1alpha1.StatusCode {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.ReconciliationError.Code
+			}, timeout).Should(Equal(hydrav1alpha1.StatusInvalidJWK))
+
+			secret := &apiv1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: tstSecretName, Namespace: tstNamespace},
+				Data: map[string][]byte{
+					"jwk.json": []byte(`{"kty":"RSA","kid":"key-1","n":"modulus","e":"AQAB","d":"private"}`),
+				},
+			}
+			Expect(c.Create(context.TODO(), secret)).To(Succeed())
+
+			Eventually(func() string {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.ID
+			}, timeout).Should(Equal("trust-1"))
+			Expect(retrieved.Status.ReconciliationError).To(BeZero())
+
+			mu.Lock()
+			Expect(created[0].Issuer).To(Equal("https://issuer"))
+			Expect(created[0].JWK.KeyID()).To(Equal("key-1"))
+			Expect(created[0].JWK).NotTo(HaveKey("d"))
+			mu.Unlock()
+
+			// Changing the key trusts the issuer again, retrying once ORY Hydra
+			// is available
+			mu.Lock()
+			unavailable = true
+			mu.Unlock()
+			secret.Data["jwk.json"] = []byte(`{"kty":"RSA","kid":"key-2","n":"modulus","e":"AQAB"}`)
+			Expect(c.Update(context.TODO(), secret)).To(Succeed())
+
+			Eventually(func() string {
+				Expect(c.Get(context.TODO(), key, &retrieved)).To(Succeed())
+				return retrieved.Status.ID
+			}, timeout).Should(Equal("trust-2"))
+
+			mu.Lock()
+			Expect(deleted).To(Equal([]string{"trust-1"}))
+			Expect(unavailable).To(BeFalse())
+			mu.Unlock()
+
+			// Deleting the resource deletes the trust relationship
+			Expect(c.Delete(context.TODO(), instance)).To(Succeed())
+			Eventually(func() []string {
+				mu.Lock()
+				defer mu.Unlock()
+				return deleted
+			}, timeout).Should(Equal([]string{"trust-1", "trust-2"}))
+
+			Expect(c.Delete(context.TODO(), secret)).To(Succeed())
+			stopMgr.Done()
+		})
+	})
+})
+
+// addTrustController adds a new TrustedJwtGrantIssuer Controller to mgr with r as the reconcile.Reconciler
+func addTrustController(mgr manager.Manager, r reconcile.Reconciler) error {
+	name := fmt.Sprintf("controller-%s", uuid.NewString())
+	c, err := controller.New(name, mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(source.Kind(mgr.GetCache(), &hydrav1alpha1.TrustedJwtGrantIssuer{}, &handler.TypedEnqueueRequestForObject[*hydrav1alpha1.TrustedJwtGrantIssuer]{}))
+	if err != nil {
+		return err
+	}
+
+	// requeue the issuers of the Secret, like the reconciler's SetupWithManager
+	return c.Watch(source.Kind(mgr.GetCache(), &apiv1.Secret{}, handler.TypedEnqueueRequestsFromMapFunc(func(ctx context.Context, secret *apiv1.Secret) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: "test-issuer"}}}
+	})))
+}
+
+func getTrustReconciler(mgr ctrl.Manager, mock hydra.TrustClient) reconcile.Reconciler {
+	return controllers.NewTrustedJwtGrantIssuerReconciler(
+		mgr.GetClient(),
+		mock,
+		ctrl.Log.WithName("controllers").WithName("TrustedJwtGrantIssuer"),
+	)
+}
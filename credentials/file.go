@@ -0,0 +1,110 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+// FileStore keeps credentials as JSON documents on the local file system,
+// in {Dir}/{namespace}/{secretName}.json. It is meant to be used with a
+// volume shared with a sidecar that syncs the files to their destination.
+type FileStore struct {
+	Dir string
+}
+
+// NewFileStore returns a new FileStore rooted at dir.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{Dir: dir}
+}
+
+func (s *FileStore) Get(_ context.Context, c *hydrav1alpha1.OAuth2Client) (*hydra.Oauth2ClientCredentials, bool, error) {
+	p, err := s.path(c)
+	if err != nil {
+		return nil, false, err
+	}
+
+	b, err := os.ReadFile(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	var doc document
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, true, invalidf("unable to decode credentials: %s", err)
+	}
+
+	credentials, err := doc.toCredentials(c)
+	if err != nil {
+		return nil, true, err
+	}
+
+	return credentials, true, nil
+}
+
+func (s *FileStore) Put(_ context.Context, c *hydrav1alpha1.OAuth2Client, credentials *hydra.Oauth2ClientCredentials) error {
+	b, err := json.Marshal(fromCredentials(credentials))
+	if err != nil {
+		return err
+	}
+
+	p, err := s.path(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so readers never observe partial content
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".credentials-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), p)
+}
+
+func (s *FileStore) Delete(_ context.Context, c *hydrav1alpha1.OAuth2Client) error {
+	p, err := s.path(c)
+	if err != nil {
+		// nothing can have been written for an invalid secret name
+		return nil
+	}
+
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path returns the path of the credentials of c. The secret name must not
+// lead out of the directory of the namespace, e.g. to the credentials of
+// another namespace.
+func (s *FileStore) path(c *hydrav1alpha1.OAuth2Client) (string, error) {
+	p := filepath.Join(s.Dir, c.Namespace, c.Spec.SecretName+".json")
+	if rel, err := filepath.Rel(s.Dir, p); err != nil || !filepath.IsLocal(rel) || filepath.Dir(rel) != c.Namespace {
+		return "", invalidf("secret name %q is not a valid file name", c.Spec.SecretName)
+	}
+	return p, nil
+}
@@ -0,0 +1,158 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package credentials
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"text/template"
+
+	"github.com/google/uuid"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+const (
+	// AlphaNumeric is the default charset of generated client secrets.
+	AlphaNumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	DefaultSecretLength = 32
+)
+
+// Generator creates client IDs and secrets for new OAuth2 clients, so that
+// they can be persisted before the client is registered in ORY Hydra.
+type Generator struct {
+	// IDTemplate is a text/template rendered with the OAuth2Client's Name
+	// and Namespace to build the client ID, e.g. "{{ .Namespace }}-{{ .Name }}".
+	// It is used for clients that do not set spec.clientId.
+	IDTemplate string
+	// IDLength is the length of generated client IDs. If zero, a UUID is
+	// used, mirroring ORY Hydra's own behavior.
+	IDLength int
+	// IDCharset is the set of characters generated client IDs are made of.
+	IDCharset string
+	// SecretLength is the length of generated client secrets.
+	SecretLength int
+	// SecretCharset is the set of characters generated client secrets are
+	// made of.
+	SecretCharset string
+}
+
+// NewGenerator returns a Generator with the default settings.
+func NewGenerator() *Generator {
+	return &Generator{
+		IDCharset:     AlphaNumeric,
+		SecretLength:  DefaultSecretLength,
+		SecretCharset: AlphaNumeric,
+	}
+}
+
+// Validate checks that the generator produces usable credentials.
+func (g *Generator) Validate() error {
+	if g.IDTemplate != "" {
+		if _, err := parseIDTemplate(g.IDTemplate); err != nil {
+			return err
+		}
+	}
+	if g.IDLength < 0 {
+		return fmt.Errorf("client ID length must not be negative")
+	}
+	if g.IDLength > 0 && len(g.IDCharset) < 2 {
+		return fmt.Errorf("client ID charset must contain at least two characters")
+	}
+	if g.SecretLength < 6 {
+		return fmt.Errorf("client secret length must be at least 6")
+	}
+	if len(g.SecretCharset) < 2 {
+		return fmt.Errorf("client secret charset must contain at least two characters")
+	}
+	return nil
+}
+
+// ClientID returns the client ID declared for the client, either through
+// spec.clientId or through the generator's IDTemplate. The returned bool is
+// false if the client ID is not declared and has to be generated.
+func (g *Generator) ClientID(c *hydrav1alpha1.OAuth2Client) (string, bool, error) {
+	text := c.Spec.ClientID
+	if text == "" {
+		text = g.IDTemplate
+	}
+	if text == "" {
+		return "", false, nil
+	}
+
+	tmpl, err := parseIDTemplate(text)
+	if err != nil {
+		return "", true, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct{ Name, Namespace string }{c.Name, c.Namespace}); err != nil {
+		return "", true, fmt.Errorf("unable to render client ID template: %w", err)
+	}
+	if buf.Len() == 0 {
+		return "", true, fmt.Errorf("client ID template %q renders an empty client ID", text)
+	}
+
+	return buf.String(), true, nil
+}
+
+// Generate returns new credentials for the client. The client ID is the
+// declared one if any. No secret is generated for clients that do not
+// authenticate at the token endpoint with a client secret.
+func (g *Generator) Generate(c *hydrav1alpha1.OAuth2Client) (*hydra.Oauth2ClientCredentials, error) {
+	id, declared, err := g.ClientID(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if !declared {
+		id = uuid.NewString()
+		if g.IDLength > 0 {
+			if id, err = randomString(g.IDLength, g.IDCharset); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	credentials := &hydra.Oauth2ClientCredentials{ID: []byte(id)}
+	if !UsesSecret(c) {
+		return credentials, nil
+	}
+
+	secret, err := randomString(g.SecretLength, g.SecretCharset)
+	if err != nil {
+		return nil, err
+	}
+	credentials.Password = []byte(secret)
+
+	return credentials, nil
+}
+
+func parseIDTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("clientId").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse client ID template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func randomString(length int, charset string) (string, error) {
+	runes := []rune(charset)
+	max := big.NewInt(int64(len(runes)))
+
+	out := make([]rune, length)
+	for i := range out {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		out[i] = runes[n.Int64()]
+	}
+
+	return string(out), nil
+}
@@ -0,0 +1,117 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package credentials_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/credentials"
+)
+
+func TestGenerator(t *testing.T) {
+	t.Run("should generate a UUID and a secret by default", func(t *testing.T) {
+		g := credentials.NewGenerator()
+		require.NoError(t, g.Validate())
+
+		creds, err := g.Generate(testClient(""))
+		require.NoError(t, err)
+
+		_, err = uuid.Parse(string(creds.ID))
+		assert.NoError(t, err)
+		assert.Len(t, creds.Password, credentials.DefaultSecretLength)
+	})
+
+	t.Run("should honor length and charset", func(t *testing.T) {
+		g := &credentials.Generator{
+			IDLength:      12,
+			IDCharset:     "ab",
+			SecretLength:  64,
+			SecretCharset: "0123456789",
+		}
+		require.NoError(t, g.Validate())
+
+		creds, err := g.Generate(testClient("client_secret_post"))
+		require.NoError(t, err)
+
+		assert.Len(t, creds.ID, 12)
+		assert.Empty(t, strings.Trim(string(creds.ID), "ab"))
+		assert.Len(t, creds.Password, 64)
+		assert.Empty(t, strings.Trim(string(creds.Password), "0123456789"))
+	})
+
+	t.Run("should not generate a secret for public clients", func(t *testing.T) {
+		creds, err := credentials.NewGenerator().Generate(testClient("none"))
+		require.NoError(t, err)
+		assert.NotEmpty(t, creds.ID)
+		assert.Nil(t, creds.Password)
+	})
+
+	t.Run("should not generate a secret for clients with generated key pairs", func(t *testing.T) {
+		c := testClient("private_key_jwt")
+		c.Spec.KeyPairGeneration = &hydrav1alpha1.KeyPairGeneration{Algorithm: "ES256"}
+
+		creds, err := credentials.NewGenerator().Generate(c)
+		require.NoError(t, err)
+		assert.NotEmpty(t, creds.ID)
+		assert.Nil(t, creds.Password)
+	})
+
+	t.Run("should use the declared client ID", func(t *testing.T) {
+		c := testClient("")
+		c.Spec.ClientID = "{{ .Namespace }}-{{ .Name }}"
+
+		creds, err := credentials.NewGenerator().Generate(c)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("team-a-test"), creds.ID)
+		assert.Len(t, creds.Password, credentials.DefaultSecretLength)
+
+		c.Spec.ClientID = "static-id"
+		id, declared, err := credentials.NewGenerator().ClientID(c)
+		require.NoError(t, err)
+		assert.True(t, declared)
+		assert.Equal(t, "static-id", id)
+	})
+
+	t.Run("should fall back to the ID template", func(t *testing.T) {
+		g := credentials.NewGenerator()
+		g.IDTemplate = "{{ .Name }}.{{ .Namespace }}"
+		require.NoError(t, g.Validate())
+
+		creds, err := g.Generate(testClient(""))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("test.team-a"), creds.ID)
+	})
+
+	t.Run("should reject invalid client ID templates", func(t *testing.T) {
+		c := testClient("")
+		c.Spec.ClientID = "{{ .Unknown }}"
+		_, err := credentials.NewGenerator().Generate(c)
+		assert.Error(t, err)
+
+		c.Spec.ClientID = "{{ if false }}{{ end }}"
+		_, err = credentials.NewGenerator().Generate(c)
+		assert.ErrorContains(t, err, "empty client ID")
+
+		g := credentials.NewGenerator()
+		g.IDTemplate = "{{ .Name"
+		assert.Error(t, g.Validate())
+	})
+
+	t.Run("should reject invalid settings", func(t *testing.T) {
+		for d, g := range map[string]*credentials.Generator{
+			"short secret":     {SecretLength: 4, SecretCharset: credentials.AlphaNumeric},
+			"empty charset":    {SecretLength: 32},
+			"negative id":      {IDLength: -1, SecretLength: 32, SecretCharset: credentials.AlphaNumeric},
+			"empty id charset": {IDLength: 8, SecretLength: 32, SecretCharset: credentials.AlphaNumeric},
+		} {
+			assert.Error(t, g.Validate(), d)
+		}
+	})
+}
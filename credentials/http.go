@@ -0,0 +1,152 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+// HTTPStore keeps credentials in a generic HTTP key-value store. Credentials
+// of a client are read with GET and written with PUT on
+// {URL}/{namespace}/{secretName} as a JSON document with the client_id and
+// client_secret properties.
+type HTTPStore struct {
+	URL        url.URL
+	HTTPClient *http.Client
+	// Token, if set, is sent as a bearer token with every request.
+	Token string
+}
+
+// NewHTTPStore returns a new HTTPStore for the given base URL.
+func NewHTTPStore(baseURL string, httpClient *http.Client, token string) (*HTTPStore, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HTTPStore{
+		URL:        *u,
+		HTTPClient: httpClient,
+		Token:      token,
+	}, nil
+}
+
+func (s *HTTPStore) Get(ctx context.Context, c *hydrav1alpha1.OAuth2Client) (*hydra.Oauth2ClientCredentials, bool, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, c, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, false, nil
+	default:
+		return nil, false, fmt.Errorf("%s %s http request returned unexpected status code %s", req.Method, req.URL.String(), resp.Status)
+	}
+
+	var doc document
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, true, invalidf("unable to decode credentials: %s", err)
+	}
+
+	credentials, err := doc.toCredentials(c)
+	if err != nil {
+		return nil, true, err
+	}
+
+	return credentials, true, nil
+}
+
+func (s *HTTPStore) Put(ctx context.Context, c *hydrav1alpha1.OAuth2Client, credentials *hydra.Oauth2ClientCredentials) error {
+	req, err := s.newRequest(ctx, http.MethodPut, c, fromCredentials(credentials))
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	default:
+		return fmt.Errorf("%s %s http request returned unexpected status code %s", req.Method, req.URL.String(), resp.Status)
+	}
+}
+
+func (s *HTTPStore) Delete(ctx context.Context, c *hydrav1alpha1.OAuth2Client) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, c, nil)
+	if err != nil {
+		// nothing can have been written for an invalid secret name
+		if IsInvalid(err) {
+			return nil
+		}
+		return err
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("%s %s http request returned unexpected status code %s", req.Method, req.URL.String(), resp.Status)
+	}
+}
+
+func (s *HTTPStore) newRequest(ctx context.Context, method string, c *hydrav1alpha1.OAuth2Client, body interface{}) (*http.Request, error) {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return nil, err
+		}
+	}
+
+	// the secret name must not lead out of the path of the namespace, e.g. to
+	// the credentials of another namespace
+	u := s.URL
+	dir := path.Join(u.Path, c.Namespace)
+	u.Path = path.Join(dir, c.Spec.SecretName)
+	if c.Spec.SecretName == "" || path.Dir(u.Path) != dir {
+		return nil, invalidf("secret name %q is not a valid path element", c.Spec.SecretName)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), &buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,164 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package credentials
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"github.com/ory/hydra-maester/hydra"
+)
+
+// RSAKeySize is the size of generated RSA keys.
+const RSAKeySize = 2048
+
+// KeyPair is a key pair a client authenticates with using the
+// private_key_jwt method.
+type KeyPair struct {
+	// KeyID is the RFC 7638 thumbprint of the public key.
+	KeyID string
+	// PrivateKey is the PEM encoded PKCS #8 private key.
+	PrivateKey []byte
+	// PublicKey is the public key as a JSON Web Key.
+	PublicKey hydra.JSONWebKey
+}
+
+// GenerateKeyPair returns a new key pair for the given JWS algorithm, one of
+// RS256, ES256 and EdDSA.
+func GenerateKeyPair(alg string) (*KeyPair, error) {
+	var key crypto.Signer
+	var err error
+	switch alg {
+	case "RS256":
+		key, err = rsa.GenerateKey(rand.Reader, RSAKeySize)
+	case "ES256":
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "EdDSA":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported key pair algorithm %q", alg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate %s key pair: %w", alg, err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return newKeyPair(key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), alg)
+}
+
+// ParseKeyPair parses a PEM encoded PKCS #8 private key generated for the
+// given algorithm. An error is returned if the key does not match it.
+func ParseKeyPair(privateKey []byte, alg string) (*KeyPair, error) {
+	block, _ := pem.Decode(privateKey)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("private key is not a PEM encoded PKCS #8 key")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse private key: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("private key of type %T can not sign", key)
+	}
+
+	return newKeyPair(signer, privateKey, alg)
+}
+
+func newKeyPair(key crypto.Signer, privateKey []byte, alg string) (*KeyPair, error) {
+	var jwk hydra.JSONWebKey
+	switch pub := key.Public().(type) {
+	case *rsa.PublicKey:
+		if alg != "RS256" {
+			break
+		}
+		jwk = hydra.JSONWebKey{
+			"kty": "RSA",
+			"n":   encode(pub.N.Bytes()),
+			"e":   encode(big.NewInt(int64(pub.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		if alg != "ES256" || pub.Curve != elliptic.P256() {
+			break
+		}
+		b, err := pub.ECDH()
+		if err != nil {
+			return nil, err
+		}
+		// the uncompressed point is 0x04 || x || y
+		point := b.Bytes()
+		jwk = hydra.JSONWebKey{
+			"kty": "EC",
+			"crv": "P-256",
+			"x":   encode(point[1:33]),
+			"y":   encode(point[33:]),
+		}
+	case ed25519.PublicKey:
+		if alg != "EdDSA" {
+			break
+		}
+		jwk = hydra.JSONWebKey{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"x":   encode(pub),
+		}
+	}
+	if jwk == nil {
+		return nil, fmt.Errorf("private key of type %T does not match algorithm %q", key, alg)
+	}
+
+	kid, err := thumbprint(jwk)
+	if err != nil {
+		return nil, err
+	}
+	jwk["kid"] = kid
+	jwk["alg"] = alg
+	jwk["use"] = "sig"
+
+	return &KeyPair{
+		KeyID:      kid,
+		PrivateKey: privateKey,
+		PublicKey:  jwk,
+	}, nil
+}
+
+// thumbprint returns the RFC 7638 thumbprint of the key, computed over its
+// required members only. They are marshaled in lexicographic order.
+func thumbprint(jwk hydra.JSONWebKey) (string, error) {
+	required := map[string]interface{}{}
+	for _, name := range []string{"kty", "crv", "e", "n", "x", "y"} {
+		if value, ok := jwk[name]; ok {
+			required[name] = value
+		}
+	}
+
+	b, err := json.Marshal(required)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return encode(sum[:]), nil
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,59 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package credentials_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/hydra-maester/credentials"
+)
+
+func TestKeyPair(t *testing.T) {
+	for alg, params := range map[string][]string{
+		"RS256": {"kty", "n", "e"},
+		"ES256": {"kty", "crv", "x", "y"},
+		"EdDSA": {"kty", "crv", "x"},
+	} {
+		t.Run("alg="+alg, func(t *testing.T) {
+			kp, err := credentials.GenerateKeyPair(alg)
+			require.NoError(t, err)
+
+			assert.NotEmpty(t, kp.KeyID)
+			assert.Equal(t, kp.KeyID, kp.PublicKey.KeyID())
+			assert.Equal(t, alg, kp.PublicKey.Algorithm())
+			assert.Equal(t, "sig", kp.PublicKey.Use())
+			for _, name := range params {
+				assert.NotEmpty(t, kp.PublicKey[name], name)
+			}
+			assert.NotContains(t, kp.PublicKey, "d")
+
+			parsed, err := credentials.ParseKeyPair(kp.PrivateKey, alg)
+			require.NoError(t, err)
+			assert.Equal(t, kp.KeyID, parsed.KeyID)
+			assert.Equal(t, kp.PublicKey, parsed.PublicKey)
+		})
+	}
+
+	t.Run("should reject a key of another algorithm", func(t *testing.T) {
+		kp, err := credentials.GenerateKeyPair("ES256")
+		require.NoError(t, err)
+
+		_, err = credentials.ParseKeyPair(kp.PrivateKey, "RS256")
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject an invalid key", func(t *testing.T) {
+		_, err := credentials.ParseKeyPair([]byte("not a key"), "RS256")
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject an unsupported algorithm", func(t *testing.T) {
+		_, err := credentials.GenerateKeyPair("HS256")
+		assert.Error(t, err)
+	})
+
+}
@@ -0,0 +1,118 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package credentials
+
+import (
+	"context"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+// SecretStore keeps credentials in a Kubernetes Secret named after the
+// OAuth2Client's SecretName, in the namespace of the OAuth2Client.
+type SecretStore struct {
+	Client    client.Client
+	IDKey     string
+	SecretKey string
+}
+
+// NewSecretStore returns a new SecretStore using the given data keys.
+func NewSecretStore(c client.Client, idKey, secretKey string) *SecretStore {
+	return &SecretStore{
+		Client:    c,
+		IDKey:     idKey,
+		SecretKey: secretKey,
+	}
+}
+
+func (s *SecretStore) Get(ctx context.Context, c *hydrav1alpha1.OAuth2Client) (*hydra.Oauth2ClientCredentials, bool, error) {
+	var secret apiv1.Secret
+	if err := s.Client.Get(ctx, types.NamespacedName{Name: c.Spec.SecretName, Namespace: c.Namespace}, &secret); err != nil {
+		if apierrs.IsNotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	credentials, err := s.parseSecret(secret, c)
+	if err != nil {
+		return nil, true, err
+	}
+
+	return credentials, true, nil
+}
+
+func (s *SecretStore) Put(ctx context.Context, c *hydrav1alpha1.OAuth2Client, credentials *hydra.Oauth2ClientCredentials) error {
+	clientSecret := apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.Spec.SecretName,
+			Namespace: c.Namespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: c.TypeMeta.APIVersion,
+				Kind:       c.TypeMeta.Kind,
+				Name:       c.ObjectMeta.Name,
+				UID:        c.ObjectMeta.UID,
+			}},
+		},
+		Data: map[string][]byte{
+			s.IDKey: credentials.ID,
+		},
+	}
+
+	if credentials.Password != nil {
+		clientSecret.Data[s.SecretKey] = credentials.Password
+	}
+
+	err := s.Client.Create(ctx, &clientSecret)
+	if !apierrs.IsAlreadyExists(err) {
+		return err
+	}
+
+	var existing apiv1.Secret
+	if err := s.Client.Get(ctx, client.ObjectKeyFromObject(&clientSecret), &existing); err != nil {
+		return err
+	}
+
+	// keep the other keys of the Secret, e.g. a generated private key
+	if existing.Data == nil {
+		existing.Data = map[string][]byte{}
+	}
+	existing.Data[s.IDKey] = credentials.ID
+	if credentials.Password != nil {
+		existing.Data[s.SecretKey] = credentials.Password
+	} else {
+		delete(existing.Data, s.SecretKey)
+	}
+	return s.Client.Update(ctx, &existing)
+}
+
+// Delete is a no-op, the Secret is owned by the OAuth2Client and removed by
+// the Kubernetes garbage collector.
+func (s *SecretStore) Delete(context.Context, *hydrav1alpha1.OAuth2Client) error {
+	return nil
+}
+
+func (s *SecretStore) parseSecret(secret apiv1.Secret, c *hydrav1alpha1.OAuth2Client) (*hydra.Oauth2ClientCredentials, error) {
+	id, found := secret.Data[s.IDKey]
+	if !found {
+		return nil, invalidf("%s property missing", s.IDKey)
+	}
+
+	psw, found := secret.Data[s.SecretKey]
+	if !found && UsesSecret(c) {
+		return nil, invalidf("%s property missing", s.SecretKey)
+	}
+
+	return &hydra.Oauth2ClientCredentials{
+		ID:       id,
+		Password: psw,
+	}, nil
+}
@@ -0,0 +1,84 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+// InvalidError is returned by Store implementations when stored credentials
+// exist but cannot be used for the client, e.g. because a key is missing.
+type InvalidError struct {
+	Reason string
+}
+
+func (e *InvalidError) Error() string {
+	return e.Reason
+}
+
+// IsInvalid returns true if err is an InvalidError.
+func IsInvalid(err error) bool {
+	var invalid *InvalidError
+	return errors.As(err, &invalid)
+}
+
+func invalidf(format string, args ...interface{}) error {
+	return &InvalidError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// Store persists the credentials of an OAuth2 client outside of ORY Hydra.
+// The location of the credentials is derived from the OAuth2Client's
+// namespace and SecretName.
+type Store interface {
+	// Get returns the credentials stored for the client. The returned bool is
+	// false if no credentials have been stored yet.
+	Get(ctx context.Context, c *hydrav1alpha1.OAuth2Client) (*hydra.Oauth2ClientCredentials, bool, error)
+	// Put stores the credentials for the client, replacing existing ones.
+	Put(ctx context.Context, c *hydrav1alpha1.OAuth2Client, credentials *hydra.Oauth2ClientCredentials) error
+	// Delete removes the credentials stored for the client, if any.
+	Delete(ctx context.Context, c *hydrav1alpha1.OAuth2Client) error
+}
+
+// document is the serialized form of the credentials used by the HTTP and
+// file backends.
+type document struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// UsesSecret returns true if the client authenticates at the token endpoint
+// with a client secret. Clients using generated key pairs and public clients
+// do not.
+func UsesSecret(c *hydrav1alpha1.OAuth2Client) bool {
+	return c.Spec.TokenEndpointAuthMethod != "none" && c.Spec.KeyPairGeneration == nil
+}
+
+func (d *document) toCredentials(c *hydrav1alpha1.OAuth2Client) (*hydra.Oauth2ClientCredentials, error) {
+	if d.ClientID == "" {
+		return nil, invalidf("client_id property missing")
+	}
+
+	credentials := &hydra.Oauth2ClientCredentials{ID: []byte(d.ClientID)}
+	if d.ClientSecret == "" {
+		if UsesSecret(c) {
+			return nil, invalidf("client_secret property missing")
+		}
+		return credentials, nil
+	}
+
+	credentials.Password = []byte(d.ClientSecret)
+	return credentials, nil
+}
+
+func fromCredentials(credentials *hydra.Oauth2ClientCredentials) *document {
+	return &document{
+		ClientID:     string(credentials.ID),
+		ClientSecret: string(credentials.Password),
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package credentials_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/credentials"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+func testClient(authMethod hydrav1alpha1.TokenEndpointAuthMethod) *hydrav1alpha1.OAuth2Client {
+	return &hydrav1alpha1.OAuth2Client{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "team-a",
+		},
+		Spec: hydrav1alpha1.OAuth2ClientSpec{
+			SecretName:              "my-secret",
+			TokenEndpointAuthMethod: authMethod,
+		},
+	}
+}
+
+func TestHTTPStore(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		data = map[string][]byte{}
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+		switch req.Method {
+		case http.MethodGet:
+			b, ok := data[req.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(b)
+		case http.MethodPut:
+			var doc map[string]string
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&doc))
+			data[req.URL.Path], _ = json.Marshal(doc)
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodDelete:
+			delete(data, req.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer srv.Close()
+
+	store, err := credentials.NewHTTPStore(srv.URL+"/v1/oauth2", srv.Client(), "token")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	c := testClient("")
+
+	t.Run("should not find missing credentials", func(t *testing.T) {
+		_, found, err := store.Get(ctx, c)
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("should store and read credentials", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, c, &hydra.Oauth2ClientCredentials{ID: []byte("id"), Password: []byte("secret")}))
+		assert.Contains(t, data, "/v1/oauth2/team-a/my-secret")
+
+		creds, found, err := store.Get(ctx, c)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, []byte("id"), creds.ID)
+		assert.Equal(t, []byte("secret"), creds.Password)
+	})
+
+	t.Run("should reject credentials without a secret", func(t *testing.T) {
+		data["/v1/oauth2/team-a/my-secret"] = []byte(`{"client_id":"id"}`)
+
+		_, found, err := store.Get(ctx, c)
+		assert.True(t, found)
+		assert.True(t, credentials.IsInvalid(err))
+		assert.EqualError(t, err, "client_secret property missing")
+
+		creds, _, err := store.Get(ctx, testClient("none"))
+		require.NoError(t, err)
+		assert.Nil(t, creds.Password)
+	})
+
+	t.Run("should delete credentials", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, c))
+		_, found, err := store.Get(ctx, c)
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("should reject secret names leading out of the namespace", func(t *testing.T) {
+		data["/v1/oauth2/team-b/my-secret"] = []byte(`{"client_id":"id","client_secret":"secret"}`)
+
+		for _, name := range []string{"../team-b/my-secret", "..", "a/b", ""} {
+			traversal := testClient("")
+			traversal.Spec.SecretName = name
+
+			_, _, err := store.Get(ctx, traversal)
+			assert.True(t, credentials.IsInvalid(err), name)
+			assert.True(t, credentials.IsInvalid(store.Put(ctx, traversal, &hydra.Oauth2ClientCredentials{ID: []byte("id")})), name)
+			assert.NoError(t, store.Delete(ctx, traversal), name)
+		}
+		assert.Contains(t, data, "/v1/oauth2/team-b/my-secret")
+	})
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store := credentials.NewFileStore(dir)
+	ctx := context.Background()
+	c := testClient("")
+
+	t.Run("should not find missing credentials", func(t *testing.T) {
+		_, found, err := store.Get(ctx, c)
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("should store and read credentials", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, c, &hydra.Oauth2ClientCredentials{ID: []byte("id"), Password: []byte("secret")}))
+
+		info, err := os.Stat(filepath.Join(dir, "team-a", "my-secret.json"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+		creds, found, err := store.Get(ctx, c)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, []byte("id"), creds.ID)
+		assert.Equal(t, []byte("secret"), creds.Password)
+	})
+
+	t.Run("should reject malformed credentials", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "team-a", "my-secret.json"), []byte("{"), 0o600))
+
+		_, found, err := store.Get(ctx, c)
+		assert.True(t, found)
+		assert.True(t, credentials.IsInvalid(err))
+	})
+
+	t.Run("should delete credentials", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, c))
+		require.NoError(t, store.Delete(ctx, c))
+		_, found, err := store.Get(ctx, c)
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("should reject secret names leading out of the namespace", func(t *testing.T) {
+		other := filepath.Join(dir, "team-b", "my-secret.json")
+		require.NoError(t, os.MkdirAll(filepath.Dir(other), 0o700))
+		require.NoError(t, os.WriteFile(other, []byte(`{"client_id":"id","client_secret":"secret"}`), 0o600))
+
+		for _, name := range []string{"../team-b/my-secret", "../../etc/passwd", "a/b"} {
+			traversal := testClient("")
+			traversal.Spec.SecretName = name
+
+			_, _, err := store.Get(ctx, traversal)
+			assert.True(t, credentials.IsInvalid(err), name)
+			assert.True(t, credentials.IsInvalid(store.Put(ctx, traversal, &hydra.Oauth2ClientCredentials{ID: []byte("id")})), name)
+			assert.NoError(t, store.Delete(ctx, traversal), name)
+		}
+		assert.FileExists(t, other)
+	})
+}
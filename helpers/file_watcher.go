@@ -0,0 +1,91 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package helpers
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+)
+
+// FileWatcher is a manager runnable that calls OnChange when the files that
+// Files returns change. It watches their directories rather than the files,
+// as the files mounted from ConfigMaps and Secrets are replaced by updating a
+// symlink. The changes are debounced, so that a burst of writes results in a
+// single call.
+type FileWatcher struct {
+	// Files returns the paths of the files to watch. It is called again after
+	// each change, as the files may reference other files.
+	Files func() []string
+	// OnChange is called when the files change. The manager is stopped if
+	// it fails.
+	OnChange func() error
+	// Debounce is how long the watcher waits for the changes to settle.
+	Debounce time.Duration
+	Log      logr.Logger
+}
+
+// NeedLeaderElection makes the watcher run on every replica, as each of them
+// reads the files.
+func (w *FileWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+func (w *FileWatcher) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	watched := map[string]bool{}
+	watch := func() error {
+		for _, file := range w.Files() {
+			if file == "" {
+				continue
+			}
+			dir := filepath.Dir(file)
+			if watched[dir] {
+				continue
+			}
+			if err := watcher.Add(dir); err != nil {
+				return err
+			}
+			watched[dir] = true
+		}
+		return nil
+	}
+	if err := watch(); err != nil {
+		return err
+	}
+
+	var changed <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			changed = time.After(w.Debounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			w.Log.Error(err, "error watching files")
+		case <-changed:
+			changed = nil
+			if err := w.OnChange(); err != nil {
+				return err
+			}
+			if err := watch(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package helpers_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/hydra-maester/helpers"
+)
+
+func TestFileWatcher(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(name, []byte("a"), 0o600))
+
+	var changes atomic.Int32
+	stop := errors.New("stop")
+	w := &helpers.FileWatcher{
+		Files: func() []string { return []string{name, ""} },
+		OnChange: func() error {
+			if changes.Add(1) == 2 {
+				return stop
+			}
+			return nil
+		},
+		Debounce: 50 * time.Millisecond,
+		Log:      logr.Discard(),
+	}
+	assert.False(t, w.NeedLeaderElection())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- w.Start(ctx) }()
+
+	// wait for the watcher to be started
+	time.Sleep(100 * time.Millisecond)
+
+	// a burst of writes results in a single change
+	for _, content := range []string{"b", "c", "d"} {
+		require.NoError(t, os.WriteFile(name, []byte(content), 0o600))
+	}
+	assert.Eventually(t, func() bool { return changes.Load() == 1 }, time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(1), changes.Load())
+
+	// a failed change stops the watcher
+	require.NoError(t, os.WriteFile(name, []byte("e"), 0o600))
+	select {
+	case err := <-done:
+		assert.Equal(t, stop, err)
+	case <-time.After(time.Second):
+		t.Fatal("the watcher did not stop")
+	}
+}
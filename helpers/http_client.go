@@ -4,9 +4,16 @@
 package helpers
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -14,11 +21,15 @@ import (
 	httptransport "github.com/go-openapi/runtime/client"
 )
 
+// DefaultHTTPTimeout is the timeout of the requests of the HTTP clients
+// unless configured otherwise.
+const DefaultHTTPTimeout = 5 * time.Second
+
 func CreateHttpClient(insecureSkipVerify bool, tlsTrustStore string) (*http.Client, error) {
 	setupLog := ctrl.Log.WithName("setup")
 	tr := &http.Transport{}
 	httpClient := &http.Client{
-		Timeout: 5 * time.Second,
+		Timeout: DefaultHTTPTimeout,
 	}
 	if insecureSkipVerify {
 		setupLog.Info("configuring TLS with InsecureSkipVerify")
@@ -42,3 +53,125 @@ func CreateHttpClient(insecureSkipVerify bool, tlsTrustStore string) (*http.Clie
 	}
 	return httpClient, nil
 }
+
+// HTTPClientConfig configures the requests of an HTTP client.
+type HTTPClientConfig struct {
+	// Timeout of the requests, or no timeout if zero.
+	Timeout time.Duration
+	// TLSTrustStore is the path of the PEM encoded certificates of the
+	// certificate authorities the server certificates are verified with.
+	TLSTrustStore      string
+	InsecureSkipVerify bool
+	// BearerTokenFile is the path of the file holding the bearer token sent
+	// in the Authorization header.
+	BearerTokenFile string
+	// Username and PasswordFile are the credentials of the basic
+	// authentication, the password being read from a file.
+	Username     string
+	PasswordFile string
+}
+
+// ReloadableTransport is an http.RoundTripper whose configuration can be
+// replaced while it is in use, so that the changes apply to the next requests
+// of the clients using it without recreating them.
+type ReloadableTransport struct {
+	current atomic.Pointer[configuredTransport]
+}
+
+type configuredTransport struct {
+	base          *http.Transport
+	timeout       time.Duration
+	authorization string
+}
+
+// NewReloadableTransport returns a transport configured with c.
+func NewReloadableTransport(c HTTPClientConfig) (*ReloadableTransport, error) {
+	t := &ReloadableTransport{}
+	if err := t.Reload(c); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Reload replaces the configuration of the transport with c, reading the
+// trust store and the credentials again. The configuration is left unchanged
+// if c is invalid.
+func (t *ReloadableTransport) Reload(c HTTPClientConfig) error {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	if c.TLSTrustStore != "" || c.InsecureSkipVerify {
+		base.TLSClientConfig = &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+	}
+	if c.TLSTrustStore != "" {
+		pem, err := os.ReadFile(c.TLSTrustStore)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in trust store %s", c.TLSTrustStore)
+		}
+		base.TLSClientConfig.RootCAs = pool
+	}
+
+	configured := &configuredTransport{base: base, timeout: c.Timeout}
+	switch {
+	case c.BearerTokenFile != "":
+		token, err := readCredential(c.BearerTokenFile)
+		if err != nil {
+			return err
+		}
+		configured.authorization = "Bearer " + token
+	case c.Username != "":
+		password, err := readCredential(c.PasswordFile)
+		if err != nil {
+			return err
+		}
+		configured.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(c.Username+":"+password))
+	}
+
+	if previous := t.current.Swap(configured); previous != nil {
+		previous.base.CloseIdleConnections()
+	}
+	return nil
+}
+
+// RoundTrip sends req with the current configuration of the transport.
+func (t *ReloadableTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c := t.current.Load()
+
+	if c.authorization != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", c.authorization)
+	}
+	if c.timeout <= 0 {
+		return c.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), c.timeout)
+	resp, err := c.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// the timeout covers reading the body, as the one of http.Client
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+func readCredential(name string) (string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -4,11 +4,18 @@
 package helpers_test
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ory/hydra-maester/helpers"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -41,3 +48,67 @@ func TestCreateHttpClient(t *testing.T) {
 		require.Nil(t, err)
 	})
 }
+
+func TestReloadableTransport(t *testing.T) {
+	var authorization string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		authorization = req.Header.Get("Authorization")
+		if req.URL.Path == "/slow" {
+			select {
+			case <-time.After(time.Second):
+			case <-req.Context().Done():
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer s.Close()
+
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token\n"), 0o600))
+	require.NoError(t, os.WriteFile(passwordFile, []byte("password"), 0o600))
+
+	transport, err := helpers.NewReloadableTransport(helpers.HTTPClientConfig{BearerTokenFile: tokenFile})
+	require.NoError(t, err)
+	c := &http.Client{Transport: transport}
+
+	get := func(path string) error {
+		resp, err := c.Get(s.URL + path)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	t.Run("case=sends the credentials", func(t *testing.T) {
+		require.NoError(t, get("/"))
+		assert.Equal(t, "Bearer token", authorization)
+
+		require.NoError(t, transport.Reload(helpers.HTTPClientConfig{Username: "admin", PasswordFile: passwordFile}))
+		require.NoError(t, get("/"))
+		assert.Equal(t, "Basic YWRtaW46cGFzc3dvcmQ=", authorization)
+
+		require.NoError(t, transport.Reload(helpers.HTTPClientConfig{}))
+		require.NoError(t, get("/"))
+		assert.Empty(t, authorization)
+	})
+
+	t.Run("case=applies the timeout", func(t *testing.T) {
+		require.NoError(t, transport.Reload(helpers.HTTPClientConfig{Timeout: 50 * time.Millisecond}))
+		err := get("/slow")
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		require.NoError(t, get("/"))
+	})
+
+	t.Run("case=keeps the configuration if the new one is invalid", func(t *testing.T) {
+		require.NoError(t, transport.Reload(helpers.HTTPClientConfig{BearerTokenFile: tokenFile}))
+		require.Error(t, transport.Reload(helpers.HTTPClientConfig{TLSTrustStore: passwordFile}))
+		require.Error(t, transport.Reload(helpers.HTTPClientConfig{BearerTokenFile: "/does/not/exist"}))
+
+		require.NoError(t, get("/"))
+		assert.Equal(t, "Bearer token", authorization)
+	})
+}
@@ -0,0 +1,59 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package helpers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"go.uber.org/zap/zapcore"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+const (
+	// LogFormatJSON logs a JSON object per line.
+	LogFormatJSON = "json"
+	// LogFormatConsole logs human-readable lines.
+	LogFormatConsole = "console"
+)
+
+// NewLogger returns a logger writing in the given format (json or console)
+// the lines of at least the given level: debug, info or error, or a verbosity
+// such as 2 to also log the lines of logr's V(2).
+func NewLogger(format, level string) (logr.Logger, error) {
+	var encoder zap.Opts
+	switch format {
+	case LogFormatJSON:
+		encoder = zap.JSONEncoder()
+	case LogFormatConsole:
+		encoder = zap.ConsoleEncoder()
+	default:
+		return logr.Logger{}, fmt.Errorf("invalid log format %q, expected %s or %s", format, LogFormatJSON, LogFormatConsole)
+	}
+
+	lvl, err := parseLogLevel(level)
+	if err != nil {
+		return logr.Logger{}, err
+	}
+
+	return zap.New(encoder, zap.Level(lvl)), nil
+}
+
+// parseLogLevel parses a zap level name, or a logr verbosity, which is the
+// opposite of a zap level.
+func parseLogLevel(level string) (zapcore.Level, error) {
+	if v, err := strconv.Atoi(level); err == nil {
+		if v < 0 {
+			return 0, fmt.Errorf("invalid log level %q, a verbosity can't be negative", level)
+		}
+		return zapcore.Level(-v), nil
+	}
+
+	lvl, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return 0, fmt.Errorf("invalid log level %q, expected debug, info, error or a verbosity", level)
+	}
+	return lvl, nil
+}
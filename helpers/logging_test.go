@@ -0,0 +1,48 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package helpers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/hydra-maester/helpers"
+)
+
+func TestNewLogger(t *testing.T) {
+	t.Run("should create a logger of the given level", func(t *testing.T) {
+		for level, enabled := range map[string][]bool{
+			"error": {false, false, false},
+			"info":  {true, false, false},
+			"debug": {true, true, false},
+			"0":     {true, false, false},
+			"2":     {true, true, true},
+		} {
+			log, err := helpers.NewLogger(helpers.LogFormatJSON, level)
+			require.NoError(t, err, level)
+			for v, e := range enabled {
+				assert.Equal(t, e, log.V(v).Enabled(), "level %s, V(%d)", level, v)
+			}
+		}
+	})
+
+	t.Run("should create a console logger", func(t *testing.T) {
+		_, err := helpers.NewLogger(helpers.LogFormatConsole, "info")
+		require.NoError(t, err)
+	})
+
+	t.Run("should not create a logger of an invalid format", func(t *testing.T) {
+		_, err := helpers.NewLogger("text", "info")
+		require.Error(t, err)
+	})
+
+	t.Run("should not create a logger of an invalid level", func(t *testing.T) {
+		for _, level := range []string{"verbose", "-1"} {
+			_, err := helpers.NewLogger(helpers.LogFormatJSON, level)
+			require.Error(t, err, level)
+		}
+	})
+}
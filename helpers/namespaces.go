@@ -0,0 +1,111 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// ErrRestartRequired is wrapped by the errors of the runnables that stop the
+// manager because a setting it was started with changed. The process is then
+// expected to exit cleanly and be restarted.
+var ErrRestartRequired = errors.New("restart required")
+
+// ParseNamespaces splits a comma separated list of namespaces, ignoring
+// empty entries and duplicates.
+func ParseNamespaces(list string) []string {
+	var namespaces []string
+	for _, ns := range strings.Split(list, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns != "" && !slices.Contains(namespaces, ns) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// SelectNamespaces returns the sorted names of the namespaces matching selector.
+func SelectNamespaces(ctx context.Context, c client.Reader, selector labels.Selector) ([]string, error) {
+	var list apiv1.NamespaceList
+	if err := c.List(ctx, &list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("unable to list namespaces: %w", err)
+	}
+
+	namespaces := make([]string, 0, len(list.Items))
+	for _, ns := range list.Items {
+		namespaces = append(namespaces, ns.Name)
+	}
+	slices.Sort(namespaces)
+	return namespaces, nil
+}
+
+// CacheNamespaces returns the cache configuration restricting the cache to
+// the given namespaces, or nil to watch all namespaces.
+func CacheNamespaces(namespaces []string) map[string]cache.Config {
+	if len(namespaces) == 0 {
+		return nil
+	}
+
+	config := make(map[string]cache.Config, len(namespaces))
+	for _, ns := range namespaces {
+		config[ns] = cache.Config{}
+	}
+	return config
+}
+
+// NamespaceWatcher is a manager runnable that periodically lists the
+// namespaces matching Selector and stops the manager with an error wrapping
+// ErrRestartRequired once they differ from Namespaces. The cache cannot be
+// re-scoped at runtime, so the process has to be restarted with a cache
+// scoped to the new set of namespaces.
+type NamespaceWatcher struct {
+	Client     client.Reader
+	Selector   labels.Selector
+	Namespaces []string
+	Interval   time.Duration
+	Log        logr.Logger
+}
+
+// NeedLeaderElection makes the watcher run on every replica, as each of them
+// has its own cache.
+func (w *NamespaceWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+func (w *NamespaceWatcher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			namespaces, err := SelectNamespaces(ctx, w.Client, w.Selector)
+			if err != nil {
+				// transient API errors must not restart the manager
+				w.Log.Error(err, "unable to select namespaces, keeping the current ones")
+				continue
+			}
+			if !slices.Equal(namespaces, w.Namespaces) {
+				w.Log.Info("namespaces matching the namespace selector changed, stopping to restart with the new namespaces",
+					"selector", w.Selector.String(), "namespaces", w.Namespaces, "next", namespaces)
+				return fmt.Errorf("namespaces matching selector %q changed from %v to %v: %w", w.Selector, w.Namespaces, namespaces, ErrRestartRequired)
+			}
+		}
+	}
+}
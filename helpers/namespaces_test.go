@@ -0,0 +1,79 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package helpers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/ory/hydra-maester/helpers"
+)
+
+func namespace(name string, lbls map[string]string) *apiv1.Namespace {
+	return &apiv1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: lbls}}
+}
+
+func TestParseNamespaces(t *testing.T) {
+	assert.Nil(t, helpers.ParseNamespaces(""))
+	assert.Equal(t, []string{"a", "b"}, helpers.ParseNamespaces(" a,b,,a "))
+}
+
+func TestCacheNamespaces(t *testing.T) {
+	assert.Nil(t, helpers.CacheNamespaces(nil))
+	assert.Len(t, helpers.CacheNamespaces([]string{"a", "b"}), 2)
+}
+
+func TestSelectNamespaces(t *testing.T) {
+	managed := map[string]string{"hydra.ory.sh/managed": "true"}
+	c := fake.NewClientBuilder().WithObjects(
+		namespace("team-b", managed),
+		namespace("team-a", managed),
+		namespace("kube-system", nil),
+	).Build()
+
+	selector, err := labels.Parse("hydra.ory.sh/managed=true")
+	require.NoError(t, err)
+
+	namespaces, err := helpers.SelectNamespaces(context.Background(), c, selector)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"team-a", "team-b"}, namespaces)
+
+	t.Run("watcher should fail once the selected namespaces change", func(t *testing.T) {
+		w := &helpers.NamespaceWatcher{
+			Client:     c,
+			Selector:   selector,
+			Namespaces: namespaces,
+			Interval:   10 * time.Millisecond,
+		}
+		assert.False(t, w.NeedLeaderElection())
+
+		errs := make(chan error)
+		go func() { errs <- w.Start(context.Background()) }()
+
+		require.NoError(t, c.Create(context.Background(), namespace("team-c", managed)))
+
+		select {
+		case err := <-errs:
+			assert.ErrorContains(t, err, "team-c")
+			assert.ErrorIs(t, err, helpers.ErrRestartRequired)
+		case <-time.After(5 * time.Second):
+			t.Fatal("watcher did not detect the new namespace")
+		}
+	})
+
+	t.Run("watcher should stop with the context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		w := &helpers.NamespaceWatcher{Client: c, Selector: selector, Interval: time.Hour}
+		cancel()
+		assert.NoError(t, w.Start(ctx))
+	})
+}
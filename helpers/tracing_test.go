@@ -0,0 +1,61 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package helpers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/ory/hydra-maester/helpers"
+)
+
+func TestSetupTracing(t *testing.T) {
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	t.Run("should not export traces without a collector", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+		t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+		shutdown, err := helpers.SetupTracing(context.Background(), "", false)
+		require.NoError(t, err)
+		require.NoError(t, shutdown(context.Background()))
+
+		_, span := otel.Tracer("test").Start(context.Background(), "test")
+		assert.False(t, span.SpanContext().IsValid())
+	})
+
+	t.Run("should export traces to the collector", func(t *testing.T) {
+		exported := make(chan string, 1)
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			select {
+			case exported <- req.URL.Path:
+			default:
+			}
+		}))
+		defer s.Close()
+		u, _ := url.Parse(s.URL)
+
+		shutdown, err := helpers.SetupTracing(context.Background(), u.Host, true)
+		require.NoError(t, err)
+
+		_, span := otel.Tracer("test").Start(context.Background(), "test")
+		assert.True(t, span.SpanContext().IsValid())
+		span.End()
+
+		require.NoError(t, shutdown(context.Background()))
+		assert.Equal(t, "/v1/traces", <-exported)
+	})
+}
@@ -5,60 +5,109 @@ package hydra
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
 
 	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
 	"github.com/ory/hydra-maester/helpers"
 )
 
 type Client interface {
-	GetOAuth2Client(id string) (*OAuth2ClientJSON, bool, error)
-	ListOAuth2Client() ([]*OAuth2ClientJSON, error)
-	PostOAuth2Client(o *OAuth2ClientJSON) (*OAuth2ClientJSON, error)
-	PutOAuth2Client(o *OAuth2ClientJSON) (*OAuth2ClientJSON, error)
-	DeleteOAuth2Client(id string) error
+	GetOAuth2Client(ctx context.Context, id string) (*OAuth2ClientJSON, bool, error)
+	ListOAuth2Client(ctx context.Context) ([]*OAuth2ClientJSON, error)
+	PostOAuth2Client(ctx context.Context, o *OAuth2ClientJSON) (*OAuth2ClientJSON, error)
+	PutOAuth2Client(ctx context.Context, o *OAuth2ClientJSON) (*OAuth2ClientJSON, error)
+	PutOAuth2ClientLifespans(ctx context.Context, id string, l *OAuth2ClientLifespans) (*OAuth2ClientJSON, error)
+	DeleteOAuth2Client(ctx context.Context, id string) error
+	RevokeOAuth2ClientTokens(ctx context.Context, id string) error
+	Ready(ctx context.Context) error
+	Version(ctx context.Context) (string, error)
 }
 
 type InternalClient struct {
 	HydraURL       url.URL
 	HTTPClient     *http.Client
 	ForwardedProto string
+
+	// detectVersion is set for clients created with New, which query ORY
+	// Hydra's version before the first request, under basePath, the path of
+	// the admin URL. The clients endpoint is then appended to HydraURL
+	// unless hasEndpoint is set.
+	detectVersion bool
+	hasEndpoint   bool
+	basePath      string
+	version       *Version
+	versionErr    error
+	mu            sync.Mutex
 }
 
+// fallbackVersion is assumed when ORY Hydra's version cannot be detected. Its
+// endpoints, such as /clients, are the defaults from before the version was
+// detected.
+var fallbackVersion = ParseVersion("v1.0.0")
+
 // New returns a new hydra InternalClient instance.
 func New(spec hydrav1alpha1.OAuth2ClientSpec, tlsTrustStore string, insecureSkipVerify bool) (Client, error) {
-	address := fmt.Sprintf("%s:%d", spec.HydraAdmin.URL, spec.HydraAdmin.Port)
-	u, err := url.Parse(address)
+	client, err := NewInternalClient(spec.HydraAdmin, tlsTrustStore, insecureSkipVerify)
 	if err != nil {
 		return nil, err
 	}
+	return client, nil
+}
 
+// NewInternalClient returns a new hydra InternalClient instance for the given
+// admin server, which implements both Client and KeysClient.
+func NewInternalClient(admin hydrav1alpha1.HydraAdmin, tlsTrustStore string, insecureSkipVerify bool) (*InternalClient, error) {
 	c, err := helpers.CreateHttpClient(insecureSkipVerify, tlsTrustStore)
 	if err != nil {
 		return nil, err
 	}
 
+	return NewInternalClientWithHTTPClient(admin, c)
+}
+
+// NewInternalClientWithHTTPClient returns a new hydra InternalClient instance
+// for the given admin server, which sends its requests with httpClient.
+func NewInternalClientWithHTTPClient(admin hydrav1alpha1.HydraAdmin, httpClient *http.Client) (*InternalClient, error) {
+	u, err := url.Parse(admin.URL)
+	if err != nil {
+		return nil, err
+	}
+	if admin.Port != 0 {
+		// the port goes before the path of the URL, if any
+		u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(admin.Port))
+	}
+
 	client := &InternalClient{
-		HydraURL:   *u.ResolveReference(&url.URL{Path: spec.HydraAdmin.Endpoint}),
-		HTTPClient: c,
+		HydraURL:      *u.ResolveReference(&url.URL{Path: admin.Endpoint}),
+		HTTPClient:    httpClient,
+		detectVersion: true,
+		hasEndpoint:   admin.Endpoint != "",
+		basePath:      u.Path,
 	}
 
-	if spec.HydraAdmin.ForwardedProto != "" && spec.HydraAdmin.ForwardedProto != "off" {
-		client.ForwardedProto = spec.HydraAdmin.ForwardedProto
+	if admin.ForwardedProto != "" && admin.ForwardedProto != "off" {
+		client.ForwardedProto = admin.ForwardedProto
 	}
 
 	return client, nil
 }
 
-func (c *InternalClient) GetOAuth2Client(id string) (*OAuth2ClientJSON, bool, error) {
+func (c *InternalClient) GetOAuth2Client(ctx context.Context, id string) (*OAuth2ClientJSON, bool, error) {
 	var jsonClient *OAuth2ClientJSON
 
-	req, err := c.newRequest(http.MethodGet, id, nil)
+	req, err := c.newRequest(ctx, http.MethodGet, id, nil)
 	if err != nil {
 		return nil, false, err
 	}
@@ -74,35 +123,80 @@ func (c *InternalClient) GetOAuth2Client(id string) (*OAuth2ClientJSON, bool, er
 	case http.StatusNotFound, http.StatusUnauthorized:
 		return nil, false, nil
 	default:
-		return nil, false, fmt.Errorf("%s %s http request returned unexpected status code %s", req.Method, req.URL.String(), resp.Status)
+		return nil, false, statusError(req, resp, fmt.Errorf("%s %s http request returned unexpected status code %s", req.Method, req.URL.String(), resp.Status))
 	}
 }
 
-func (c *InternalClient) ListOAuth2Client() ([]*OAuth2ClientJSON, error) {
+// ListOAuth2Client returns all the clients registered in ORY Hydra, following
+// the pages of the list that ORY Hydra links in the Link header.
+func (c *InternalClient) ListOAuth2Client(ctx context.Context) ([]*OAuth2ClientJSON, error) {
 	var jsonClientList []*OAuth2ClientJSON
 
-	req, err := c.newRequest(http.MethodGet, "", nil)
+	req, err := c.newRequest(ctx, http.MethodGet, "", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.do(req, &jsonClientList)
-	if err != nil {
-		return nil, err
+	for {
+		var page []*OAuth2ClientJSON
+		resp, err := c.do(req, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, statusError(req, resp, fmt.Errorf("%s %s http request returned unexpected status code %s", req.Method, req.URL.String(), resp.Status))
+		}
+
+		if jsonClientList == nil {
+			jsonClientList = []*OAuth2ClientJSON{}
+		}
+		jsonClientList = append(jsonClientList, page...)
+
+		next := nextPage(resp)
+		if next == nil || len(page) == 0 {
+			return jsonClientList, nil
+		}
+
+		req, err = c.newRequestForURL(ctx, http.MethodGet, *req.URL.ResolveReference(next), nil)
+		if err != nil {
+			return nil, err
+		}
 	}
+}
 
-	switch resp.StatusCode {
-	case http.StatusOK:
-		return jsonClientList, nil
-	default:
-		return nil, fmt.Errorf("%s %s http request returned unexpected status code %s", req.Method, req.URL.String(), resp.Status)
+// nextPage returns the URL of the next page of a list, which ORY Hydra links
+// with the "next" relation in the Link header, or nil on the last page.
+func nextPage(resp *http.Response) *url.URL {
+	for _, header := range resp.Header.Values("Link") {
+		for _, link := range strings.Split(header, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				if strings.ReplaceAll(strings.TrimSpace(param), " ", "") != `rel="next"` {
+					continue
+				}
+				u, err := url.Parse(strings.Trim(target, "<>"))
+				if err != nil {
+					return nil
+				}
+				return u
+			}
+		}
 	}
+	return nil
 }
 
-func (c *InternalClient) PostOAuth2Client(o *OAuth2ClientJSON) (*OAuth2ClientJSON, error) {
+func (c *InternalClient) PostOAuth2Client(ctx context.Context, o *OAuth2ClientJSON) (*OAuth2ClientJSON, error) {
 	var jsonClient *OAuth2ClientJSON
 
-	req, err := c.newRequest(http.MethodPost, "", o)
+	if err := c.checkSupported(ctx, o); err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, "", o)
 	if err != nil {
 		return nil, err
 	}
@@ -116,16 +210,20 @@ func (c *InternalClient) PostOAuth2Client(o *OAuth2ClientJSON) (*OAuth2ClientJSO
 	case http.StatusCreated:
 		return jsonClient, nil
 	case http.StatusConflict:
-		return nil, fmt.Errorf("%s %s http request failed: requested ID already exists", req.Method, req.URL)
+		return nil, statusError(req, resp, fmt.Errorf("%s %s http request failed: requested ID already exists", req.Method, req.URL))
 	default:
-		return nil, fmt.Errorf("%s %s http request returned unexpected status code: %s", req.Method, req.URL, resp.Status)
+		return nil, statusError(req, resp, fmt.Errorf("%s %s http request returned unexpected status code: %s", req.Method, req.URL, resp.Status))
 	}
 }
 
-func (c *InternalClient) PutOAuth2Client(o *OAuth2ClientJSON) (*OAuth2ClientJSON, error) {
+func (c *InternalClient) PutOAuth2Client(ctx context.Context, o *OAuth2ClientJSON) (*OAuth2ClientJSON, error) {
 	var jsonClient *OAuth2ClientJSON
 
-	req, err := c.newRequest(http.MethodPut, *o.ClientID, o)
+	if err := c.checkSupported(ctx, o); err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPut, *o.ClientID, o)
 	if err != nil {
 		return nil, err
 	}
@@ -136,14 +234,38 @@ func (c *InternalClient) PutOAuth2Client(o *OAuth2ClientJSON) (*OAuth2ClientJSON
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%s %s http request returned unexpected status code: %s", req.Method, req.URL, resp.Status)
+		return nil, statusError(req, resp, fmt.Errorf("%s %s http request returned unexpected status code: %s", req.Method, req.URL, resp.Status))
 	}
 
 	return jsonClient, nil
 }
 
-func (c *InternalClient) DeleteOAuth2Client(id string) error {
-	req, err := c.newRequest(http.MethodDelete, id, nil)
+func (c *InternalClient) PutOAuth2ClientLifespans(ctx context.Context, id string, l *OAuth2ClientLifespans) (*OAuth2ClientJSON, error) {
+	var jsonClient *OAuth2ClientJSON
+
+	if err := c.checkSupported(ctx, &OAuth2ClientJSON{OAuth2ClientLifespans: *l}); err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPut, path.Join(id, "lifespans"), l)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req, &jsonClient)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(req, resp, fmt.Errorf("%s %s http request returned unexpected status code: %s", req.Method, req.URL, resp.Status))
+	}
+
+	return jsonClient, nil
+}
+
+func (c *InternalClient) DeleteOAuth2Client(ctx context.Context, id string) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, id, nil)
 	if err != nil {
 		return err
 	}
@@ -157,14 +279,171 @@ func (c *InternalClient) DeleteOAuth2Client(id string) error {
 	case http.StatusNoContent:
 		return nil
 	case http.StatusNotFound:
-		fmt.Printf("InternalClient with id %s does not exist", id)
+		logr.FromContextOrDiscard(ctx).Info("client to delete does not exist in ORY Hydra", "id", id)
 		return nil
 	default:
-		return fmt.Errorf("%s %s http request returned unexpected status code %s", req.Method, req.URL.String(), resp.Status)
+		return statusError(req, resp, fmt.Errorf("%s %s http request returned unexpected status code %s", req.Method, req.URL.String(), resp.Status))
 	}
 }
 
-func (c *InternalClient) newRequest(method, relativePath string, body interface{}) (*http.Request, error) {
+// Ready checks that ORY Hydra's admin server is reachable and ready to serve
+// requests, by calling its /health/ready endpoint under the path of the admin
+// URL.
+func (c *InternalClient) Ready(ctx context.Context) error {
+	u := c.HydraURL
+	u.Path = path.Join("/", c.basePath, "health/ready")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	if c.ForwardedProto != "" {
+		req.Header.Add("X-Forwarded-Proto", c.ForwardedProto)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.do(req, nil)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(req, resp, fmt.Errorf("%s %s http request returned unexpected status code %s", req.Method, req.URL.String(), resp.Status))
+	}
+
+	return nil
+}
+
+// Version returns the version of ORY Hydra, or an empty string for clients
+// that have not been created with New. It fails if the version cannot be
+// detected.
+func (c *InternalClient) Version(ctx context.Context) (string, error) {
+	v, err := c.getVersion(ctx)
+	if err != nil || v == nil {
+		return "", err
+	}
+	return v.Name, nil
+}
+
+// getVersion queries ORY Hydra's /version endpoint once it is first needed,
+// so that the controller can start before ORY Hydra is reachable. Queries
+// failing with a transient error are retried on the next request, other
+// failures, such as an ORY Hydra behind a proxy not exposing /version, are
+// final.
+func (c *InternalClient) getVersion(ctx context.Context) (*Version, error) {
+	if !c.detectVersion {
+		return nil, nil
+	}
+
+	// the lock is not held during the query, concurrent first requests may
+	// query the version more than once
+	c.mu.Lock()
+	v, err := c.version, c.versionErr
+	c.mu.Unlock()
+	if v != nil || err != nil {
+		return v, err
+	}
+
+	v, err = c.queryVersion(ctx)
+	if err != nil {
+		logr.FromContextOrDiscard(ctx).Error(err, "unable to detect the version of ORY Hydra", "fallbackVersion", fallbackVersion.Name)
+		if !IsTransient(err) {
+			c.mu.Lock()
+			c.versionErr = err
+			c.mu.Unlock()
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version == nil {
+		c.version = v
+	}
+	return c.version, nil
+}
+
+// queryVersion queries ORY Hydra's /version endpoint.
+func (c *InternalClient) queryVersion(ctx context.Context) (*Version, error) {
+	u := c.HydraURL
+	u.Path = path.Join("/", c.basePath, "version")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.ForwardedProto != "" {
+		req.Header.Add("X-Forwarded-Proto", c.ForwardedProto)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	var body struct {
+		Version string `json:"version"`
+	}
+	resp, err := c.do(req, &body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to detect ORY Hydra version: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(req, resp, fmt.Errorf("%s %s http request returned unexpected status code %s", req.Method, req.URL.String(), resp.Status))
+	}
+
+	v := ParseVersion(body.Version)
+	return &v, nil
+}
+
+func (c *InternalClient) checkSupported(ctx context.Context, o *OAuth2ClientJSON) error {
+	v, err := c.getVersion(ctx)
+	if err != nil || v == nil {
+		// the fields of an undetected version are left to ORY Hydra
+		return nil
+	}
+
+	if unsupported := v.Unsupported(o); len(unsupported) > 0 {
+		return &UnsupportedFieldsError{Version: v.Name, Fields: unsupported}
+	}
+	return nil
+}
+
+// requestVersion returns the version of ORY Hydra whose endpoints the
+// requests are sent to: the detected version, fallbackVersion if it cannot be
+// detected, or nil if it is not detected at all.
+func (c *InternalClient) requestVersion(ctx context.Context) *Version {
+	v, err := c.getVersion(ctx)
+	if err != nil {
+		return &fallbackVersion
+	}
+	return v
+}
+
+func (c *InternalClient) newRequest(ctx context.Context, method, relativePath string, body interface{}) (*http.Request, error) {
+	v := c.requestVersion(ctx)
+
+	u := c.HydraURL
+	if v != nil && !c.hasEndpoint {
+		u.Path = path.Join(u.Path, v.ClientsPath())
+	}
+	u.Path = path.Join(u.Path, relativePath)
+
+	return c.newRequestForURL(ctx, method, u, body)
+}
+
+// newAdminRequest returns a request to the admin API endpoint that apiPath
+// returns for the version of ORY Hydra.
+func (c *InternalClient) newAdminRequest(ctx context.Context, method string, apiPath func(Version) string, relativePath string, body interface{}) (*http.Request, error) {
+	v := c.requestVersion(ctx)
+	if v == nil {
+		// assume the latest version when it is not detected at all
+		v = &Version{}
+	}
+
+	u := c.HydraURL
+	u.Path = path.Join("/", c.basePath, apiPath(*v), relativePath)
+	return c.newRequestForURL(ctx, method, u, body)
+}
+
+func (c *InternalClient) newRequestForURL(ctx context.Context, method string, u url.URL, body interface{}) (*http.Request, error) {
 	var buf io.ReadWriter
 	if body != nil {
 		buf = new(bytes.Buffer)
@@ -174,10 +453,7 @@ func (c *InternalClient) newRequest(method, relativePath string, body interface{
 		}
 	}
 
-	u := c.HydraURL
-	u.Path = path.Join(u.Path, relativePath)
-
-	req, err := http.NewRequest(method, u.String(), buf)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -195,10 +471,13 @@ func (c *InternalClient) newRequest(method, relativePath string, body interface{
 
 }
 
-func (c *InternalClient) do(req *http.Request, v interface{}) (*http.Response, error) {
-	resp, err := c.HTTPClient.Do(req)
+func (c *InternalClient) do(req *http.Request, v interface{}) (resp *http.Response, err error) {
+	req, span := startSpan(req)
+	defer func() { endSpan(span, resp, err) }()
+
+	resp, err = c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, &transportError{err: err}
 	}
 
 	defer resp.Body.Close()
@@ -4,19 +4,24 @@
 package hydra_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"k8s.io/utils/ptr"
 
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
 	"github.com/ory/hydra-maester/hydra"
 )
 
@@ -30,6 +35,7 @@ const (
 	testClientUpdated             = `{"client_id":"test-id-3","client_secret":"xFoPPm654por","owner":"test-name-3","scope":"yet,another,scope","grant_types":["type3"],"audience":["audience-c"],"token_endpoint_auth_method":"client_secret_basic"}`
 	testClientList                = `{"client_id":"test-id-4","owner":"test-name-4","scope":"scope1 scope2","grant_types":["type4"],"token_endpoint_auth_method":"client_secret_basic"}`
 	testClientList2               = `{"client_id":"test-id-5","owner":"test-name-5","scope":"scope3 scope4","grant_types":["type5"],"token_endpoint_auth_method":"client_secret_basic"}`
+	testClientWithLifespans       = `{"client_id":"test-id","owner":"test-name","scope":"some,scopes","grant_types":["type1"],"refresh_token_grant_refresh_token_lifespan":"720h0m0s"}`
 	testClientWithMetadataCreated = `{"client_id":"test-id-21","client_secret":"TmGkvcY7k526","owner":"test-name-21","scope":"some,other,scopes","grant_types":["type2"],"token_endpoint_auth_method":"client_secret_basic","metadata":{"property1":1,"property2":"2"},"backchannel_logout_uri":"https://localhost/backchannel-logout","frontchannel_logout_uri":"https://localhost/frontchannel-logout"}`
 
 	statusNotFoundBody            = `{"error":"Not Found","error_description":"Unable to locate the requested resource","status_code":404,"request_id":"id"}`
@@ -53,7 +59,9 @@ var testOAuthJSONPost = &hydra.OAuth2ClientJSON{
 	FrontChannelLogoutSessionRequired: false,
 	BackChannelLogoutURI:              "https://localhost/backchannel-logout",
 	BackChannelLogoutSessionRequired:  false,
-	AuthorizationCodeGrantAccessTokenLifespan: "6h",
+	OAuth2ClientLifespans: hydra.OAuth2ClientLifespans{
+		AuthorizationCodeGrantAccessTokenLifespan: "6h",
+	},
 }
 
 var testOAuthJSONPut = &hydra.OAuth2ClientJSON{
@@ -114,7 +122,7 @@ func TestCRUD(t *testing.T) {
 				runServer(&c, h)
 
 				//when
-				o, found, err := c.GetOAuth2Client(testID)
+				o, found, err := c.GetOAuth2Client(context.Background(), testID)
 
 				//then
 				if tc.err == nil {
@@ -196,10 +204,10 @@ func TestCRUD(t *testing.T) {
 						BackChannelLogoutURI:              "https://localhost/backchannel-logout",
 						BackChannelLogoutSessionRequired:  false,
 					}
-					o, err = c.PostOAuth2Client(testOAuthJSONPost2)
+					o, err = c.PostOAuth2Client(context.Background(), testOAuthJSONPost2)
 					expected = testOAuthJSONPost2
 				} else {
-					o, err = c.PostOAuth2Client(testOAuthJSONPost)
+					o, err = c.PostOAuth2Client(context.Background(), testOAuthJSONPost)
 					expected = testOAuthJSONPost
 				}
 
@@ -271,7 +279,7 @@ func TestCRUD(t *testing.T) {
 				runServer(&c, h)
 
 				//when
-				o, err := c.PutOAuth2Client(testOAuthJSONPut)
+				o, err := c.PutOAuth2Client(context.Background(), testOAuthJSONPut)
 
 				//then
 				if tc.err == nil {
@@ -295,6 +303,53 @@ func TestCRUD(t *testing.T) {
 		}
 	})
 
+	t.Run("method=put lifespans", func(t *testing.T) {
+		for d, tc := range map[string]server{
+			"with registered client": {
+				http.StatusOK,
+				testClientWithLifespans,
+				nil,
+			},
+			"with unregistered client": {
+				http.StatusNotFound,
+				statusNotFoundBody,
+				errors.New("http request returned unexpected status code"),
+			},
+		} {
+			t.Run(fmt.Sprintf("case/%s", d), func(t *testing.T) {
+
+				//given
+				h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					assert.Equal(fmt.Sprintf("%s/%s/lifespans", c.HydraURL.String(), testID), fmt.Sprintf("%s://%s%s", schemeHTTP, req.Host, req.URL.Path))
+					assert.Equal(http.MethodPut, req.Method)
+
+					var lifespans map[string]string
+					require.NoError(t, json.NewDecoder(req.Body).Decode(&lifespans))
+					assert.Equal(map[string]string{"refresh_token_grant_refresh_token_lifespan": "720h"}, lifespans)
+
+					w.WriteHeader(tc.statusCode)
+					w.Write([]byte(tc.respBody))
+				})
+				runServer(&c, h)
+
+				//when
+				o, err := c.PutOAuth2ClientLifespans(context.Background(), testID, &hydra.OAuth2ClientLifespans{
+					RefreshTokenGrantRefreshTokenLifespan: "720h",
+				})
+
+				//then
+				if tc.err == nil {
+					require.NoError(t, err)
+					require.NotNil(t, o)
+					assert.Equal("720h0m0s", o.RefreshTokenGrantRefreshTokenLifespan)
+				} else {
+					require.Error(t, err)
+					assert.Contains(err.Error(), tc.err.Error())
+				}
+			})
+		}
+	})
+
 	t.Run("method=delete", func(t *testing.T) {
 
 		for d, tc := range map[string]server{
@@ -320,9 +375,13 @@ func TestCRUD(t *testing.T) {
 					w.WriteHeader(tc.statusCode)
 				})
 				runServer(&c, h)
+				var logged []string
+				ctx := logr.NewContext(context.Background(), funcr.New(func(_, args string) {
+					logged = append(logged, args)
+				}, funcr.Options{}))
 
 				//when
-				err := c.DeleteOAuth2Client(testID)
+				err := c.DeleteOAuth2Client(ctx, testID)
 
 				//then
 				if tc.err == nil {
@@ -331,6 +390,12 @@ func TestCRUD(t *testing.T) {
 					require.Error(t, err)
 					assert.Contains(err.Error(), tc.err.Error())
 				}
+				if tc.statusCode == http.StatusNotFound {
+					require.Len(t, logged, 1)
+					assert.Contains(logged[0], `"id"="`+testID+`"`)
+				} else {
+					assert.Empty(logged)
+				}
 			})
 		}
 	})
@@ -373,7 +438,7 @@ func TestCRUD(t *testing.T) {
 				runServer(&c, h)
 
 				//when
-				list, err := c.ListOAuth2Client()
+				list, err := c.ListOAuth2Client(context.Background())
 
 				//then
 				if tc.err == nil {
@@ -388,6 +453,74 @@ func TestCRUD(t *testing.T) {
 				}
 			})
 		}
+
+		t.Run("case/more pages", func(t *testing.T) {
+
+			//given
+			var tokens []string
+			h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				assert.Equal(c.HydraURL.Path, req.URL.Path)
+				token := req.URL.Query().Get("page_token")
+				tokens = append(tokens, token)
+				switch token {
+				case "":
+					w.Header().Add("Link", fmt.Sprintf(`<%s?page_size=1&page_token=next>; rel="next"`, clientsEndpoint))
+					w.Write([]byte(fmt.Sprintf("[%s]", testClientList)))
+				case "next":
+					w.Header().Add("Link", fmt.Sprintf(`<%s?page_size=1>; rel="first"`, clientsEndpoint))
+					w.Write([]byte(fmt.Sprintf("[%s]", testClientList2)))
+				}
+			})
+			runServer(&c, h)
+
+			//when
+			list, err := c.ListOAuth2Client(context.Background())
+
+			//then
+			require.NoError(t, err)
+			var expectedList []*hydra.OAuth2ClientJSON
+			json.Unmarshal([]byte(fmt.Sprintf("[%s,%s]", testClientList, testClientList2)), &expectedList)
+			assert.Equal(expectedList, list)
+			assert.Equal([]string{"", "next"}, tokens)
+		})
+	})
+
+	t.Run("method=ready", func(t *testing.T) {
+
+		for d, tc := range map[string]server{
+			"ready": {
+				statusCode: http.StatusOK,
+				respBody:   `{"status":"ok"}`,
+			},
+			"not ready": {
+				statusCode: http.StatusServiceUnavailable,
+				respBody:   `{"errors":{"database":"not alive"}}`,
+				err:        errors.New("http request returned unexpected status code 503"),
+			},
+		} {
+			t.Run(fmt.Sprintf("case/%s", d), func(t *testing.T) {
+
+				//given
+				h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					assert.Equal("/health/ready", req.URL.Path)
+					assert.Equal(http.MethodGet, req.Method)
+					w.WriteHeader(tc.statusCode)
+					w.Write([]byte(tc.respBody))
+				})
+				runServer(&c, h)
+
+				//when
+				err := c.Ready(context.Background())
+
+				//then
+				if tc.err == nil {
+					require.NoError(t, err)
+				} else {
+					require.Error(t, err)
+					assert.Contains(err.Error(), tc.err.Error())
+				}
+			})
+		}
 	})
 
 	t.Run("default parameters", func(t *testing.T) {
@@ -418,3 +551,49 @@ func runServer(c *hydra.InternalClient, h http.HandlerFunc) {
 	serverUrl, _ := url.Parse(s.URL)
 	c.HydraURL = *serverUrl.ResolveReference(&url.URL{Path: clientsEndpoint})
 }
+
+func TestAdminRequestsUnderBasePath(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.Method+" "+req.URL.Path)
+		switch req.Method {
+		case http.MethodGet:
+			if strings.HasSuffix(req.URL.Path, "/version") {
+				w.Write([]byte(`{"version":"v2.2.0"}`))
+				return
+			}
+			w.Write([]byte(`{"keys":[]}`))
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"trust-id"}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+	c, err := hydra.NewInternalClient(hydrav1alpha1.HydraAdmin{URL: "http://" + u.Hostname() + "/hydra", Port: port}, "", false)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, _, err = c.GetJSONWebKeySet(ctx, "set")
+	require.NoError(t, err)
+	require.NoError(t, c.DeleteJSONWebKey(ctx, "set", "kid"))
+	_, err = c.CreateTrustedJwtGrantIssuer(ctx, &hydra.TrustJwtGrantIssuerJSON{})
+	require.NoError(t, err)
+	require.NoError(t, c.DeleteTrustedJwtGrantIssuer(ctx, "trust-id"))
+	require.NoError(t, c.RevokeOAuth2ClientTokens(ctx, "client-id"))
+
+	assert.Equal(t, []string{
+		"GET /hydra/version",
+		"GET /hydra/admin/keys/set",
+		"DELETE /hydra/admin/keys/set/kid",
+		"POST /hydra/admin/trust/grants/jwt-bearer/issuers",
+		"DELETE /hydra/admin/trust/grants/jwt-bearer/issuers/trust-id",
+		"DELETE /hydra/admin/oauth2/tokens",
+	}, requests)
+}
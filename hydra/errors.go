@@ -0,0 +1,87 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hydra
+
+import (
+	"errors"
+	"net/http"
+)
+
+// StatusError is returned when ORY Hydra answers a request with an unexpected
+// HTTP status code.
+type StatusError struct {
+	Method     string
+	StatusCode int
+	Err        error
+}
+
+func (e *StatusError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+func statusError(req *http.Request, resp *http.Response, err error) error {
+	return &StatusError{Method: req.Method, StatusCode: resp.StatusCode, Err: err}
+}
+
+// transportError is returned when a request could not be sent to ORY Hydra
+// or its response could not be received, e.g. because ORY Hydra is down.
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// IsUnavailable returns true if err shows that ORY Hydra could not be
+// reached or is not ready to serve requests.
+func IsUnavailable(err error) bool {
+	var transportErr *transportError
+	if errors.As(err, &transportErr) {
+		return true
+	}
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	switch statusErr.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTransient returns true if err is likely to go away when the request is
+// retried later: ORY Hydra could not be reached, failed with a server error,
+// throttled the request or reported a conflicting update.
+func IsTransient(err error) bool {
+	var transportErr *transportError
+	if errors.As(err, &transportErr) {
+		return true
+	}
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	switch {
+	case statusErr.StatusCode >= http.StatusInternalServerError, statusErr.StatusCode == http.StatusTooManyRequests:
+		return true
+	case statusErr.StatusCode == http.StatusConflict:
+		// creating an entity under an ID that is already taken fails again
+		return statusErr.Method != http.MethodPost
+	default:
+		return false
+	}
+}
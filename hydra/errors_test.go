@@ -0,0 +1,72 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hydra_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+
+	"github.com/ory/hydra-maester/hydra"
+)
+
+func TestIsTransient(t *testing.T) {
+	c := hydra.InternalClient{HTTPClient: &http.Client{}}
+	client := &hydra.OAuth2ClientJSON{ClientID: ptr.To("test-id")}
+
+	for status, tc := range map[int]struct {
+		post        bool
+		put         bool
+		unavailable bool
+	}{
+		http.StatusBadRequest:          {false, false, false},
+		http.StatusNotFound:            {false, false, false},
+		http.StatusConflict:            {false, true, false},
+		http.StatusTooManyRequests:     {true, true, false},
+		http.StatusInternalServerError: {true, true, false},
+		http.StatusServiceUnavailable:  {true, true, true},
+	} {
+		t.Run(fmt.Sprintf("status=%d", status), func(t *testing.T) {
+			runServer(&c, func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(status)
+			})
+
+			_, err := c.PostOAuth2Client(context.Background(), client)
+			var statusErr *hydra.StatusError
+			if assert.ErrorAs(t, err, &statusErr) {
+				assert.Equal(t, status, statusErr.StatusCode)
+			}
+			assert.Equal(t, tc.post, hydra.IsTransient(err), "post")
+			assert.Equal(t, tc.unavailable, hydra.IsUnavailable(err), "unavailable")
+
+			_, err = c.PutOAuth2Client(context.Background(), client)
+			assert.Equal(t, tc.put, hydra.IsTransient(err), "put")
+		})
+	}
+
+	t.Run("case=unreachable", func(t *testing.T) {
+		s := httptest.NewServer(http.NotFoundHandler())
+		u, _ := url.Parse(s.URL)
+		s.Close()
+		c.HydraURL = *u
+
+		_, _, err := c.GetOAuth2Client(context.Background(), "test-id")
+		assert.Error(t, err)
+		assert.True(t, hydra.IsTransient(err))
+		assert.True(t, hydra.IsUnavailable(err))
+	})
+
+	t.Run("case=other errors", func(t *testing.T) {
+		assert.False(t, hydra.IsTransient(errors.New("oops")))
+		assert.False(t, hydra.IsUnavailable(errors.New("oops")))
+		assert.False(t, hydra.IsTransient(&hydra.UnsupportedFieldsError{Version: "v2.1.2", Fields: []string{"skip_consent"}}))
+	})
+}
@@ -0,0 +1,481 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+// Package hydratest provides an in-memory fake of ORY Hydra's admin API for
+// the tests of the controllers, which serves the endpoints that the hydra
+// package calls. Unlike a mocked hydra.Client, the fake keeps the clients it
+// registers, so that the tests exercise the real HTTP client end to end.
+package hydratest
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"k8s.io/utils/ptr"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+const (
+	// DefaultVersion is the version of ORY Hydra that servers report unless
+	// WithVersion is given.
+	DefaultVersion = "v2.2.0"
+
+	// DefaultPageSize is the number of clients per page of the list unless
+	// WithPageSize is given, which is ORY Hydra's default.
+	DefaultPageSize = 250
+)
+
+// Server is a fake ORY Hydra admin server backed by an in-memory store of
+// OAuth2 clients. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	version  hydra.Version
+	pageSize int
+
+	mu          sync.Mutex
+	clients     map[string]hydra.OAuth2ClientJSON
+	faults      []*Fault
+	requests    []string
+	revocations []Revocation
+	onRequest   func(r *http.Request)
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithVersion sets the version of ORY Hydra that the server reports, which
+// selects the paths of the endpoints it serves.
+func WithVersion(version string) Option {
+	return func(s *Server) {
+		s.version = hydra.ParseVersion(version)
+	}
+}
+
+// WithPageSize sets the default number of clients per page of the list.
+func WithPageSize(size int) Option {
+	return func(s *Server) {
+		s.pageSize = size
+	}
+}
+
+// Fault makes the server answer the requests it matches with an error status
+// code, after an optional latency, instead of serving them.
+type Fault struct {
+	// Method is the method of the requests to match, or any method if empty.
+	Method string
+	// Path is the prefix of the path of the requests to match, or any path if
+	// empty.
+	Path string
+	// Latency delays the answer to the matched requests, which are served as
+	// usual after it unless StatusCode is set.
+	Latency time.Duration
+	// StatusCode is the status code of the answer to the matched requests.
+	StatusCode int
+	// Times is the number of requests the fault applies to, or all of them
+	// if zero.
+	Times int
+}
+
+// Revocation records a revocation requested for a client.
+type Revocation struct {
+	Target   hydrav1alpha1.RevocationTarget
+	ClientID string
+}
+
+// NewServer starts and returns a new Server, which the caller should Close
+// when done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		version:  hydra.ParseVersion(DefaultVersion),
+		pageSize: DefaultPageSize,
+		clients:  map[string]hydra.OAuth2ClientJSON{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	clients := s.version.ClientsPath()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /version", s.getVersion)
+	mux.HandleFunc("GET /health/ready", s.getHealth)
+	mux.HandleFunc("GET /health/alive", s.getHealth)
+	mux.HandleFunc("GET "+clients, s.listClients)
+	mux.HandleFunc("POST "+clients, s.createClient)
+	mux.HandleFunc("GET "+clients+"/{id}", s.getClient)
+	mux.HandleFunc("PUT "+clients+"/{id}", s.updateClient)
+	mux.HandleFunc("PUT "+clients+"/{id}/lifespans", s.updateLifespans)
+	mux.HandleFunc("DELETE "+clients+"/{id}", s.deleteClient)
+	mux.HandleFunc("DELETE "+s.version.TokensPath(), s.revoke(hydrav1alpha1.RevokeTokens, "client_id"))
+
+	s.Server = httptest.NewServer(s.injectFaults(mux))
+	return s
+}
+
+// HydraAdmin returns the address of the server in the form of the spec of an
+// OAuth2Client.
+func (s *Server) HydraAdmin() hydrav1alpha1.HydraAdmin {
+	u, _ := url.Parse(s.URL)
+	port, _ := strconv.Atoi(u.Port())
+	return hydrav1alpha1.HydraAdmin{
+		URL:  u.Scheme + "://" + u.Hostname(),
+		Port: port,
+	}
+}
+
+// NewClient returns a client of the hydra package for the server.
+func (s *Server) NewClient() (*hydra.InternalClient, error) {
+	return hydra.NewInternalClient(s.HydraAdmin(), "", false)
+}
+
+// Inject makes the server apply f to the requests it matches from now on.
+// Faults apply in the order they are injected, the first match winning.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all the injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// OnRequest makes the server call f with each request before serving it,
+// e.g. to observe the state of the cluster when a client is registered.
+func (s *Server) OnRequest(f func(r *http.Request)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onRequest = f
+}
+
+// Add registers c as is, as if it had been created out of band.
+func (s *Server) Add(c hydra.OAuth2ClientJSON) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients[*c.ClientID] = c
+}
+
+// Client returns the client registered with the given ID, including its
+// secret.
+func (s *Server) Client(id string) (hydra.OAuth2ClientJSON, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.clients[id]
+	return c, ok
+}
+
+// Clients returns all the registered clients ordered by ID, including their
+// secrets.
+func (s *Server) Clients() []hydra.OAuth2ClientJSON {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clients := make([]hydra.OAuth2ClientJSON, 0, len(s.clients))
+	for _, id := range s.sortedIDs() {
+		clients = append(clients, s.clients[id])
+	}
+	return clients
+}
+
+// Requests returns the method and path of the requests served so far, such
+// as "POST /admin/clients", including the ones answered with a fault.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// Revocations returns the revocations requested so far.
+func (s *Server) Revocations() []Revocation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.revocations)
+}
+
+func (s *Server) injectFaults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		var fault Fault
+		for _, f := range s.faults {
+			if (f.Method == "" || f.Method == r.Method) && strings.HasPrefix(r.URL.Path, f.Path) {
+				fault = *f
+				if f.Times > 0 {
+					if f.Times--; f.Times == 0 {
+						s.faults = slices.DeleteFunc(s.faults, func(o *Fault) bool { return o == f })
+					}
+				}
+				break
+			}
+		}
+		onRequest := s.onRequest
+		s.mu.Unlock()
+
+		if onRequest != nil {
+			onRequest(r)
+		}
+
+		if fault.Latency > 0 {
+			select {
+			case <-time.After(fault.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fault.StatusCode != 0 {
+			writeError(w, fault.StatusCode, "injected fault")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) getVersion(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"version": s.version.Name})
+}
+
+func (s *Server) getHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// listClients lists the clients ordered by ID, filtered by owner and name,
+// with the keyset pagination of ORY Hydra v2: the page_token of a page is
+// the last ID of the previous page, which the Link header carries.
+func (s *Server) listClients(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	size := s.pageSize
+	if v := query.Get("page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "invalid page_size")
+			return
+		}
+		size = n
+	}
+	var after string
+	if v := query.Get("page_token"); v != "" {
+		token, err := base64.RawURLEncoding.DecodeString(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid page_token")
+			return
+		}
+		after = string(token)
+	}
+
+	s.mu.Lock()
+	page := []hydra.OAuth2ClientJSON{}
+	var more bool
+	for _, id := range s.sortedIDs() {
+		c := s.clients[id]
+		if after != "" && id <= after ||
+			query.Has("owner") && c.Owner != query.Get("owner") ||
+			query.Has("client_name") && c.ClientName != query.Get("client_name") {
+			continue
+		}
+		if len(page) == size {
+			more = true
+			break
+		}
+		page = append(page, withoutSecret(c))
+	}
+	s.mu.Unlock()
+
+	if more {
+		next := url.Values{}
+		for _, key := range []string{"owner", "client_name"} {
+			if query.Has(key) {
+				next.Set(key, query.Get(key))
+			}
+		}
+		next.Set("page_size", strconv.Itoa(size))
+		next.Set("page_token", base64.RawURLEncoding.EncodeToString([]byte(*page[len(page)-1].ClientID)))
+		w.Header().Add("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, next.Encode()))
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) getClient(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	c, ok := s.clients[r.PathValue("id")]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "Unable to locate the resource")
+		return
+	}
+	writeJSON(w, http.StatusOK, withoutSecret(c))
+}
+
+// createClient registers a client, generating its ID unless given, and its
+// secret unless given or it does not authenticate with one. As ORY Hydra,
+// it answers with the secret, which is never returned afterwards.
+func (s *Server) createClient(w http.ResponseWriter, r *http.Request) {
+	var c hydra.OAuth2ClientJSON
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if c.ClientID == nil || *c.ClientID == "" {
+		c.ClientID = ptr.To(uuid.NewString())
+	}
+	if c.TokenEndpointAuthMethod == "" {
+		c.TokenEndpointAuthMethod = "client_secret_basic"
+	}
+	if c.Secret == nil && c.TokenEndpointAuthMethod != "none" && c.TokenEndpointAuthMethod != "private_key_jwt" {
+		c.Secret = ptr.To(generateSecret())
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	c.CreatedAt, c.UpdatedAt = now, now
+
+	s.mu.Lock()
+	if _, ok := s.clients[*c.ClientID]; ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusConflict, "Unable to insert or update resource because a resource with that value exists already")
+		return
+	}
+	s.clients[*c.ClientID] = c
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, c)
+}
+
+// updateClient replaces a client, keeping its secret unless a new one is
+// given, in which case it answers with the new secret.
+func (s *Server) updateClient(w http.ResponseWriter, r *http.Request) {
+	var c hydra.OAuth2ClientJSON
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	old, ok := s.clients[id]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Unable to locate the resource")
+		return
+	}
+	newSecret := c.Secret != nil
+	c.ClientID = ptr.To(id)
+	if !newSecret {
+		c.Secret = old.Secret
+	}
+	if c.TokenEndpointAuthMethod == "" {
+		c.TokenEndpointAuthMethod = old.TokenEndpointAuthMethod
+	}
+	c.CreatedAt = old.CreatedAt
+	c.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	s.clients[id] = c
+	s.mu.Unlock()
+
+	if !newSecret {
+		c = withoutSecret(c)
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) updateLifespans(w http.ResponseWriter, r *http.Request) {
+	var l hydra.OAuth2ClientLifespans
+	if err := json.NewDecoder(r.Body).Decode(&l); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	c, ok := s.clients[id]
+	if ok {
+		c.OAuth2ClientLifespans = l
+		c.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		s.clients[id] = c
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "Unable to locate the resource")
+		return
+	}
+	writeJSON(w, http.StatusOK, withoutSecret(c))
+}
+
+func (s *Server) deleteClient(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	_, ok := s.clients[id]
+	delete(s.clients, id)
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "Unable to locate the resource")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) revoke(target hydrav1alpha1.RevocationTarget, clientParam string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		revocation := Revocation{Target: target, ClientID: r.URL.Query().Get(clientParam)}
+		if revocation.ClientID == "" {
+			writeError(w, http.StatusBadRequest, "missing "+clientParam)
+			return
+		}
+
+		s.mu.Lock()
+		s.revocations = append(s.revocations, revocation)
+		s.mu.Unlock()
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) sortedIDs() []string {
+	ids := make([]string, 0, len(s.clients))
+	for id := range s.clients {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func withoutSecret(c hydra.OAuth2ClientJSON) hydra.OAuth2ClientJSON {
+	c.Secret = nil
+	return c
+}
+
+func generateSecret() string {
+	b := make([]byte, 26)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers with an error in the format of ORY Hydra.
+func writeError(w http.ResponseWriter, statusCode int, description string) {
+	writeJSON(w, statusCode, map[string]interface{}{
+		"error":             strings.ToLower(strings.ReplaceAll(http.StatusText(statusCode), " ", "_")),
+		"error_description": description,
+		"status_code":       statusCode,
+	})
+}
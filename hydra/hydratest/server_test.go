@@ -0,0 +1,198 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hydratest_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/hydra"
+	"github.com/ory/hydra-maester/hydra/hydratest"
+)
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	newClient := func(t *testing.T, opts ...hydratest.Option) (*hydratest.Server, *hydra.InternalClient) {
+		s := hydratest.NewServer(opts...)
+		t.Cleanup(s.Close)
+		c, err := s.NewClient()
+		require.NoError(t, err)
+		return s, c
+	}
+
+	t.Run("case=registers clients", func(t *testing.T) {
+		s, c := newClient(t)
+
+		created, err := c.PostOAuth2Client(ctx, &hydra.OAuth2ClientJSON{
+			ClientName: "test",
+			GrantTypes: []string{"client_credentials"},
+			Scope:      "a b",
+			Owner:      "test/default",
+		})
+		require.NoError(t, err)
+		require.NotNil(t, created.ClientID)
+		require.NotNil(t, created.Secret)
+		assert.Equal(t, "client_secret_basic", created.TokenEndpointAuthMethod)
+		assert.NotEmpty(t, created.CreatedAt)
+
+		fetched, found, err := c.GetOAuth2Client(ctx, *created.ClientID)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Nil(t, fetched.Secret)
+		assert.Equal(t, "a b", fetched.Scope)
+
+		stored, ok := s.Client(*created.ClientID)
+		require.True(t, ok)
+		assert.Equal(t, created.Secret, stored.Secret)
+
+		_, err = c.PostOAuth2Client(ctx, &hydra.OAuth2ClientJSON{ClientID: created.ClientID})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requested ID already exists")
+	})
+
+	t.Run("case=does not generate secrets for public clients", func(t *testing.T) {
+		_, c := newClient(t)
+
+		created, err := c.PostOAuth2Client(ctx, &hydra.OAuth2ClientJSON{
+			ClientID:                ptr.To("public"),
+			TokenEndpointAuthMethod: "none",
+		})
+		require.NoError(t, err)
+		assert.Nil(t, created.Secret)
+	})
+
+	t.Run("case=updates clients", func(t *testing.T) {
+		s, c := newClient(t)
+		s.Add(hydra.OAuth2ClientJSON{ClientID: ptr.To("test-id"), Secret: ptr.To("secret"), Scope: "a"})
+
+		updated, err := c.PutOAuth2Client(ctx, &hydra.OAuth2ClientJSON{ClientID: ptr.To("test-id"), Scope: "a b"})
+		require.NoError(t, err)
+		assert.Equal(t, "a b", updated.Scope)
+		assert.Nil(t, updated.Secret)
+
+		updated, err = c.PutOAuth2ClientLifespans(ctx, "test-id", &hydra.OAuth2ClientLifespans{
+			ClientCredentialsGrantAccessTokenLifespan: "1h",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "1h", updated.ClientCredentialsGrantAccessTokenLifespan)
+
+		stored, _ := s.Client("test-id")
+		assert.Equal(t, "a b", stored.Scope)
+		assert.Equal(t, ptr.To("secret"), stored.Secret)
+
+		_, err = c.PutOAuth2Client(ctx, &hydra.OAuth2ClientJSON{ClientID: ptr.To("unknown")})
+		require.Error(t, err)
+	})
+
+	t.Run("case=deletes clients", func(t *testing.T) {
+		s, c := newClient(t)
+		s.Add(hydra.OAuth2ClientJSON{ClientID: ptr.To("test-id")})
+
+		require.NoError(t, c.DeleteOAuth2Client(ctx, "test-id"))
+		_, found, err := c.GetOAuth2Client(ctx, "test-id")
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.Empty(t, s.Clients())
+
+		// deleting a missing client is not an error
+		require.NoError(t, c.DeleteOAuth2Client(ctx, "test-id"))
+	})
+
+	t.Run("case=lists clients page by page", func(t *testing.T) {
+		s, c := newClient(t, hydratest.WithPageSize(2))
+		for i := range 5 {
+			s.Add(hydra.OAuth2ClientJSON{ClientID: ptr.To(fmt.Sprintf("test-id-%d", i))})
+		}
+
+		list, err := c.ListOAuth2Client(ctx)
+		require.NoError(t, err)
+		var ids []string
+		for _, o := range list {
+			ids = append(ids, *o.ClientID)
+		}
+		assert.Equal(t, []string{"test-id-0", "test-id-1", "test-id-2", "test-id-3", "test-id-4"}, ids)
+		assert.Equal(t, []string{
+			"GET /version",
+			"GET /admin/clients",
+			"GET /admin/clients",
+			"GET /admin/clients",
+		}, s.Requests())
+	})
+
+	t.Run("case=filters clients by owner", func(t *testing.T) {
+		s, _ := newClient(t)
+		s.Add(hydra.OAuth2ClientJSON{ClientID: ptr.To("mine"), Owner: "test/default"})
+		s.Add(hydra.OAuth2ClientJSON{ClientID: ptr.To("theirs"), Owner: "other/default"})
+
+		resp, err := http.Get(s.URL + "/admin/clients?owner=test/default")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var list []hydra.OAuth2ClientJSON
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+		require.Len(t, list, 1)
+		assert.Equal(t, "mine", *list[0].ClientID)
+	})
+
+	t.Run("case=calls the request hook", func(t *testing.T) {
+		s, c := newClient(t)
+		var methods []string
+		s.OnRequest(func(r *http.Request) {
+			methods = append(methods, r.Method)
+		})
+
+		_, err := c.PostOAuth2Client(ctx, &hydra.OAuth2ClientJSON{ClientID: ptr.To("test-id")})
+		require.NoError(t, err)
+		assert.Equal(t, []string{http.MethodGet, http.MethodPost}, methods)
+	})
+
+	t.Run("case=serves older versions", func(t *testing.T) {
+		s, c := newClient(t, hydratest.WithVersion("v1.11.0"))
+
+		_, err := c.PostOAuth2Client(ctx, &hydra.OAuth2ClientJSON{ClientID: ptr.To("test-id")})
+		require.NoError(t, err)
+		require.NoError(t, c.RevokeOAuth2ClientTokens(ctx, "test-id"))
+
+		assert.Contains(t, s.Requests(), "POST /clients")
+		assert.Equal(t, []hydratest.Revocation{
+			{Target: hydrav1alpha1.RevokeTokens, ClientID: "test-id"},
+		}, s.Revocations())
+	})
+
+	t.Run("case=injects faults", func(t *testing.T) {
+		s, c := newClient(t)
+		require.NoError(t, c.Ready(ctx))
+
+		s.Inject(hydratest.Fault{Method: http.MethodPost, StatusCode: http.StatusServiceUnavailable, Times: 1})
+		_, err := c.PostOAuth2Client(ctx, &hydra.OAuth2ClientJSON{ClientID: ptr.To("test-id")})
+		require.Error(t, err)
+		assert.True(t, hydra.IsTransient(err))
+
+		// the fault applied once
+		_, err = c.PostOAuth2Client(ctx, &hydra.OAuth2ClientJSON{ClientID: ptr.To("test-id")})
+		require.NoError(t, err)
+
+		s.Inject(hydratest.Fault{Path: "/admin/clients", StatusCode: http.StatusUnauthorized})
+		_, found, err := c.GetOAuth2Client(ctx, "test-id")
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		s.ClearFaults()
+		s.Inject(hydratest.Fault{Latency: time.Second})
+		timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, _, err = c.GetOAuth2Client(timeoutCtx, "test-id")
+		require.Error(t, err)
+		assert.True(t, hydra.IsTransient(err))
+	})
+}
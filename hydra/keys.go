@@ -0,0 +1,183 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hydra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+)
+
+// KeysClient manages ORY Hydra's JSON Web Key sets.
+type KeysClient interface {
+	GetJSONWebKeySet(ctx context.Context, set string) (*JSONWebKeySet, bool, error)
+	CreateJSONWebKey(ctx context.Context, set string, k *JSONWebKeyRequest) (*JSONWebKeySet, error)
+	DeleteJSONWebKey(ctx context.Context, set, kid string) error
+	DeleteJSONWebKeySet(ctx context.Context, set string) error
+}
+
+// NewKeysClient returns a KeysClient for the given ORY Hydra admin server.
+func NewKeysClient(admin hydrav1alpha1.HydraAdmin, tlsTrustStore string, insecureSkipVerify bool) (KeysClient, error) {
+	client, err := NewInternalClient(admin, tlsTrustStore, insecureSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// JSONWebKey is a JSON Web Key as returned by ORY Hydra. It is kept as a map
+// to preserve the parameters specific to each key type.
+type JSONWebKey map[string]interface{}
+
+// privateParameters are the JWK parameters of private and symmetric keys.
+var privateParameters = []string{"d", "p", "q", "dp", "dq", "qi", "oth", "k"}
+
+func (k JSONWebKey) param(name string) string {
+	s, _ := k[name].(string)
+	return s
+}
+
+// KeyID returns the "kid" parameter of the key.
+func (k JSONWebKey) KeyID() string {
+	return k.param("kid")
+}
+
+// Algorithm returns the "alg" parameter of the key.
+func (k JSONWebKey) Algorithm() string {
+	return k.param("alg")
+}
+
+// Use returns the "use" parameter of the key.
+func (k JSONWebKey) Use() string {
+	return k.param("use")
+}
+
+// Public returns the public part of the key, or nil for symmetric keys.
+func (k JSONWebKey) Public() JSONWebKey {
+	if k.param("kty") == "oct" {
+		return nil
+	}
+
+	public := make(JSONWebKey, len(k))
+	for name, value := range k {
+		public[name] = value
+	}
+	for _, name := range privateParameters {
+		delete(public, name)
+	}
+	return public
+}
+
+// JSONWebKeySet represents a JSON Web Key Set digestible by ORY Hydra
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// Key returns the key with the given ID, or nil.
+func (s *JSONWebKeySet) Key(kid string) JSONWebKey {
+	for _, k := range s.Keys {
+		if k.KeyID() == kid {
+			return k
+		}
+	}
+	return nil
+}
+
+// Public returns the set of the public keys, leaving out symmetric keys.
+func (s *JSONWebKeySet) Public() *JSONWebKeySet {
+	public := &JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, k := range s.Keys {
+		if p := k.Public(); p != nil {
+			public.Keys = append(public.Keys, p)
+		}
+	}
+	return public
+}
+
+// JSONWebKeyRequest describes a key for ORY Hydra to generate.
+type JSONWebKeyRequest struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+}
+
+func (c *InternalClient) GetJSONWebKeySet(ctx context.Context, set string) (*JSONWebKeySet, bool, error) {
+	var jsonKeySet *JSONWebKeySet
+
+	req, err := c.newKeysRequest(ctx, http.MethodGet, set, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, err := c.do(req, &jsonKeySet)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return jsonKeySet, true, nil
+	case http.StatusNotFound:
+		return nil, false, nil
+	default:
+		return nil, false, statusError(req, resp, fmt.Errorf("%s %s http request returned unexpected status code %s", req.Method, req.URL.String(), resp.Status))
+	}
+}
+
+// CreateJSONWebKey makes ORY Hydra generate a new key in the set, creating
+// the set if needed. It returns a set made of the generated key.
+func (c *InternalClient) CreateJSONWebKey(ctx context.Context, set string, k *JSONWebKeyRequest) (*JSONWebKeySet, error) {
+	var jsonKeySet *JSONWebKeySet
+
+	req, err := c.newKeysRequest(ctx, http.MethodPost, set, k)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req, &jsonKeySet)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		return jsonKeySet, nil
+	default:
+		return nil, statusError(req, resp, fmt.Errorf("%s %s http request returned unexpected status code: %s", req.Method, req.URL, resp.Status))
+	}
+}
+
+func (c *InternalClient) DeleteJSONWebKey(ctx context.Context, set, kid string) error {
+	return c.deleteKeys(ctx, path.Join(set, kid))
+}
+
+func (c *InternalClient) DeleteJSONWebKeySet(ctx context.Context, set string) error {
+	return c.deleteKeys(ctx, set)
+}
+
+func (c *InternalClient) deleteKeys(ctx context.Context, relativePath string) error {
+	req, err := c.newKeysRequest(ctx, http.MethodDelete, relativePath, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req, nil)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return statusError(req, resp, fmt.Errorf("%s %s http request returned unexpected status code %s", req.Method, req.URL.String(), resp.Status))
+	}
+}
+
+func (c *InternalClient) newKeysRequest(ctx context.Context, method, relativePath string, body interface{}) (*http.Request, error) {
+	return c.newAdminRequest(ctx, method, Version.KeysPath, relativePath, body)
+}
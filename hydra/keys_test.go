@@ -0,0 +1,190 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hydra_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/hydra-maester/hydra"
+)
+
+const (
+	testSetName = "hydra.openid.id-token"
+	testKeySet  = `{"keys":[{"kty":"RSA","kid":"key-1","alg":"RS256","use":"sig","n":"modulus","e":"AQAB","d":"private","p":"prime1","q":"prime2","dp":"exp1","dq":"exp2","qi":"coef"},{"kty":"oct","kid":"key-2","alg":"HS256","use":"sig","k":"secret"}]}`
+)
+
+func TestKeys(t *testing.T) {
+
+	assert := assert.New(t)
+
+	c := hydra.InternalClient{
+		HTTPClient: &http.Client{},
+		HydraURL:   url.URL{Scheme: schemeHTTP},
+	}
+
+	t.Run("method=get", func(t *testing.T) {
+
+		for d, tc := range map[string]server{
+			"with existing set": {
+				statusCode: http.StatusOK,
+				respBody:   testKeySet,
+			},
+			"with missing set": {
+				statusCode: http.StatusNotFound,
+				respBody:   statusNotFoundBody,
+			},
+			"internal server error when requesting": {
+				statusCode: http.StatusInternalServerError,
+				respBody:   statusInternalServerErrorBody,
+				err:        errors.New("http request returned unexpected status code"),
+			},
+		} {
+			t.Run(fmt.Sprintf("case/%s", d), func(t *testing.T) {
+
+				//given
+				h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					assert.Equal("/admin/keys/"+testSetName, req.URL.Path)
+					assert.Equal(http.MethodGet, req.Method)
+					w.WriteHeader(tc.statusCode)
+					w.Write([]byte(tc.respBody))
+				})
+				runServer(&c, h)
+
+				//when
+				set, found, err := c.GetJSONWebKeySet(context.Background(), testSetName)
+
+				//then
+				if tc.err != nil {
+					require.Error(t, err)
+					assert.Contains(err.Error(), tc.err.Error())
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(tc.statusCode == http.StatusOK, found)
+				if found {
+					require.Len(t, set.Keys, 2)
+					assert.Equal("key-1", set.Keys[0].KeyID())
+					assert.Equal("RS256", set.Keys[0].Algorithm())
+					assert.Equal("sig", set.Keys[0].Use())
+				}
+			})
+		}
+	})
+
+	t.Run("method=create", func(t *testing.T) {
+
+		for d, tc := range map[string]server{
+			"with created key": {
+				statusCode: http.StatusCreated,
+				respBody:   testKeySet,
+			},
+			"with invalid algorithm": {
+				statusCode: http.StatusBadRequest,
+				respBody:   `{"error":"invalid_request"}`,
+				err:        errors.New("http request returned unexpected status code"),
+			},
+		} {
+			t.Run(fmt.Sprintf("case/%s", d), func(t *testing.T) {
+
+				//given
+				h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					assert.Equal("/admin/keys/"+testSetName, req.URL.Path)
+					assert.Equal(http.MethodPost, req.Method)
+
+					var body map[string]string
+					require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+					assert.Equal(map[string]string{"alg": "RS256", "kid": "key-1", "use": "sig"}, body)
+
+					w.WriteHeader(tc.statusCode)
+					w.Write([]byte(tc.respBody))
+				})
+				runServer(&c, h)
+
+				//when
+				set, err := c.CreateJSONWebKey(context.Background(), testSetName, &hydra.JSONWebKeyRequest{
+					Algorithm: "RS256",
+					KeyID:     "key-1",
+					Use:       "sig",
+				})
+
+				//then
+				if tc.err != nil {
+					require.Error(t, err)
+					assert.Contains(err.Error(), tc.err.Error())
+					return
+				}
+
+				require.NoError(t, err)
+				assert.NotNil(set.Key("key-1"))
+			})
+		}
+	})
+
+	t.Run("method=delete", func(t *testing.T) {
+
+		for d, tc := range map[string]server{
+			"with existing key": {
+				statusCode: http.StatusNoContent,
+			},
+			"with missing key": {
+				statusCode: http.StatusNotFound,
+				respBody:   statusNotFoundBody,
+			},
+			"internal server error when requesting": {
+				statusCode: http.StatusInternalServerError,
+				respBody:   statusInternalServerErrorBody,
+				err:        errors.New("http request returned unexpected status code"),
+			},
+		} {
+			t.Run(fmt.Sprintf("case/%s", d), func(t *testing.T) {
+
+				//given
+				var paths []string
+				h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					assert.Equal(http.MethodDelete, req.Method)
+					paths = append(paths, req.URL.Path)
+					w.WriteHeader(tc.statusCode)
+				})
+				runServer(&c, h)
+
+				//when
+				keyErr := c.DeleteJSONWebKey(context.Background(), testSetName, "key-1")
+				setErr := c.DeleteJSONWebKeySet(context.Background(), testSetName)
+
+				//then
+				assert.Equal([]string{"/admin/keys/" + testSetName + "/key-1", "/admin/keys/" + testSetName}, paths)
+				for _, err := range []error{keyErr, setErr} {
+					if tc.err == nil {
+						require.NoError(t, err)
+					} else {
+						require.Error(t, err)
+						assert.Contains(err.Error(), tc.err.Error())
+					}
+				}
+			})
+		}
+	})
+}
+
+func TestJSONWebKeySetPublic(t *testing.T) {
+	var set hydra.JSONWebKeySet
+	require.NoError(t, json.Unmarshal([]byte(testKeySet), &set))
+
+	b, err := json.Marshal(set.Public())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"keys":[{"kty":"RSA","kid":"key-1","alg":"RS256","use":"sig","n":"modulus","e":"AQAB"}]}`, string(b))
+
+	// the set itself is left untouched
+	assert.Equal(t, "private", set.Key("key-1")["d"])
+}
@@ -0,0 +1,114 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hydra
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+)
+
+// OAuth2ClientLifespans represents the token lifespans of an OAuth2 client,
+// as managed by ORY Hydra's /admin/clients/{id}/lifespans endpoint
+type OAuth2ClientLifespans struct {
+	AuthorizationCodeGrantAccessTokenLifespan    string `json:"authorization_code_grant_access_token_lifespan,omitempty"`
+	AuthorizationCodeGrantIdTokenLifespan        string `json:"authorization_code_grant_id_token_lifespan,omitempty"`
+	AuthorizationCodeGrantRefreshTokenLifespan   string `json:"authorization_code_grant_refresh_token_lifespan,omitempty"`
+	ClientCredentialsGrantAccessTokenLifespan    string `json:"client_credentials_grant_access_token_lifespan,omitempty"`
+	DeviceAuthorizationGrantAccessTokenLifespan  string `json:"device_authorization_grant_access_token_lifespan,omitempty"`
+	DeviceAuthorizationGrantIdTokenLifespan      string `json:"device_authorization_grant_id_token_lifespan,omitempty"`
+	DeviceAuthorizationGrantRefreshTokenLifespan string `json:"device_authorization_grant_refresh_token_lifespan,omitempty"`
+	ImplicitGrantAccessTokenLifespan             string `json:"implicit_grant_access_token_lifespan,omitempty"`
+	ImplicitGrantIdTokenLifespan                 string `json:"implicit_grant_id_token_lifespan,omitempty"`
+	JwtBearerGrantAccessTokenLifespan            string `json:"jwt_bearer_grant_access_token_lifespan,omitempty"`
+	RefreshTokenGrantAccessTokenLifespan         string `json:"refresh_token_grant_access_token_lifespan,omitempty"`
+	RefreshTokenGrantIdTokenLifespan             string `json:"refresh_token_grant_id_token_lifespan,omitempty"`
+	RefreshTokenGrantRefreshTokenLifespan        string `json:"refresh_token_grant_refresh_token_lifespan,omitempty"`
+}
+
+// LifespansFromTokenLifespans converts the token lifespans of an OAuth2Client.
+func LifespansFromTokenLifespans(t hydrav1alpha1.TokenLifespans) OAuth2ClientLifespans {
+	return OAuth2ClientLifespans{
+		AuthorizationCodeGrantAccessTokenLifespan:    t.AuthorizationCodeGrantAccessTokenLifespan,
+		AuthorizationCodeGrantIdTokenLifespan:        t.AuthorizationCodeGrantIdTokenLifespan,
+		AuthorizationCodeGrantRefreshTokenLifespan:   t.AuthorizationCodeGrantRefreshTokenLifespan,
+		ClientCredentialsGrantAccessTokenLifespan:    t.ClientCredentialsGrantAccessTokenLifespan,
+		DeviceAuthorizationGrantAccessTokenLifespan:  t.DeviceAuthorizationGrantAccessTokenLifespan,
+		DeviceAuthorizationGrantIdTokenLifespan:      t.DeviceAuthorizationGrantIdTokenLifespan,
+		DeviceAuthorizationGrantRefreshTokenLifespan: t.DeviceAuthorizationGrantRefreshTokenLifespan,
+		ImplicitGrantAccessTokenLifespan:             t.ImplicitGrantAccessTokenLifespan,
+		ImplicitGrantIdTokenLifespan:                 t.ImplicitGrantIdTokenLifespan,
+		JwtBearerGrantAccessTokenLifespan:            t.JwtBearerGrantAccessTokenLifespan,
+		RefreshTokenGrantAccessTokenLifespan:         t.RefreshTokenGrantAccessTokenLifespan,
+		RefreshTokenGrantIdTokenLifespan:             t.RefreshTokenGrantIdTokenLifespan,
+		RefreshTokenGrantRefreshTokenLifespan:        t.RefreshTokenGrantRefreshTokenLifespan,
+	}
+}
+
+// IsZero returns true if no lifespan is set.
+func (l OAuth2ClientLifespans) IsZero() bool {
+	return l == OAuth2ClientLifespans{}
+}
+
+// Range calls f with the JSON name and the value of each set lifespan.
+func (l OAuth2ClientLifespans) Range(f func(name, value string)) {
+	for _, lifespan := range l.all() {
+		if lifespan[1] != "" {
+			f(lifespan[0], lifespan[1])
+		}
+	}
+}
+
+func (l OAuth2ClientLifespans) all() [][2]string {
+	return [][2]string{
+		{"authorization_code_grant_access_token_lifespan", l.AuthorizationCodeGrantAccessTokenLifespan},
+		{"authorization_code_grant_id_token_lifespan", l.AuthorizationCodeGrantIdTokenLifespan},
+		{"authorization_code_grant_refresh_token_lifespan", l.AuthorizationCodeGrantRefreshTokenLifespan},
+		{"client_credentials_grant_access_token_lifespan", l.ClientCredentialsGrantAccessTokenLifespan},
+		{"device_authorization_grant_access_token_lifespan", l.DeviceAuthorizationGrantAccessTokenLifespan},
+		{"device_authorization_grant_id_token_lifespan", l.DeviceAuthorizationGrantIdTokenLifespan},
+		{"device_authorization_grant_refresh_token_lifespan", l.DeviceAuthorizationGrantRefreshTokenLifespan},
+		{"implicit_grant_access_token_lifespan", l.ImplicitGrantAccessTokenLifespan},
+		{"implicit_grant_id_token_lifespan", l.ImplicitGrantIdTokenLifespan},
+		{"jwt_bearer_grant_access_token_lifespan", l.JwtBearerGrantAccessTokenLifespan},
+		{"refresh_token_grant_access_token_lifespan", l.RefreshTokenGrantAccessTokenLifespan},
+		{"refresh_token_grant_id_token_lifespan", l.RefreshTokenGrantIdTokenLifespan},
+		{"refresh_token_grant_refresh_token_lifespan", l.RefreshTokenGrantRefreshTokenLifespan},
+	}
+}
+
+// LifespanBounds are the minimum and maximum token lifespans clients may set.
+// A zero Max does not limit lifespans.
+type LifespanBounds struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// Validate checks that all the set lifespans are positive Go durations within
+// the bounds.
+func (b LifespanBounds) Validate(l OAuth2ClientLifespans) error {
+	var errs []error
+	for _, lifespan := range l.all() {
+		name, value := lifespan[0], lifespan[1]
+		if value == "" {
+			continue
+		}
+
+		d, err := time.ParseDuration(value)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		case d <= 0:
+			errs = append(errs, fmt.Errorf("%s: %s is not positive", name, value))
+		case d < b.Min:
+			errs = append(errs, fmt.Errorf("%s: %s is below the minimum of %s", name, value, b.Min))
+		case b.Max > 0 && d > b.Max:
+			errs = append(errs, fmt.Errorf("%s: %s exceeds the maximum of %s", name, value, b.Max))
+		}
+	}
+
+	return errors.Join(errs...)
+}
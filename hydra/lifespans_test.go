@@ -0,0 +1,58 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hydra_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+func TestLifespanBounds(t *testing.T) {
+	bounds := hydra.LifespanBounds{Min: time.Minute, Max: 24 * time.Hour}
+
+	for d, tc := range map[string]struct {
+		lifespan string
+		err      string
+	}{
+		"empty":             {"", ""},
+		"simple duration":   {"1h", ""},
+		"compound":          {"1h30m", ""},
+		"fractional":        {"1.5h", ""},
+		"invalid":           {"invalid", `time: invalid duration "invalid"`},
+		"zero":              {"0s", "0s is not positive"},
+		"below the minimum": {"30s", "30s is below the minimum of 1m0s"},
+		"above the maximum": {"25h", "25h exceeds the maximum of 24h0m0s"},
+	} {
+		t.Run("case="+d, func(t *testing.T) {
+			err := bounds.Validate(hydra.LifespansFromTokenLifespans(hydrav1alpha1.TokenLifespans{
+				RefreshTokenGrantRefreshTokenLifespan: tc.lifespan,
+			}))
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, "refresh_token_grant_refresh_token_lifespan: "+tc.err)
+			}
+		})
+	}
+
+	t.Run("case=unbounded maximum", func(t *testing.T) {
+		assert.NoError(t, hydra.LifespanBounds{}.Validate(hydra.OAuth2ClientLifespans{
+			ImplicitGrantAccessTokenLifespan: "8760h",
+		}))
+	})
+
+	t.Run("case=report all invalid lifespans", func(t *testing.T) {
+		err := bounds.Validate(hydra.OAuth2ClientLifespans{
+			AuthorizationCodeGrantAccessTokenLifespan: "0s",
+			JwtBearerGrantAccessTokenLifespan:         "48h",
+		})
+		assert.EqualError(t, err, "authorization_code_grant_access_token_lifespan: 0s is not positive\n"+
+			"jwt_bearer_grant_access_token_lifespan: 48h exceeds the maximum of 24h0m0s")
+	})
+}
@@ -0,0 +1,37 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hydra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// RevokeOAuth2ClientTokens revokes all the access and refresh tokens issued to
+// the client.
+func (c *InternalClient) RevokeOAuth2ClientTokens(ctx context.Context, id string) error {
+	return c.revoke(ctx, Version.TokensPath, url.Values{"client_id": {id}})
+}
+
+func (c *InternalClient) revoke(ctx context.Context, apiPath func(Version) string, query url.Values) error {
+	req, err := c.newAdminRequest(ctx, http.MethodDelete, apiPath, "", nil)
+	if err != nil {
+		return err
+	}
+	req.URL.RawQuery = query.Encode()
+
+	resp, err := c.do(req, nil)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil
+	default:
+		return statusError(req, resp, fmt.Errorf("%s %s http request returned unexpected status code %s", req.Method, req.URL.String(), resp.Status))
+	}
+}
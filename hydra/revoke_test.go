@@ -0,0 +1,64 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hydra_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/hydra-maester/hydra"
+)
+
+func TestRevoke(t *testing.T) {
+
+	assert := assert.New(t)
+
+	c := hydra.InternalClient{
+		HTTPClient: &http.Client{},
+		HydraURL:   url.URL{Scheme: schemeHTTP},
+	}
+
+	for d, tc := range map[string]server{
+		"with revoked tokens": {
+			statusCode: http.StatusNoContent,
+		},
+		"internal server error when requesting": {
+			statusCode: http.StatusInternalServerError,
+			respBody:   statusInternalServerErrorBody,
+			err:        errors.New("http request returned unexpected status code"),
+		},
+	} {
+		t.Run(fmt.Sprintf("case/%s", d), func(t *testing.T) {
+
+			//given
+			var requests []string
+			h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				assert.Equal(http.MethodDelete, req.Method)
+				requests = append(requests, req.URL.Path+"?"+req.URL.RawQuery)
+				w.WriteHeader(tc.statusCode)
+				w.Write([]byte(tc.respBody))
+			})
+			runServer(&c, h)
+
+			//when
+			err := c.RevokeOAuth2ClientTokens(context.Background(), "test-client")
+
+			//then
+			assert.Equal([]string{"/admin/oauth2/tokens?client_id=test-client"}, requests)
+			if tc.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(err.Error(), tc.err.Error())
+			}
+		})
+	}
+}
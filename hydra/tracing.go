@@ -0,0 +1,61 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hydra
+
+import (
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the tracer of the requests sent to ORY Hydra.
+const TracerName = "github.com/ory/hydra-maester/hydra"
+
+// startSpan starts the span of req as a child of the span of its context and
+// propagates it to ORY Hydra in the headers of req, so that the traces of ORY
+// Hydra join the ones of the controller. req is updated to carry the context
+// of the span. Requests that are not part of a trace, such as the readiness
+// checks, are not traced.
+func startSpan(req *http.Request) (*http.Request, trace.Span) {
+	if !trace.SpanContextFromContext(req.Context()).IsValid() {
+		return req, trace.SpanFromContext(req.Context())
+	}
+
+	port, _ := strconv.Atoi(req.URL.Port())
+	ctx, span := otel.Tracer(TracerName).Start(req.Context(), req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLFull(req.URL.String()),
+			semconv.ServerAddress(req.URL.Hostname()),
+			semconv.ServerPort(port),
+		),
+	)
+	req = req.WithContext(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return req, span
+}
+
+// endSpan ends the span of a request sent to ORY Hydra, which failed if err
+// is set or ORY Hydra answered with an error status code.
+func endSpan(span trace.Span, resp *http.Response, err error) {
+	defer span.End()
+	if resp != nil {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+		if resp.StatusCode >= 400 {
+			span.SetAttributes(semconv.ErrorTypeKey.String(strconv.Itoa(resp.StatusCode)))
+			span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+			return
+		}
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hydra_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/ory/hydra-maester/hydra"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	c := hydra.InternalClient{HTTPClient: &http.Client{}}
+
+	for desc, tc := range map[string]struct {
+		statusCode int
+		failed     bool
+	}{
+		"with a successful request": {statusCode: http.StatusNoContent},
+		"with a failed request":     {statusCode: http.StatusInternalServerError, failed: true},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			exporter.Reset()
+
+			var traceparent string
+			runServer(&c, func(w http.ResponseWriter, req *http.Request) {
+				traceparent = req.Header.Get("traceparent")
+				w.WriteHeader(tc.statusCode)
+			})
+
+			ctx, parent := provider.Tracer("test").Start(context.Background(), "Reconcile")
+			_ = c.DeleteOAuth2Client(ctx, testID)
+			parent.End()
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 2)
+			span := spans[0]
+			assert.Equal(t, http.MethodDelete, span.Name)
+			assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+			assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext.TraceID())
+			assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+			assert.Contains(t, span.Attributes, attribute.Int("http.response.status_code", tc.statusCode))
+			if tc.failed {
+				assert.Equal(t, codes.Error, span.Status.Code)
+			} else {
+				assert.Equal(t, codes.Unset, span.Status.Code)
+			}
+
+			// the span is propagated to ORY Hydra
+			assert.Equal(t, "00-"+span.SpanContext.TraceID().String()+"-"+span.SpanContext.SpanID().String()+"-01", traceparent)
+		})
+	}
+
+	t.Run("without a trace", func(t *testing.T) {
+		exporter.Reset()
+
+		var traceparent string
+		runServer(&c, func(w http.ResponseWriter, req *http.Request) {
+			traceparent = req.Header.Get("traceparent")
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		require.NoError(t, c.DeleteOAuth2Client(context.Background(), testID))
+		assert.Empty(t, exporter.GetSpans())
+		assert.Empty(t, traceparent)
+	})
+}
@@ -0,0 +1,121 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hydra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+)
+
+// TrustClient manages ORY Hydra's trust relationships with the issuers of
+// the jwt-bearer grant type.
+type TrustClient interface {
+	GetTrustedJwtGrantIssuer(ctx context.Context, id string) (*TrustedJwtGrantIssuerJSON, bool, error)
+	CreateTrustedJwtGrantIssuer(ctx context.Context, t *TrustJwtGrantIssuerJSON) (*TrustedJwtGrantIssuerJSON, error)
+	DeleteTrustedJwtGrantIssuer(ctx context.Context, id string) error
+}
+
+// NewTrustClient returns a TrustClient for the given ORY Hydra admin server.
+func NewTrustClient(admin hydrav1alpha1.HydraAdmin, tlsTrustStore string, insecureSkipVerify bool) (TrustClient, error) {
+	client, err := NewInternalClient(admin, tlsTrustStore, insecureSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// TrustJwtGrantIssuerJSON represents a trust relationship to create in ORY
+// Hydra
+type TrustJwtGrantIssuerJSON struct {
+	Issuer          string     `json:"issuer"`
+	Subject         string     `json:"subject,omitempty"`
+	AllowAnySubject bool       `json:"allow_any_subject,omitempty"`
+	Scope           []string   `json:"scope"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	JWK             JSONWebKey `json:"jwk"`
+}
+
+// TrustedJwtGrantIssuerJSON represents a trust relationship as returned by
+// ORY Hydra
+type TrustedJwtGrantIssuerJSON struct {
+	ID              string    `json:"id"`
+	Issuer          string    `json:"issuer"`
+	Subject         string    `json:"subject,omitempty"`
+	AllowAnySubject bool      `json:"allow_any_subject,omitempty"`
+	Scope           []string  `json:"scope"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	PublicKey       struct {
+		Set   string `json:"set"`
+		KeyID string `json:"kid"`
+	} `json:"public_key"`
+}
+
+func (c *InternalClient) GetTrustedJwtGrantIssuer(ctx context.Context, id string) (*TrustedJwtGrantIssuerJSON, bool, error) {
+	var jsonIssuer *TrustedJwtGrantIssuerJSON
+
+	req, err := c.newAdminRequest(ctx, http.MethodGet, Version.TrustPath, id, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, err := c.do(req, &jsonIssuer)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return jsonIssuer, true, nil
+	case http.StatusNotFound:
+		return nil, false, nil
+	default:
+		return nil, false, statusError(req, resp, fmt.Errorf("%s %s http request returned unexpected status code %s", req.Method, req.URL.String(), resp.Status))
+	}
+}
+
+func (c *InternalClient) CreateTrustedJwtGrantIssuer(ctx context.Context, t *TrustJwtGrantIssuerJSON) (*TrustedJwtGrantIssuerJSON, error) {
+	var jsonIssuer *TrustedJwtGrantIssuerJSON
+
+	req, err := c.newAdminRequest(ctx, http.MethodPost, Version.TrustPath, "", t)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req, &jsonIssuer)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		return jsonIssuer, nil
+	case http.StatusConflict:
+		return nil, statusError(req, resp, fmt.Errorf("%s %s http request failed: requested trust relationship for issuer %s already exists", req.Method, req.URL, t.Issuer))
+	default:
+		return nil, statusError(req, resp, fmt.Errorf("%s %s http request returned unexpected status code: %s", req.Method, req.URL, resp.Status))
+	}
+}
+
+func (c *InternalClient) DeleteTrustedJwtGrantIssuer(ctx context.Context, id string) error {
+	req, err := c.newAdminRequest(ctx, http.MethodDelete, Version.TrustPath, id, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req, nil)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return statusError(req, resp, fmt.Errorf("%s %s http request returned unexpected status code %s", req.Method, req.URL.String(), resp.Status))
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hydra_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/hydra-maester/hydra"
+)
+
+const (
+	trustEndpoint = "/admin/trust/grants/jwt-bearer/issuers"
+	testTrustID   = "trust-id"
+	testTrust     = `{"id":"trust-id","issuer":"https://issuer","subject":"alice","scope":["read"],"expires_at":"2030-01-01T00:00:00Z","public_key":{"set":"https://issuer","kid":"key-1"}}`
+)
+
+func TestTrust(t *testing.T) {
+
+	assert := assert.New(t)
+
+	c := hydra.InternalClient{
+		HTTPClient: &http.Client{},
+		HydraURL:   url.URL{Scheme: schemeHTTP},
+	}
+
+	t.Run("method=get", func(t *testing.T) {
+
+		for d, tc := range map[string]server{
+			"with existing trust relationship": {
+				statusCode: http.StatusOK,
+				respBody:   testTrust,
+			},
+			"with missing trust relationship": {
+				statusCode: http.StatusNotFound,
+				respBody:   statusNotFoundBody,
+			},
+			"internal server error when requesting": {
+				statusCode: http.StatusInternalServerError,
+				respBody:   statusInternalServerErrorBody,
+				err:        errors.New("http request returned unexpected status code"),
+			},
+		} {
+			t.Run(fmt.Sprintf("case/%s", d), func(t *testing.T) {
+
+				//given
+				h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					assert.Equal(trustEndpoint+"/"+testTrustID, req.URL.Path)
+					assert.Equal(http.MethodGet, req.Method)
+					w.WriteHeader(tc.statusCode)
+					w.Write([]byte(tc.respBody))
+				})
+				runServer(&c, h)
+
+				//when
+				trust, found, err := c.GetTrustedJwtGrantIssuer(context.Background(), testTrustID)
+
+				//then
+				if tc.err != nil {
+					require.Error(t, err)
+					assert.Contains(err.Error(), tc.err.Error())
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(tc.statusCode == http.StatusOK, found)
+				if found {
+					assert.Equal(testTrustID, trust.ID)
+					assert.Equal("key-1", trust.PublicKey.KeyID)
+				}
+			})
+		}
+	})
+
+	t.Run("method=create", func(t *testing.T) {
+
+		for d, tc := range map[string]server{
+			"with created trust relationship": {
+				statusCode: http.StatusCreated,
+				respBody:   testTrust,
+			},
+			"with existing trust relationship": {
+				statusCode: http.StatusConflict,
+				respBody:   statusConflictBody,
+				err:        errors.New("requested trust relationship for issuer https://issuer already exists"),
+			},
+		} {
+			t.Run(fmt.Sprintf("case/%s", d), func(t *testing.T) {
+
+				//given
+				h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					assert.Equal(trustEndpoint, req.URL.Path)
+					assert.Equal(http.MethodPost, req.Method)
+
+					var body map[string]interface{}
+					require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+					assert.Equal(map[string]interface{}{
+						"issuer":     "https://issuer",
+						"subject":    "alice",
+						"scope":      []interface{}{"read"},
+						"expires_at": "2030-01-01T00:00:00Z",
+						"jwk":        map[string]interface{}{"kty": "RSA", "kid": "key-1", "n": "modulus", "e": "AQAB"},
+					}, body)
+
+					w.WriteHeader(tc.statusCode)
+					w.Write([]byte(tc.respBody))
+				})
+				runServer(&c, h)
+
+				//when
+				trust, err := c.CreateTrustedJwtGrantIssuer(context.Background(), &hydra.TrustJwtGrantIssuerJSON{
+					Issuer:    "https://issuer",
+					Subject:   "alice",
+					Scope:     []string{"read"},
+					ExpiresAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+					JWK:       hydra.JSONWebKey{"kty": "RSA", "kid": "key-1", "n": "modulus", "e": "AQAB"},
+				})
+
+				//then
+				if tc.err != nil {
+					require.Error(t, err)
+					assert.Contains(err.Error(), tc.err.Error())
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(testTrustID, trust.ID)
+			})
+		}
+	})
+
+	t.Run("method=delete", func(t *testing.T) {
+
+		for d, tc := range map[string]server{
+			"with existing trust relationship": {
+				statusCode: http.StatusNoContent,
+			},
+			"with missing trust relationship": {
+				statusCode: http.StatusNotFound,
+				respBody:   statusNotFoundBody,
+			},
+			"internal server error when requesting": {
+				statusCode: http.StatusInternalServerError,
+				respBody:   statusInternalServerErrorBody,
+				err:        errors.New("http request returned unexpected status code"),
+			},
+		} {
+			t.Run(fmt.Sprintf("case/%s", d), func(t *testing.T) {
+
+				//given
+				h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					assert.Equal(trustEndpoint+"/"+testTrustID, req.URL.Path)
+					assert.Equal(http.MethodDelete, req.Method)
+					w.WriteHeader(tc.statusCode)
+				})
+				runServer(&c, h)
+
+				//when
+				err := c.DeleteTrustedJwtGrantIssuer(context.Background(), testTrustID)
+
+				//then
+				if tc.err == nil {
+					require.NoError(t, err)
+				} else {
+					require.Error(t, err)
+					assert.Contains(err.Error(), tc.err.Error())
+				}
+			})
+		}
+	})
+}
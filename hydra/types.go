@@ -4,10 +4,12 @@
 package hydra
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
 
+	"github.com/go-logr/logr"
 	"github.com/go-playground/validator/v10"
 
 	"k8s.io/utils/ptr"
@@ -17,53 +19,45 @@ import (
 
 // OAuth2ClientJSON represents an OAuth2 client digestible by ORY Hydra
 type OAuth2ClientJSON struct {
-	ClientName                                 string          `json:"client_name,omitempty"`
-	ClientID                                   *string         `json:"client_id,omitempty"`
-	Secret                                     *string         `json:"client_secret,omitempty"`
-	GrantTypes                                 []string        `json:"grant_types"`
-	RedirectURIs                               []string        `json:"redirect_uris,omitempty"`
-	RequestURIs                                []string        `json:"request_uris,omitempty"`
-	PostLogoutRedirectURIs                     []string        `json:"post_logout_redirect_uris,omitempty"`
-	AllowedCorsOrigins                         []string        `json:"allowed_cors_origins,omitempty"`
-	ResponseTypes                              []string        `json:"response_types,omitempty"`
-	Audience                                   []string        `json:"audience,omitempty"`
-	Scope                                      string          `json:"scope"`
-	SkipConsent                                bool            `json:"skip_consent,omitempty"`
-	Owner                                      string          `json:"owner"`
-	TokenEndpointAuthMethod                    string          `json:"token_endpoint_auth_method,omitempty"`
-	Metadata                                   json.RawMessage `json:"metadata,omitempty"`
-	JwksUri                                    string          `json:"jwks_uri,omitempty" validate:"required_if=TokenEndpointAuthMethod private_key_jwt"`
-	FrontChannelLogoutSessionRequired          bool            `json:"frontchannel_logout_session_required"`
-	FrontChannelLogoutURI                      string          `json:"frontchannel_logout_uri"`
-	BackChannelLogoutSessionRequired           bool            `json:"backchannel_logout_session_required"`
-	BackChannelLogoutURI                       string          `json:"backchannel_logout_uri"`
-	AuthorizationCodeGrantAccessTokenLifespan  string          `json:"authorization_code_grant_access_token_lifespan,omitempty"`
-	AuthorizationCodeGrantIdTokenLifespan      string          `json:"authorization_code_grant_id_token_lifespan,omitempty"`
-	AuthorizationCodeGrantRefreshTokenLifespan string          `json:"authorization_code_grant_refresh_token_lifespan,omitempty"`
-	ClientCredentialsGrantAccessTokenLifespan  string          `json:"client_credentials_grant_access_token_lifespan,omitempty"`
-	ImplicitGrantAccessTokenLifespan           string          `json:"implicit_grant_access_token_lifespan,omitempty"`
-	ImplicitGrantIdTokenLifespan               string          `json:"implicit_grant_id_token_lifespan,omitempty"`
-	JwtBearerGrantAccessTokenLifespan          string          `json:"jwt_bearer_grant_access_token_lifespan,omitempty"`
-	RefreshTokenGrantAccessTokenLifespan       string          `json:"refresh_token_grant_access_token_lifespan,omitempty"`
-	RefreshTokenGrantIdTokenLifespan           string          `json:"refresh_token_grant_id_token_lifespan,omitempty"`
-	RefreshTokenGrantRefreshTokenLifespan      string          `json:"refresh_token_grant_refresh_token_lifespan,omitempty"`
-	LogoUri                                    string          `json:"logo_uri,omitempty"`
-	AccessTokenStrategy                        string          `json:"access_token_strategy,omitempty"`
-	ClientSecretExpiresAt                      int64           `json:"client_secret_expires_at,omitempty"`
-	ClientUri                                  string          `json:"client_uri,omitempty"`
-	Contacts                                   []string        `json:"contacts,omitempty"`
-	CreatedAt                                  string          `json:"created_at,omitempty"`
-	PolicyUri                                  string          `json:"policy_uri,omitempty"`
-	RegistrationAccessToken                    string          `json:"registration_access_token,omitempty"`
-	RegistrationClientUri                      string          `json:"registration_client_uri,omitempty"`
-	RequestObjectSigningAlg                    string          `json:"request_object_signing_alg,omitempty"`
-	SectorIdentifierUri                        string          `json:"sector_identifier_uri,omitempty"`
-	SkipLogoutConsent                          bool            `json:"skip_logout_consent,omitempty"`
-	SubjectType                                string          `json:"subject_type,omitempty"`
-	TokenEndpointAuthSigningAlg                string          `json:"token_endpoint_auth_signing_alg,omitempty"`
-	TosUri                                     string          `json:"tos_uri,omitempty"`
-	UpdatedAt                                  string          `json:"updated_at,omitempty"`
-	UserinfoSignedResponseAlg                  string          `json:"userinfo_signed_response_alg,omitempty"`
+	ClientName                        string          `json:"client_name,omitempty"`
+	ClientID                          *string         `json:"client_id,omitempty"`
+	Secret                            *string         `json:"client_secret,omitempty"`
+	GrantTypes                        []string        `json:"grant_types"`
+	RedirectURIs                      []string        `json:"redirect_uris,omitempty"`
+	RequestURIs                       []string        `json:"request_uris,omitempty"`
+	PostLogoutRedirectURIs            []string        `json:"post_logout_redirect_uris,omitempty"`
+	AllowedCorsOrigins                []string        `json:"allowed_cors_origins,omitempty"`
+	ResponseTypes                     []string        `json:"response_types,omitempty"`
+	Audience                          []string        `json:"audience,omitempty"`
+	Scope                             string          `json:"scope"`
+	SkipConsent                       bool            `json:"skip_consent,omitempty"`
+	Owner                             string          `json:"owner"`
+	TokenEndpointAuthMethod           string          `json:"token_endpoint_auth_method,omitempty"`
+	Metadata                          json.RawMessage `json:"metadata,omitempty"`
+	JwksUri                           string          `json:"jwks_uri,omitempty" validate:"required_if=TokenEndpointAuthMethod private_key_jwt"`
+	Jwks                              *JSONWebKeySet  `json:"jwks,omitempty"`
+	FrontChannelLogoutSessionRequired bool            `json:"frontchannel_logout_session_required"`
+	FrontChannelLogoutURI             string          `json:"frontchannel_logout_uri"`
+	BackChannelLogoutSessionRequired  bool            `json:"backchannel_logout_session_required"`
+	BackChannelLogoutURI              string          `json:"backchannel_logout_uri"`
+	OAuth2ClientLifespans
+	LogoUri                     string   `json:"logo_uri,omitempty"`
+	AccessTokenStrategy         string   `json:"access_token_strategy,omitempty"`
+	ClientSecretExpiresAt       int64    `json:"client_secret_expires_at,omitempty"`
+	ClientUri                   string   `json:"client_uri,omitempty"`
+	Contacts                    []string `json:"contacts,omitempty"`
+	CreatedAt                   string   `json:"created_at,omitempty"`
+	PolicyUri                   string   `json:"policy_uri,omitempty"`
+	RegistrationAccessToken     string   `json:"registration_access_token,omitempty"`
+	RegistrationClientUri       string   `json:"registration_client_uri,omitempty"`
+	RequestObjectSigningAlg     string   `json:"request_object_signing_alg,omitempty"`
+	SectorIdentifierUri         string   `json:"sector_identifier_uri,omitempty"`
+	SkipLogoutConsent           bool     `json:"skip_logout_consent,omitempty"`
+	SubjectType                 string   `json:"subject_type,omitempty"`
+	TokenEndpointAuthSigningAlg string   `json:"token_endpoint_auth_signing_alg,omitempty"`
+	TosUri                      string   `json:"tos_uri,omitempty"`
+	UpdatedAt                   string   `json:"updated_at,omitempty"`
+	UserinfoSignedResponseAlg   string   `json:"userinfo_signed_response_alg,omitempty"`
 }
 
 // Oauth2ClientCredentials represents client ID and password fetched from a
@@ -81,15 +75,22 @@ func (oj *OAuth2ClientJSON) WithCredentials(credentials *Oauth2ClientCredentials
 	return oj
 }
 
-// FromOAuth2Client converts an OAuth2Client into a OAuth2ClientJSON object that represents an OAuth2 InternalClient digestible by ORY Hydra
-func FromOAuth2Client(c *hydrav1alpha1.OAuth2Client) (*OAuth2ClientJSON, error) {
+// WithJwks sets the JSON Web Key Set the client authenticates with.
+func (oj *OAuth2ClientJSON) WithJwks(jwks *JSONWebKeySet) *OAuth2ClientJSON {
+	oj.Jwks = jwks
+	return oj
+}
+
+// FromOAuth2Client converts an OAuth2Client into a OAuth2ClientJSON object that represents an OAuth2 InternalClient digestible by ORY Hydra.
+// Warnings are logged with the logger of ctx.
+func FromOAuth2Client(ctx context.Context, c *hydrav1alpha1.OAuth2Client) (*OAuth2ClientJSON, error) {
 	meta, err := json.Marshal(c.Spec.Metadata)
 	if err != nil {
 		return nil, fmt.Errorf("unable to encode `metadata` property value to json: %w", err)
 	}
 
 	if c.Spec.Scope != "" {
-		fmt.Println("Property `scope` in client '" + c.Name + "' is deprecated. Rather use scopeArray.")
+		logr.FromContextOrDiscard(ctx).Info("property `scope` is deprecated, use `scopeArray` instead")
 	}
 
 	var scope = c.Spec.Scope
@@ -116,33 +117,31 @@ func FromOAuth2Client(c *hydrav1alpha1.OAuth2Client) (*OAuth2ClientJSON, error)
 		FrontChannelLogoutSessionRequired: c.Spec.FrontChannelLogoutSessionRequired,
 		BackChannelLogoutSessionRequired:  c.Spec.BackChannelLogoutSessionRequired,
 		BackChannelLogoutURI:              c.Spec.BackChannelLogoutURI,
-		AuthorizationCodeGrantAccessTokenLifespan:  c.Spec.TokenLifespans.AuthorizationCodeGrantAccessTokenLifespan,
-		AuthorizationCodeGrantIdTokenLifespan:      c.Spec.TokenLifespans.AuthorizationCodeGrantIdTokenLifespan,
-		AuthorizationCodeGrantRefreshTokenLifespan: c.Spec.TokenLifespans.AuthorizationCodeGrantRefreshTokenLifespan,
-		ClientCredentialsGrantAccessTokenLifespan:  c.Spec.TokenLifespans.ClientCredentialsGrantAccessTokenLifespan,
-		ImplicitGrantAccessTokenLifespan:           c.Spec.TokenLifespans.ImplicitGrantAccessTokenLifespan,
-		ImplicitGrantIdTokenLifespan:               c.Spec.TokenLifespans.ImplicitGrantIdTokenLifespan,
-		JwtBearerGrantAccessTokenLifespan:          c.Spec.TokenLifespans.JwtBearerGrantAccessTokenLifespan,
-		RefreshTokenGrantAccessTokenLifespan:       c.Spec.TokenLifespans.RefreshTokenGrantAccessTokenLifespan,
-		RefreshTokenGrantIdTokenLifespan:           c.Spec.TokenLifespans.RefreshTokenGrantIdTokenLifespan,
-		RefreshTokenGrantRefreshTokenLifespan:      c.Spec.TokenLifespans.RefreshTokenGrantRefreshTokenLifespan,
-		LogoUri:                                    c.Spec.LogoUri,
-		AccessTokenStrategy:                        c.Spec.AccessTokenStrategy,
-		ClientSecretExpiresAt:                      c.Spec.ClientSecretExpiresAt,
-		ClientUri:                                  c.Spec.ClientUri,
-		Contacts:                                   c.Spec.Contacts,
-		PolicyUri:                                  c.Spec.PolicyUri,
-		RequestObjectSigningAlg:                    c.Spec.RequestObjectSigningAlg,
-		SectorIdentifierUri:                        c.Spec.SectorIdentifierUri,
-		SkipLogoutConsent:                          c.Spec.SkipLogoutConsent,
-		SubjectType:                                c.Spec.SubjectType,
-		TokenEndpointAuthSigningAlg:                c.Spec.TokenEndpointAuthSigningAlg,
-		TosUri:                                     c.Spec.TosUri,
-		UserinfoSignedResponseAlg:                  c.Spec.UserinfoSignedResponseAlg,
+		OAuth2ClientLifespans:             LifespansFromTokenLifespans(c.Spec.TokenLifespans),
+		LogoUri:                           c.Spec.LogoUri,
+		AccessTokenStrategy:               c.Spec.AccessTokenStrategy,
+		ClientSecretExpiresAt:             c.Spec.ClientSecretExpiresAt,
+		ClientUri:                         c.Spec.ClientUri,
+		Contacts:                          c.Spec.Contacts,
+		PolicyUri:                         c.Spec.PolicyUri,
+		RequestObjectSigningAlg:           c.Spec.RequestObjectSigningAlg,
+		SectorIdentifierUri:               c.Spec.SectorIdentifierUri,
+		SkipLogoutConsent:                 c.Spec.SkipLogoutConsent,
+		SubjectType:                       c.Spec.SubjectType,
+		TokenEndpointAuthSigningAlg:       c.Spec.TokenEndpointAuthSigningAlg,
+		TosUri:                            c.Spec.TosUri,
+		UserinfoSignedResponseAlg:         c.Spec.UserinfoSignedResponseAlg,
 	}
 
 	validate := validator.New()
-	if err := validate.Struct(client); err != nil {
+	if c.Spec.JwksFrom != nil || c.Spec.KeyPairGeneration != nil {
+		// the JSON Web Key Set taken from jwksFrom or generated replaces the
+		// jwks_uri, it is set by the caller, see WithJwks
+		err = validate.StructExcept(client, "JwksUri")
+	} else {
+		err = validate.Struct(client)
+	}
+	if err != nil {
 		return nil, err
 	}
 
@@ -4,11 +4,17 @@
 package hydra_test
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
 	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
 	"github.com/ory/hydra-maester/hydra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestTypes(t *testing.T) {
@@ -19,7 +25,7 @@ func TestTypes(t *testing.T) {
 			},
 		}
 
-		var parsedClient, err = hydra.FromOAuth2Client(&c)
+		var parsedClient, err = hydra.FromOAuth2Client(context.Background(), &c)
 		if err != nil {
 			assert.Fail(t, "unexpected error: %s", err)
 		}
@@ -34,13 +40,20 @@ func TestTypes(t *testing.T) {
 				ScopeArray: []string{"scope1", "scope2"},
 			},
 		}
+		var logged []string
+		ctx := logr.NewContext(context.Background(), funcr.New(func(_, args string) {
+			logged = append(logged, args)
+		}, funcr.Options{}))
 
-		var parsedClient, err = hydra.FromOAuth2Client(&c)
+		var parsedClient, err = hydra.FromOAuth2Client(ctx, &c)
 		if err != nil {
 			assert.Fail(t, "unexpected error: %s", err)
 		}
 
 		assert.Equal(t, "scope1 scope2 scope3", parsedClient.Scope)
+		// the deprecation of scope is logged with the logger of the reconciliation
+		require.Len(t, logged, 1)
+		assert.Contains(t, logged[0], "deprecated")
 	})
 
 	t.Run("Test having jwks uri", func(t *testing.T) {
@@ -50,7 +63,7 @@ func TestTypes(t *testing.T) {
 			},
 		}
 
-		var parsedClient, err = hydra.FromOAuth2Client(&c)
+		var parsedClient, err = hydra.FromOAuth2Client(context.Background(), &c)
 		if err != nil {
 			assert.Fail(t, "unexpected error: %s", err)
 		}
@@ -65,11 +78,33 @@ func TestTypes(t *testing.T) {
 			},
 		}
 
-		var _, err = hydra.FromOAuth2Client(&c)
+		var _, err = hydra.FromOAuth2Client(context.Background(), &c)
 
 		assert.ErrorContains(t, err, "JwksUri")
 	})
 
+	t.Run("Test jwks uri is not required when jwks are taken from a config map", func(t *testing.T) {
+		c := hydrav1alpha1.OAuth2Client{
+			Spec: hydrav1alpha1.OAuth2ClientSpec{
+				TokenEndpointAuthMethod: "private_key_jwt",
+				JwksFrom: &hydrav1alpha1.JwksSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "jwks"},
+						Key:                  "jwks.json",
+					},
+				},
+			},
+		}
+
+		var parsedClient, err = hydra.FromOAuth2Client(context.Background(), &c)
+		require.NoError(t, err)
+		assert.Empty(t, parsedClient.JwksUri)
+
+		b, err := json.Marshal(parsedClient.WithJwks(&hydra.JSONWebKeySet{Keys: []hydra.JSONWebKey{{"kid": "key-1"}}}))
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"jwks":{"keys":[{"kid":"key-1"}]}`)
+	})
+
 	t.Run("Test RequestURIs conversion", func(t *testing.T) {
 		c := hydrav1alpha1.OAuth2Client{
 			Spec: hydrav1alpha1.OAuth2ClientSpec{
@@ -80,7 +115,7 @@ func TestTypes(t *testing.T) {
 			},
 		}
 
-		var parsedClient, err = hydra.FromOAuth2Client(&c)
+		var parsedClient, err = hydra.FromOAuth2Client(context.Background(), &c)
 		if err != nil {
 			assert.Fail(t, "unexpected error: %s", err)
 		}
@@ -96,7 +131,7 @@ func TestTypes(t *testing.T) {
 			},
 		}
 
-		var parsedClient, err = hydra.FromOAuth2Client(&c)
+		var parsedClient, err = hydra.FromOAuth2Client(context.Background(), &c)
 		if err != nil {
 			assert.Fail(t, "unexpected error: %s", err)
 		}
@@ -117,7 +152,7 @@ func TestTypes(t *testing.T) {
 			},
 		}
 
-		var parsedClient, err = hydra.FromOAuth2Client(&c)
+		var parsedClient, err = hydra.FromOAuth2Client(context.Background(), &c)
 		if err != nil {
 			assert.Fail(t, "unexpected error: %s", err)
 		}
@@ -130,4 +165,27 @@ func TestTypes(t *testing.T) {
 		assert.Equal(t, true, parsedClient.SkipLogoutConsent)
 		assert.Equal(t, int64(1234567890), parsedClient.ClientSecretExpiresAt)
 	})
+
+	t.Run("Test device authorization grant", func(t *testing.T) {
+		c := hydrav1alpha1.OAuth2Client{
+			Spec: hydrav1alpha1.OAuth2ClientSpec{
+				GrantTypes: []hydrav1alpha1.GrantType{hydrav1alpha1.GrantTypeDeviceCode, "refresh_token"},
+				TokenLifespans: hydrav1alpha1.TokenLifespans{
+					DeviceAuthorizationGrantAccessTokenLifespan:  "1h",
+					DeviceAuthorizationGrantIdTokenLifespan:      "2h",
+					DeviceAuthorizationGrantRefreshTokenLifespan: "720h",
+				},
+			},
+		}
+
+		var parsedClient, err = hydra.FromOAuth2Client(context.Background(), &c)
+		if err != nil {
+			assert.Fail(t, "unexpected error: %s", err)
+		}
+
+		assert.Equal(t, []string{"urn:ietf:params:oauth:grant-type:device_code", "refresh_token"}, parsedClient.GrantTypes)
+		assert.Equal(t, "1h", parsedClient.DeviceAuthorizationGrantAccessTokenLifespan)
+		assert.Equal(t, "2h", parsedClient.DeviceAuthorizationGrantIdTokenLifespan)
+		assert.Equal(t, "720h", parsedClient.DeviceAuthorizationGrantRefreshTokenLifespan)
+	})
 }
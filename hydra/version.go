@@ -0,0 +1,133 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hydra
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+)
+
+var (
+	v2_0_0  = version.MustParseGeneric("2.0.0")
+	v2_2_0  = version.MustParseGeneric("2.2.0")
+	v2_3_0  = version.MustParseGeneric("2.3.0")
+	v25_4_0 = version.MustParseGeneric("25.4.0")
+)
+
+// fields lists the client fields that are not supported by all ORY Hydra
+// versions, with the version introducing them.
+var fields = []struct {
+	name  string
+	since *version.Version
+	isSet func(o *OAuth2ClientJSON) bool
+}{
+	{"access_token_strategy", v2_0_0, func(o *OAuth2ClientJSON) bool { return o.AccessTokenStrategy != "" }},
+	{"authorization_code_grant_access_token_lifespan", v2_0_0, func(o *OAuth2ClientJSON) bool { return o.AuthorizationCodeGrantAccessTokenLifespan != "" }},
+	{"authorization_code_grant_id_token_lifespan", v2_0_0, func(o *OAuth2ClientJSON) bool { return o.AuthorizationCodeGrantIdTokenLifespan != "" }},
+	{"authorization_code_grant_refresh_token_lifespan", v2_0_0, func(o *OAuth2ClientJSON) bool { return o.AuthorizationCodeGrantRefreshTokenLifespan != "" }},
+	{"client_credentials_grant_access_token_lifespan", v2_0_0, func(o *OAuth2ClientJSON) bool { return o.ClientCredentialsGrantAccessTokenLifespan != "" }},
+	{"device_authorization_grant_access_token_lifespan", v25_4_0, func(o *OAuth2ClientJSON) bool { return o.DeviceAuthorizationGrantAccessTokenLifespan != "" }},
+	{"device_authorization_grant_id_token_lifespan", v25_4_0, func(o *OAuth2ClientJSON) bool { return o.DeviceAuthorizationGrantIdTokenLifespan != "" }},
+	{"device_authorization_grant_refresh_token_lifespan", v25_4_0, func(o *OAuth2ClientJSON) bool { return o.DeviceAuthorizationGrantRefreshTokenLifespan != "" }},
+	{"grant_types: " + string(hydrav1alpha1.GrantTypeDeviceCode), v25_4_0, func(o *OAuth2ClientJSON) bool {
+		return slices.Contains(o.GrantTypes, string(hydrav1alpha1.GrantTypeDeviceCode))
+	}},
+	{"implicit_grant_access_token_lifespan", v2_0_0, func(o *OAuth2ClientJSON) bool { return o.ImplicitGrantAccessTokenLifespan != "" }},
+	{"implicit_grant_id_token_lifespan", v2_0_0, func(o *OAuth2ClientJSON) bool { return o.ImplicitGrantIdTokenLifespan != "" }},
+	{"jwt_bearer_grant_access_token_lifespan", v2_0_0, func(o *OAuth2ClientJSON) bool { return o.JwtBearerGrantAccessTokenLifespan != "" }},
+	{"refresh_token_grant_access_token_lifespan", v2_0_0, func(o *OAuth2ClientJSON) bool { return o.RefreshTokenGrantAccessTokenLifespan != "" }},
+	{"refresh_token_grant_id_token_lifespan", v2_0_0, func(o *OAuth2ClientJSON) bool { return o.RefreshTokenGrantIdTokenLifespan != "" }},
+	{"refresh_token_grant_refresh_token_lifespan", v2_0_0, func(o *OAuth2ClientJSON) bool { return o.RefreshTokenGrantRefreshTokenLifespan != "" }},
+	{"skip_consent", v2_2_0, func(o *OAuth2ClientJSON) bool { return o.SkipConsent }},
+	{"skip_logout_consent", v2_3_0, func(o *OAuth2ClientJSON) bool { return o.SkipLogoutConsent }},
+}
+
+// Version describes the API of an ORY Hydra version.
+type Version struct {
+	// Name is the version reported by ORY Hydra's /version endpoint.
+	Name string
+
+	// v is nil for versions that cannot be parsed, such as development
+	// builds, which are assumed to be the latest version.
+	v *version.Version
+}
+
+// ParseVersion parses a version reported by ORY Hydra.
+func ParseVersion(name string) Version {
+	v, _ := version.ParseGeneric(name)
+	return Version{Name: name, v: v}
+}
+
+func (v Version) atLeast(min *version.Version) bool {
+	return v.v == nil || v.v.AtLeast(min)
+}
+
+// ClientsPath returns the path of the admin API's clients endpoint.
+func (v Version) ClientsPath() string {
+	if v.atLeast(v2_0_0) {
+		return "/admin/clients"
+	}
+	return "/clients"
+}
+
+// KeysPath returns the path of the admin API's JSON Web Keys endpoint.
+func (v Version) KeysPath() string {
+	if v.atLeast(v2_0_0) {
+		return "/admin/keys"
+	}
+	return "/keys"
+}
+
+// TokensPath returns the path of the admin API's OAuth 2.0 tokens endpoint.
+func (v Version) TokensPath() string {
+	if v.atLeast(v2_0_0) {
+		return "/admin/oauth2/tokens"
+	}
+	return "/oauth2/tokens"
+}
+
+// TrustPath returns the path of the admin API's jwt-bearer grant issuers
+// endpoint.
+func (v Version) TrustPath() string {
+	if v.atLeast(v2_0_0) {
+		return "/admin/trust/grants/jwt-bearer/issuers"
+	}
+	return "/trust/grants/jwt-bearer/issuers"
+}
+
+// Unsupported returns the names of the fields set in o that this version
+// does not support.
+func (v Version) Unsupported(o *OAuth2ClientJSON) []string {
+	var unsupported []string
+	for _, f := range fields {
+		if f.isSet(o) && !v.atLeast(f.since) {
+			unsupported = append(unsupported, f.name)
+		}
+	}
+	return unsupported
+}
+
+// UnsupportedFieldsError is returned when a client sets fields that the ORY
+// Hydra version it is registered in does not support.
+type UnsupportedFieldsError struct {
+	Version string
+	Fields  []string
+}
+
+func (e *UnsupportedFieldsError) Error() string {
+	return fmt.Sprintf("fields %s are not supported by ORY Hydra %s", strings.Join(e.Fields, ", "), e.Version)
+}
+
+// IsUnsupported returns true if err is caused by fields that ORY Hydra does
+// not support.
+func IsUnsupported(err error) bool {
+	var unsupportedErr *UnsupportedFieldsError
+	return errors.As(err, &unsupportedErr)
+}
@@ -0,0 +1,198 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hydra_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+func TestVersion(t *testing.T) {
+	for name, tc := range map[string]struct {
+		clientsPath string
+		unsupported []string
+	}{
+		"v1.11.10": {"/clients", []string{"device_authorization_grant_access_token_lifespan", "grant_types: urn:ietf:params:oauth:grant-type:device_code", "refresh_token_grant_access_token_lifespan", "skip_consent"}},
+		"v2.1.2":   {"/admin/clients", []string{"device_authorization_grant_access_token_lifespan", "grant_types: urn:ietf:params:oauth:grant-type:device_code", "skip_consent"}},
+		"v2.3.0":   {"/admin/clients", []string{"device_authorization_grant_access_token_lifespan", "grant_types: urn:ietf:params:oauth:grant-type:device_code"}},
+		"v25.4.0":  {"/admin/clients", nil},
+		"master":   {"/admin/clients", nil},
+	} {
+		t.Run(fmt.Sprintf("version=%s", name), func(t *testing.T) {
+			v := hydra.ParseVersion(name)
+			assert.Equal(t, name, v.Name)
+			assert.Equal(t, tc.clientsPath, v.ClientsPath())
+			assert.Equal(t, tc.unsupported, v.Unsupported(&hydra.OAuth2ClientJSON{
+				SkipConsent: true,
+				GrantTypes:  []string{string(hydrav1alpha1.GrantTypeDeviceCode)},
+				OAuth2ClientLifespans: hydra.OAuth2ClientLifespans{
+					DeviceAuthorizationGrantAccessTokenLifespan: "1h",
+					RefreshTokenGrantAccessTokenLifespan:        "1h",
+				},
+			}))
+		})
+	}
+}
+
+func TestVersionDetection(t *testing.T) {
+	// version is the version served, or the status code /version fails with
+	newClient := func(t *testing.T, version string, basePath, endpoint string, requests *[]string) hydra.Client {
+		var mu sync.Mutex
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			*requests = append(*requests, req.Method+" "+req.URL.Path)
+			mu.Unlock()
+			switch {
+			case strings.HasSuffix(req.URL.Path, "/version"):
+				if code, err := strconv.Atoi(version); err == nil {
+					w.WriteHeader(code)
+					return
+				}
+				fmt.Fprintf(w, `{"version":%q}`, version)
+			case req.Method == http.MethodDelete:
+				w.WriteHeader(http.StatusNoContent)
+			case strings.Contains(req.URL.Path, "/keys/"):
+				w.Write([]byte(`{"keys":[]}`))
+			default:
+				w.Write([]byte(`[]`))
+			}
+		}))
+		t.Cleanup(srv.Close)
+
+		u, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		port, err := strconv.Atoi(u.Port())
+		require.NoError(t, err)
+
+		c, err := hydra.New(hydrav1alpha1.OAuth2ClientSpec{
+			HydraAdmin: hydrav1alpha1.HydraAdmin{
+				URL:      "http://" + u.Hostname() + basePath,
+				Port:     port,
+				Endpoint: endpoint,
+			},
+		}, "", false)
+		require.NoError(t, err)
+		return c
+	}
+
+	t.Run("should pick the clients endpoint of the version once", func(t *testing.T) {
+		var requests []string
+		c := newClient(t, "v1.11.10", "", "", &requests)
+
+		_, err := c.ListOAuth2Client(context.Background())
+		require.NoError(t, err)
+		_, err = c.ListOAuth2Client(context.Background())
+		require.NoError(t, err)
+
+		v, err := c.Version(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "v1.11.10", v)
+		assert.Equal(t, []string{"GET /version", "GET /clients", "GET /clients"}, requests)
+	})
+
+	t.Run("should keep the configured endpoint", func(t *testing.T) {
+		var requests []string
+		c := newClient(t, "v2.2.0", "", "/custom/clients", &requests)
+
+		_, err := c.ListOAuth2Client(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"GET /version", "GET /custom/clients"}, requests)
+	})
+
+	t.Run("should refuse unsupported fields", func(t *testing.T) {
+		var requests []string
+		c := newClient(t, "v2.1.2", "", "", &requests)
+
+		_, err := c.PostOAuth2Client(context.Background(), &hydra.OAuth2ClientJSON{SkipConsent: true})
+		require.Error(t, err)
+		assert.True(t, hydra.IsUnsupported(err))
+		assert.EqualError(t, err, "fields skip_consent are not supported by ORY Hydra v2.1.2")
+		assert.Equal(t, []string{"GET /version"}, requests)
+	})
+
+	t.Run("should query the version under the path of the admin URL", func(t *testing.T) {
+		var requests []string
+		c := newClient(t, "v2.2.0", "/hydra", "", &requests)
+
+		_, err := c.ListOAuth2Client(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"GET /hydra/version", "GET /hydra/admin/clients"}, requests)
+	})
+
+	t.Run("should check readiness under the path of the admin URL", func(t *testing.T) {
+		var requests []string
+		c := newClient(t, "v2.2.0", "/hydra", "", &requests)
+
+		require.NoError(t, c.Ready(context.Background()))
+		assert.Equal(t, []string{"GET /hydra/health/ready"}, requests)
+	})
+
+	t.Run("should fall back to the /clients endpoint and retry transient detection failures", func(t *testing.T) {
+		var requests []string
+		c := newClient(t, "503", "", "", &requests)
+
+		_, err := c.ListOAuth2Client(context.Background())
+		require.NoError(t, err)
+		_, err = c.Version(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, []string{"GET /version", "GET /clients", "GET /version"}, requests)
+	})
+
+	t.Run("should fall back to the endpoints of ORY Hydra v1 for good when the version is not served", func(t *testing.T) {
+		var requests []string
+		c := newClient(t, "404", "/hydra", "", &requests)
+
+		_, err := c.ListOAuth2Client(context.Background())
+		require.NoError(t, err)
+		_, err = c.ListOAuth2Client(context.Background())
+		require.NoError(t, err)
+		_, _, err = c.(hydra.KeysClient).GetJSONWebKeySet(context.Background(), "set")
+		require.NoError(t, err)
+		require.NoError(t, c.RevokeOAuth2ClientTokens(context.Background(), "id"))
+		_, err = c.Version(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, []string{
+			"GET /hydra/version",
+			"GET /hydra/clients",
+			"GET /hydra/clients",
+			"GET /hydra/keys/set",
+			"DELETE /hydra/oauth2/tokens",
+		}, requests)
+	})
+
+	t.Run("should detect the version concurrently", func(t *testing.T) {
+		var requests []string
+		c := newClient(t, "v2.2.0", "", "", &requests)
+
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := c.ListOAuth2Client(context.Background())
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		v, err := c.Version(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "v2.2.0", v)
+		assert.Contains(t, requests, "GET /admin/clients")
+		assert.NotContains(t, requests, "GET /clients")
+	})
+}
@@ -4,23 +4,35 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	"github.com/ory/hydra-maester/credentials"
+	"github.com/ory/hydra-maester/helpers"
 	"github.com/ory/hydra-maester/hydra"
+	"github.com/ory/hydra-maester/policy"
 
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
+	configv1alpha1 "github.com/ory/hydra-maester/api/config/v1alpha1"
 	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	hydrav1beta1 "github.com/ory/hydra-maester/api/v1beta1"
 	"github.com/ory/hydra-maester/controllers"
 	// +kubebuilder:scaffold:imports
 )
@@ -33,30 +45,95 @@ var (
 func init() {
 	_ = apiv1.AddToScheme(scheme)
 	_ = hydrav1alpha1.AddToScheme(scheme)
+	_ = hydrav1beta1.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
+	generator := credentials.NewGenerator()
+	var lifespanBounds hydra.LifespanBounds
+	var retryBaseDelay, retryMaxDelay, replacementGracePeriod time.Duration
+	var base configv1alpha1.ManagerConfig
 	var (
-		metricsAddr, hydraURL, endpoint, forwardedProto, syncPeriod, tlsTrustStore, namespace, leaderElectorNs string
-		hydraPort                                                                                              int
-		enableLeaderElection, insecureSkipVerify                                                               bool
+		metricsAddr, syncPeriod, namespace, leaderElectorNs, watchNamespaces, credentialStoreHTTPURL string
+		credentialStoreFileDir, controllerClass, shardSelector, probeAddr, webhookCertDir            string
+		otlpEndpoint, logFormat, logLevel, configFile                                                string
+		webhookPort                                                                                  int
+		enableLeaderElection, enableConversionWebhook, enablePolicyWebhook, otlpInsecure             bool
+		enforceScopeOwnership                                                                        bool
 	)
 
+	flag.StringVar(&configFile, "config", "", "Path of the configuration file of the manager. The settings it sets override the corresponding flags and env vars, and the settings of the requests to ORY Hydra are reloaded when it changes")
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
-	flag.StringVar(&hydraURL, "hydra-url", "", "The address of ORY Hydra")
-	flag.IntVar(&hydraPort, "hydra-port", 4445, "Port ORY Hydra is listening on")
-	flag.StringVar(&endpoint, "endpoint", "/clients", "ORY Hydra's client endpoint")
-	flag.StringVar(&forwardedProto, "forwarded-proto", "", "If set, this adds the value as the X-Forwarded-Proto header in requests to the ORY Hydra admin server")
-	flag.StringVar(&tlsTrustStore, "tls-trust-store", "", "trust store certificate path. If set ca will be set in http client to connect with hydra admin")
+	flag.StringVar(&probeAddr, "health-probe-addr", ":8081", "The address the /healthz and /readyz probe endpoints bind to.")
+	flag.StringVar(&base.Hydra.URL, "hydra-url", "", "The address of ORY Hydra")
+	flag.IntVar(&base.Hydra.Port, "hydra-port", configv1alpha1.DefaultHydraPort, "Port ORY Hydra is listening on")
+	flag.StringVar(&base.Hydra.Endpoint, "endpoint", "", "ORY Hydra's client endpoint. If empty, it is detected from ORY Hydra's version, falling back to /clients if the version cannot be detected")
+	flag.StringVar(&base.Hydra.ForwardedProto, "forwarded-proto", "", "If set, this adds the value as the X-Forwarded-Proto header in requests to the ORY Hydra admin server")
+	flag.DurationVar(&base.Hydra.Timeout.Duration, "hydra-timeout", configv1alpha1.DefaultTimeout, "Timeout of the requests to ORY Hydra")
+	flag.StringVar(&base.Hydra.TLS.TrustStore, "tls-trust-store", "", "trust store certificate path. If set ca will be set in http client to connect with hydra admin")
 	flag.StringVar(&syncPeriod, "sync-period", "10h", "Determines the minimum frequency at which watched resources are reconciled")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false, "Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
-	flag.BoolVar(&insecureSkipVerify, "insecure-skip-verify", false, "If set, http client will be configured to skip insecure verification to connect with hydra admin")
-	flag.StringVar(&namespace, "namespace", "", "Namespace in which the controller should operate. Setting this will make the controller ignore other namespaces.")
+	flag.BoolVar(&base.Hydra.TLS.InsecureSkipVerify, "insecure-skip-verify", false, "If set, http client will be configured to skip insecure verification to connect with hydra admin")
+	flag.StringVar(&namespace, "namespace", "", "Deprecated: use --watch-namespaces instead.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "", "Comma separated list of namespaces in which the controller should operate. If empty and no namespace selector is set, all namespaces are watched")
+	flag.StringVar(&base.Namespaces.Selector, "namespace-selector", "", "Label selector of the namespaces in which the controller should operate, e.g. 'hydra.ory.sh/managed=true'. Combined with --watch-namespaces")
+	flag.StringVar(&controllerClass, "controller-class", "", "Controller class of this instance. Only clients with a matching spec.controllerClass are reconciled")
+	flag.StringVar(&shardSelector, "shard-selector", "", "Label selector of the clients this instance reconciles, e.g. 'hydra.ory.sh/shard=a'. Clients not matching it are not cached")
 	flag.StringVar(&leaderElectorNs, "leader-elector-namespace", "", "Leader elector namespace where controller should be set.")
+	flag.StringVar(&credentialStoreHTTPURL, "credential-store-http-url", "", "Base URL of the HTTP key-value store used by clients with credentialStore 'http'. The bearer token is read from the CREDENTIAL_STORE_HTTP_TOKEN env var")
+	flag.StringVar(&credentialStoreFileDir, "credential-store-file-dir", "", "Directory used by clients with credentialStore 'file'")
+	flag.StringVar(&generator.IDTemplate, "client-id-template", "", "Template of the client ID of clients that don't set spec.clientId, e.g. '{{ .Namespace }}-{{ .Name }}'. If empty, client IDs are generated")
+	flag.IntVar(&generator.IDLength, "client-id-length", 0, "Length of generated client IDs. If 0, a UUID is generated")
+	flag.StringVar(&generator.IDCharset, "client-id-charset", credentials.AlphaNumeric, "Characters generated client IDs are made of")
+	flag.IntVar(&generator.SecretLength, "client-secret-length", credentials.DefaultSecretLength, "Length of generated client secrets")
+	flag.StringVar(&generator.SecretCharset, "client-secret-charset", credentials.AlphaNumeric, "Characters generated client secrets are made of")
+	flag.DurationVar(&lifespanBounds.Min, "min-token-lifespan", 0, "Minimum token lifespan clients may set in spec.tokenLifespans")
+	flag.BoolVar(&enableConversionWebhook, "enable-conversion-webhook", false, "Serve the webhook converting OAuth2Clients between the v1alpha1 and v1beta1 API versions. Required when the CRD uses the Webhook conversion strategy")
+	flag.BoolVar(&enablePolicyWebhook, "enable-policy-webhook", false, "Serve the webhook rejecting the OAuth2Clients that violate an OAuth2ClientPolicy or a ClusterOAuth2ClientPolicy")
+	flag.BoolVar(&enforceScopeOwnership, "enforce-scope-ownership", false, "Reject the OAuth2Clients requesting scopes that no OAuth2Scope declares, or that the namespace owning them did not approve for the client")
+	flag.IntVar(&webhookPort, "webhook-port", webhook.DefaultPort, "Port the webhook server listens on")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "", "Directory holding the tls.crt and tls.key files of the webhook server. If empty, <temp dir>/k8s-webhook-server/serving-certs is used")
+	flag.DurationVar(&lifespanBounds.Max, "max-token-lifespan", 0, "Maximum token lifespan clients may set in spec.tokenLifespans. If 0, lifespans are not limited")
+	flag.DurationVar(&retryBaseDelay, "retry-base-delay", controllers.DefaultRetryBaseDelay, "Delay before retrying a client whose reconciliation failed with a transient error, e.g. because ORY Hydra is unavailable. It doubles with each consecutive failure")
+	flag.DurationVar(&retryMaxDelay, "retry-max-delay", controllers.DefaultRetryMaxDelay, "Maximum delay between two retries of a client whose reconciliation keeps failing with transient errors")
+	flag.DurationVar(&replacementGracePeriod, "replacement-grace-period", controllers.DefaultReplacementGracePeriod, "How long the ORY Hydra clients replaced by a new one, e.g. when spec.clientId changes, are kept after the Secret has been updated, unless the replacement is acknowledged earlier")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "", "Address (host:port) of the OTLP/HTTP collector the traces of the reconciliations and of the requests to ORY Hydra are exported to. If empty, the OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_EXPORTER_OTLP_TRACES_ENDPOINT env vars are used, and tracing is disabled if they are not set")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false, "If set, traces are exported to the OTLP/HTTP collector over plain HTTP")
+	flag.StringVar(&logFormat, "log-format", helpers.LogFormatConsole, "Format of the logs: json or console")
+	flag.StringVar(&logLevel, "log-level", "info", "Minimum level of the logs: debug, info, error, or a verbosity such as 2")
 	flag.Parse()
 
-	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
+	logger, err := helpers.NewLogger(logFormat, logLevel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	ctrl.SetLogger(logger)
+
+	base.Namespaces.Watch = helpers.ParseNamespaces(watchNamespaces)
+	if namespace != "" {
+		setupLog.Info("flag --namespace is deprecated, use --watch-namespaces instead")
+		base.Namespaces.Watch = append(base.Namespaces.Watch, namespace)
+	}
+	base.SecretKeys.ClientID = os.Getenv("CLIENT_ID_KEY")
+	base.SecretKeys.ClientSecret = os.Getenv("CLIENT_SECRET_KEY")
+
+	loadConfig := func() (*configv1alpha1.ManagerConfig, error) {
+		cfg, err := configv1alpha1.Load(configFile, base)
+		if err != nil {
+			return nil, err
+		}
+		if len(cfg.Namespaces.Watch) == 0 && os.Getenv("NAMESPACE") != "" {
+			cfg.Namespaces.Watch = append(cfg.Namespaces.Watch, os.Getenv("NAMESPACE"))
+		}
+		return cfg, nil
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		setupLog.Error(err, "invalid configuration", "config", configFile)
+		os.Exit(1)
+	}
 
 	syncPeriodParsed, err := time.ParseDuration(syncPeriod)
 	if err != nil {
@@ -64,67 +141,331 @@ func main() {
 		os.Exit(1)
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	if err := generator.Validate(); err != nil {
+		setupLog.Error(err, "invalid credentials generator configuration")
+		os.Exit(1)
+	}
+
+	ctx := ctrl.SetupSignalHandler()
+
+	shutdownTracing, err := helpers.SetupTracing(ctx, otlpEndpoint, otlpInsecure)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	restConfig := ctrl.GetConfigOrDie()
+
+	namespaces := cfg.Namespaces.Watch
+
+	var namespaceWatcher *helpers.NamespaceWatcher
+	if cfg.Namespaces.Selector != "" {
+		// the selector has been validated with the configuration
+		selector, _ := labels.Parse(cfg.Namespaces.Selector)
+
+		c, err := client.New(restConfig, client.Options{Scheme: scheme})
+		if err != nil {
+			setupLog.Error(err, "unable to create namespace client")
+			os.Exit(1)
+		}
+
+		namespaceWatcher = &helpers.NamespaceWatcher{
+			Client:   c,
+			Selector: selector,
+			Interval: 30 * time.Second,
+			Log:      ctrl.Log.WithName("namespaces"),
+		}
+		for {
+			if namespaceWatcher.Namespaces, err = helpers.SelectNamespaces(ctx, c, selector); err != nil {
+				setupLog.Error(err, "unable to select namespaces")
+				os.Exit(1)
+			}
+			// an empty set of namespaces would make the cache watch all of them
+			if len(namespaceWatcher.Namespaces) > 0 || len(namespaces) > 0 {
+				break
+			}
+			setupLog.Info("no namespace matches the namespace selector, waiting", "selector", cfg.Namespaces.Selector)
+			select {
+			case <-ctx.Done():
+				setupLog.Info("stopped while waiting for a namespace to match the namespace selector")
+				return
+			case <-time.After(namespaceWatcher.Interval):
+			}
+		}
+		namespaces = append(namespaces, namespaceWatcher.Namespaces...)
+	}
+	setupLog.Info("watching namespaces", "namespaces", namespaces)
+
+	byObject := map[client.Object]cache.ByObject{}
+	if shardSelector != "" {
+		selector, err := labels.Parse(shardSelector)
+		if err != nil {
+			setupLog.Error(err, "cannot parse shard selector")
+			os.Exit(1)
+		}
+		byObject[&hydrav1alpha1.OAuth2Client{}] = cache.ByObject{Label: selector}
+	}
+
+	leaderElectionID := "hydra-maester.ory.sh"
+	if controllerClass != "" {
+		// instances of different classes must not compete for the same lease
+		leaderElectionID = controllerClass + "." + leaderElectionID
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme: scheme,
 		Metrics: server.Options{
 			BindAddress: metricsAddr,
 		},
-		LeaderElection: enableLeaderElection,
+		HealthProbeBindAddress: probeAddr,
+		LeaderElection:         enableLeaderElection,
+		LeaderElectionID:       leaderElectionID,
 		Cache: cache.Options{
-			SyncPeriod: &syncPeriodParsed,
-			DefaultNamespaces: map[string]cache.Config{
-				os.Getenv("NAMESPACE"): {},
-			},
+			SyncPeriod:        &syncPeriodParsed,
+			DefaultNamespaces: helpers.CacheNamespaces(namespaces),
+			ByObject:          byObject,
 		},
 		LeaderElectionNamespace: leaderElectorNs,
+		Controller: config.Controller{
+			GroupKindConcurrency: map[string]int{
+				"OAuth2Client." + hydrav1alpha1.GroupVersion.Group:          cfg.Concurrency.OAuth2Clients,
+				"JsonWebKeySet." + hydrav1alpha1.GroupVersion.Group:         cfg.Concurrency.JsonWebKeySets,
+				"TrustedJwtGrantIssuer." + hydrav1alpha1.GroupVersion.Group: cfg.Concurrency.TrustedJwtGrantIssuers,
+			},
+		},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    webhookPort,
+			CertDir: webhookCertDir,
+		}),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	if hydraURL == "" {
-		setupLog.Error(fmt.Errorf("hydra URL can't be empty"), "unable to create controller", "controller", "OAuth2Client")
+	// the requests to the default ORY Hydra and to the ones set by the
+	// resources are sent with transports reconfigured on reload
+	hydraTransport, err := helpers.NewReloadableTransport(hydraHTTPClientConfig(cfg))
+	if err != nil {
+		setupLog.Error(err, "cannot configure the requests to ORY Hydra")
 		os.Exit(1)
 	}
-
-	defaultSpec := hydrav1alpha1.OAuth2ClientSpec{
-		HydraAdmin: hydrav1alpha1.HydraAdmin{
-			URL:            hydraURL,
-			Port:           hydraPort,
-			Endpoint:       endpoint,
-			ForwardedProto: forwardedProto,
-		},
-	}
-	if tlsTrustStore != "" {
-		if _, err := os.Stat(tlsTrustStore); err != nil {
-			setupLog.Error(err, "cannot parse tls trust store")
-			os.Exit(1)
-		}
+	resourceTransport, err := helpers.NewReloadableTransport(helpers.HTTPClientConfig{Timeout: cfg.Hydra.Timeout.Duration})
+	if err != nil {
+		setupLog.Error(err, "cannot configure the requests to ORY Hydra")
+		os.Exit(1)
 	}
+	resourceHTTPClient := &http.Client{Transport: resourceTransport}
 
-	hydraClient, err := hydra.New(defaultSpec, tlsTrustStore, insecureSkipVerify)
+	hydraClient, err := hydra.NewInternalClientWithHTTPClient(hydrav1alpha1.HydraAdmin{
+		URL:            cfg.Hydra.URL,
+		Port:           cfg.Hydra.Port,
+		Endpoint:       cfg.Hydra.Endpoint,
+		ForwardedProto: cfg.Hydra.ForwardedProto,
+	}, &http.Client{Transport: hydraTransport})
 	if err != nil {
 		setupLog.Error(err, "making default hydra client", "controller", "OAuth2Client")
 		os.Exit(1)
 
 	}
 
-	err = controllers.New(
+	reconcilerOpts := []controllers.Option{
+		controllers.WithClientFactory(func(spec hydrav1alpha1.OAuth2ClientSpec, _ string, _ bool) (hydra.Client, error) {
+			c, err := hydra.NewInternalClientWithHTTPClient(spec.HydraAdmin, resourceHTTPClient)
+			if err != nil {
+				return nil, err
+			}
+			return c, nil
+		}),
+		controllers.WithSecretKeys(cfg.SecretKeys.ClientID, cfg.SecretKeys.ClientSecret),
+		controllers.WithCredentialsGenerator(generator),
+		controllers.WithControllerClass(controllerClass),
+		controllers.WithLifespanBounds(lifespanBounds),
+		controllers.WithEventRecorder(mgr.GetEventRecorder("hydra-maester")),
+		controllers.WithHydraURL(fmt.Sprintf("%s:%d", cfg.Hydra.URL, cfg.Hydra.Port)),
+		controllers.WithRetryBackoff(retryBaseDelay, retryMaxDelay),
+		controllers.WithReplacementGracePeriod(replacementGracePeriod),
+	}
+	if podName := os.Getenv("POD_NAME"); podName != "" {
+		reconcilerOpts = append(reconcilerOpts, controllers.WithControllerInstance(podName))
+	}
+	if enforceScopeOwnership {
+		reconcilerOpts = append(reconcilerOpts, controllers.WithScopeOwnership())
+	}
+	if shardSelector != "" {
+		// the cache only holds the clients of the shard, while the policies
+		// limit the clients of the whole namespace
+		reconcilerOpts = append(reconcilerOpts, controllers.WithClientsReader(mgr.GetAPIReader()))
+	}
+
+	if credentialStoreHTTPURL != "" {
+		httpClient, err := helpers.CreateHttpClient(false, "")
+		if err != nil {
+			setupLog.Error(err, "making credential store http client")
+			os.Exit(1)
+		}
+		store, err := credentials.NewHTTPStore(credentialStoreHTTPURL, httpClient, os.Getenv("CREDENTIAL_STORE_HTTP_TOKEN"))
+		if err != nil {
+			setupLog.Error(err, "cannot parse credential store http url")
+			os.Exit(1)
+		}
+		reconcilerOpts = append(reconcilerOpts, controllers.WithCredentialStore(hydrav1alpha1.CredentialStoreHTTP, store))
+	}
+
+	if credentialStoreFileDir != "" {
+		reconcilerOpts = append(reconcilerOpts, controllers.WithCredentialStore(hydrav1alpha1.CredentialStoreFile, credentials.NewFileStore(credentialStoreFileDir)))
+	}
+
+	reconciler := controllers.New(
 		mgr.GetClient(),
 		hydraClient,
 		ctrl.Log.WithName("controllers").WithName("OAuth2Client"),
-		controllers.WithNamespace(namespace),
-	).SetupWithManager(mgr)
-	if err != nil {
+		reconcilerOpts...,
+	)
+	if err := reconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "OAuth2Client")
 		os.Exit(1)
 	}
+
+	keySetReconciler := controllers.NewJsonWebKeySetReconciler(
+		mgr.GetClient(),
+		hydraClient,
+		ctrl.Log.WithName("controllers").WithName("JsonWebKeySet"),
+		controllers.WithKeysClientFactory(func(admin hydrav1alpha1.HydraAdmin, _ string, _ bool) (hydra.KeysClient, error) {
+			c, err := hydra.NewInternalClientWithHTTPClient(admin, resourceHTTPClient)
+			if err != nil {
+				return nil, err
+			}
+			return c, nil
+		}),
+		// the resources claiming a set may live in namespaces which are not
+		// watched
+		controllers.WithKeySetReader(mgr.GetAPIReader()),
+	)
+	if err := keySetReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "JsonWebKeySet")
+		os.Exit(1)
+	}
+
+	trustReconciler := controllers.NewTrustedJwtGrantIssuerReconciler(
+		mgr.GetClient(),
+		hydraClient,
+		ctrl.Log.WithName("controllers").WithName("TrustedJwtGrantIssuer"),
+		controllers.WithTrustClientFactory(func(admin hydrav1alpha1.HydraAdmin, _ string, _ bool) (hydra.TrustClient, error) {
+			c, err := hydra.NewInternalClientWithHTTPClient(admin, resourceHTTPClient)
+			if err != nil {
+				return nil, err
+			}
+			return c, nil
+		}),
+	)
+	if err := trustReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TrustedJwtGrantIssuer")
+		os.Exit(1)
+	}
+
+	if enforceScopeOwnership {
+		scopeReconciler := controllers.NewOAuth2ScopeReconciler(
+			mgr.GetClient(),
+			ctrl.Log.WithName("controllers").WithName("OAuth2Scope"),
+		)
+		if err := scopeReconciler.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "OAuth2Scope")
+			os.Exit(1)
+		}
+	}
+
+	if enableConversionWebhook || enablePolicyWebhook {
+		webhookBuilder := ctrl.NewWebhookManagedBy(mgr, &hydrav1alpha1.OAuth2Client{})
+		if enablePolicyWebhook {
+			// the clients of all namespaces are validated, not only the
+			// cached ones
+			webhookBuilder = webhookBuilder.WithValidator(&policy.Validator{Reader: mgr.GetAPIReader(), ScopeOwnership: enforceScopeOwnership})
+		}
+		if err := webhookBuilder.Complete(); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "OAuth2Client")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up health check")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("hydra", reconciler.ReadyzCheck); err != nil {
+		setupLog.Error(err, "unable to set up ready check")
+		os.Exit(1)
+	}
+
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if namespaceWatcher != nil {
+		if err := mgr.Add(namespaceWatcher); err != nil {
+			setupLog.Error(err, "unable to add namespace watcher")
+			os.Exit(1)
+		}
+	}
+
+	if configFile != "" {
+		configWatcher := &helpers.FileWatcher{
+			Files: func() []string {
+				return []string{configFile, cfg.Hydra.TLS.TrustStore, cfg.Hydra.Auth.BearerTokenFile, cfg.Hydra.Auth.PasswordFile}
+			},
+			OnChange: func() error {
+				next, err := loadConfig()
+				if err != nil {
+					setupLog.Error(err, "invalid configuration, keeping the current one", "config", configFile)
+					return nil
+				}
+				if changed := cfg.RestartRequired(next); len(changed) > 0 {
+					return fmt.Errorf("settings %v of %s changed: %w", changed, configFile, helpers.ErrRestartRequired)
+				}
+				if err := hydraTransport.Reload(hydraHTTPClientConfig(next)); err != nil {
+					setupLog.Error(err, "cannot reconfigure the requests to ORY Hydra, keeping the current configuration", "config", configFile)
+					return nil
+				}
+				_ = resourceTransport.Reload(helpers.HTTPClientConfig{Timeout: next.Hydra.Timeout.Duration})
+				cfg = next
+				setupLog.Info("reloaded configuration", "config", configFile)
+				return nil
+			},
+			Debounce: time.Second,
+			Log:      setupLog,
+		}
+		if err := mgr.Add(configWatcher); err != nil {
+			setupLog.Error(err, "unable to add configuration watcher")
+			os.Exit(1)
+		}
+	}
+
+	err = mgr.Start(ctx)
+
+	// export the spans of the last reconciliations before exiting
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		setupLog.Error(err, "unable to flush traces")
+	}
+
+	if errors.Is(err, helpers.ErrRestartRequired) {
+		// the pod is restarted by Kubernetes with the new settings
+		setupLog.Info("exiting to restart with the new settings", "reason", err.Error())
+		return
+	}
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// hydraHTTPClientConfig returns the configuration of the requests to the
+// default ORY Hydra.
+func hydraHTTPClientConfig(cfg *configv1alpha1.ManagerConfig) helpers.HTTPClientConfig {
+	return helpers.HTTPClientConfig{
+		Timeout:            cfg.Hydra.Timeout.Duration,
+		TLSTrustStore:      cfg.Hydra.TLS.TrustStore,
+		InsecureSkipVerify: cfg.Hydra.TLS.InsecureSkipVerify,
+		BearerTokenFile:    cfg.Hydra.Auth.BearerTokenFile,
+		Username:           cfg.Hydra.Auth.Username,
+		PasswordFile:       cfg.Hydra.Auth.PasswordFile,
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+// Package policy checks the oauth2 clients against the OAuth2ClientPolicy and
+// ClusterOAuth2ClientPolicy resources applying to them, and the scopes they
+// request against the OAuth2Scope resources declaring them.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/hydra"
+)
+
+// Policy is a policy applying to an oauth2 client.
+type Policy struct {
+	// Name identifies the policy in the violations, e.g.
+	// "OAuth2ClientPolicy default/restricted".
+	Name string
+	Spec hydrav1alpha1.OAuth2ClientPolicySpec
+}
+
+// ForClient returns the policies applying to c: the OAuth2ClientPolicies of
+// its namespace and the ClusterOAuth2ClientPolicies selecting it.
+func ForClient(ctx context.Context, r client.Reader, c *hydrav1alpha1.OAuth2Client) ([]Policy, error) {
+	var namespaced hydrav1alpha1.OAuth2ClientPolicyList
+	if err := r.List(ctx, &namespaced, client.InNamespace(c.Namespace)); err != nil {
+		return nil, err
+	}
+	var cluster hydrav1alpha1.ClusterOAuth2ClientPolicyList
+	if err := r.List(ctx, &cluster); err != nil {
+		return nil, err
+	}
+
+	var policies []Policy
+	for _, p := range namespaced.Items {
+		policies = append(policies, Policy{
+			Name: fmt.Sprintf("OAuth2ClientPolicy %s/%s", p.Namespace, p.Name),
+			Spec: p.Spec,
+		})
+	}
+
+	var namespace *apiv1.Namespace
+	for _, p := range cluster.Items {
+		if p.Spec.NamespaceSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(p.Spec.NamespaceSelector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid namespace selector of ClusterOAuth2ClientPolicy %s: %w", p.Name, err)
+			}
+			if namespace == nil {
+				namespace = &apiv1.Namespace{}
+				if err := r.Get(ctx, client.ObjectKey{Name: c.Namespace}, namespace); err != nil {
+					return nil, err
+				}
+			}
+			if !selector.Matches(labels.Set(namespace.Labels)) {
+				continue
+			}
+		}
+		policies = append(policies, Policy{
+			Name: fmt.Sprintf("ClusterOAuth2ClientPolicy %s", p.Name),
+			Spec: p.Spec.OAuth2ClientPolicySpec,
+		})
+	}
+
+	return policies, nil
+}
+
+// Evaluate returns the fields of c violating the policies applying to it. The
+// clients of its namespace are counted with clients, which must see all of
+// them, e.g. an uncached reader when the cache is filtered by a label
+// selector.
+func Evaluate(ctx context.Context, r, clients client.Reader, c *hydrav1alpha1.OAuth2Client) (field.ErrorList, error) {
+	policies, err := ForClient(ctx, r, c)
+	if err != nil {
+		return nil, err
+	}
+
+	// the clients of the namespace are only listed if a policy limits them
+	rank := -1
+	var violations field.ErrorList
+	for _, p := range policies {
+		if p.Spec.MaxClientsPerNamespace != nil && rank < 0 {
+			if rank, err = clientsBefore(ctx, clients, c); err != nil {
+				return nil, err
+			}
+		}
+		violations = append(violations, p.Check(c, rank)...)
+	}
+	return violations, nil
+}
+
+// clientsBefore returns the number of clients of the namespace of c created
+// before it, or all of them if c is not created yet. The clients being
+// deleted are not counted.
+func clientsBefore(ctx context.Context, r client.Reader, c *hydrav1alpha1.OAuth2Client) (int, error) {
+	var clients hydrav1alpha1.OAuth2ClientList
+	if err := r.List(ctx, &clients, client.InNamespace(c.Namespace)); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, other := range clients.Items {
+		if other.Name == c.Name || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		created, otherCreated := c.CreationTimestamp, other.CreationTimestamp
+		if created.IsZero() || otherCreated.Before(&created) || (otherCreated.Equal(&created) && other.Name < c.Name) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Check returns the fields of c violating the policy. rank is the number of
+// clients of its namespace created before c.
+func (p Policy) Check(c *hydrav1alpha1.OAuth2Client, rank int) field.ErrorList {
+	spec := field.NewPath("spec")
+	var violations field.ErrorList
+	forbid := func(path *field.Path, format string, args ...interface{}) {
+		violations = append(violations, field.Forbidden(path, fmt.Sprintf(format, args...)+" by "+p.Name))
+	}
+
+	if len(p.Spec.AllowedGrantTypes) > 0 {
+		for i, grantType := range c.Spec.GrantTypes {
+			if !slices.Contains(p.Spec.AllowedGrantTypes, grantType) {
+				forbid(spec.Child("grantTypes").Index(i), "grant type %q is not allowed", grantType)
+			}
+		}
+	}
+
+	if len(p.Spec.AllowedScopes) > 0 {
+		for i, scope := range c.Spec.ScopeArray {
+			if !matchAny(p.Spec.AllowedScopes, scope) {
+				forbid(spec.Child("scopeArray").Index(i), "scope %q is not allowed", scope)
+			}
+		}
+		for _, scope := range strings.Fields(c.Spec.Scope) {
+			if !matchAny(p.Spec.AllowedScopes, scope) {
+				forbid(spec.Child("scope"), "scope %q is not allowed", scope)
+			}
+		}
+	}
+
+	if len(p.Spec.AllowedRedirectURIHosts) > 0 {
+		// host names are case insensitive
+		hosts := make([]string, len(p.Spec.AllowedRedirectURIHosts))
+		for i, host := range p.Spec.AllowedRedirectURIHosts {
+			hosts[i] = strings.ToLower(host)
+		}
+		for _, uris := range []struct {
+			path *field.Path
+			uris []hydrav1alpha1.RedirectURI
+		}{
+			{spec.Child("redirectUris"), c.Spec.RedirectURIs},
+			{spec.Child("postLogoutRedirectUris"), c.Spec.PostLogoutRedirectURIs},
+		} {
+			for i, uri := range uris.uris {
+				if !matchAny(hosts, redirectURIHost(uri)) {
+					forbid(uris.path.Index(i), "the host of %q is not allowed", uri)
+				}
+			}
+		}
+	}
+
+	if len(p.Spec.AllowedAudiences) > 0 {
+		for i, audience := range c.Spec.Audience {
+			if !matchAny(p.Spec.AllowedAudiences, audience) {
+				forbid(spec.Child("audience").Index(i), "audience %q is not allowed", audience)
+			}
+		}
+	}
+
+	if p.Spec.AllowSkipConsent != nil && !*p.Spec.AllowSkipConsent && c.Spec.SkipConsent {
+		forbid(spec.Child("skipConsent"), "skipping the consent is not allowed")
+	}
+
+	if limit := p.Spec.MaxTokenLifespan; limit != nil {
+		hydra.LifespansFromTokenLifespans(c.Spec.TokenLifespans).Range(func(name, value string) {
+			// the invalid lifespans are reported by the lifespan bounds
+			if d, err := time.ParseDuration(value); err == nil && d > limit.Duration {
+				forbid(spec.Child("tokenLifespans", name), "%s exceeds the maximum of %s allowed", value, limit.Duration)
+			}
+		})
+	}
+
+	if limit := p.Spec.MaxClientsPerNamespace; limit != nil && rank >= int(*limit) {
+		forbid(field.NewPath("metadata", "namespace"), "more than %d clients in the namespace are not allowed", *limit)
+	}
+
+	return violations
+}
+
+// redirectURIHost returns the host of the redirect URI, or an empty string if
+// it has none.
+func redirectURIHost(uri hydrav1alpha1.RedirectURI) string {
+	u, err := url.Parse(string(uri))
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// matchAny returns true if s is not empty and matches one of the patterns.
+func matchAny(patterns []string, s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if match(pattern, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns true if s matches the pattern, in which `*` matches any
+// sequence of characters.
+func match(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+
+	prefix, suffix := parts[0], parts[len(parts)-1]
+	if len(s) < len(prefix)+len(suffix) || !strings.HasPrefix(s, prefix) || !strings.HasSuffix(s, suffix) {
+		return false
+	}
+	s = s[len(prefix) : len(s)-len(suffix)]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return true
+}
@@ -0,0 +1,278 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package policy_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/policy"
+)
+
+func oauth2Client(name string, created time.Time) *hydrav1alpha1.OAuth2Client {
+	return &hydrav1alpha1.OAuth2Client{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a", CreationTimestamp: metav1.NewTime(created)},
+		Spec: hydrav1alpha1.OAuth2ClientSpec{
+			GrantTypes:   []hydrav1alpha1.GrantType{"authorization_code", "refresh_token"},
+			RedirectURIs: []hydrav1alpha1.RedirectURI{"https://app.example.com/callback"},
+			ScopeArray:   []string{"openid", "orders.read"},
+			Audience:     []string{"https://api.example.com/orders"},
+			SecretName:   name,
+		},
+	}
+}
+
+func fields(violations field.ErrorList) []string {
+	var paths []string
+	for _, v := range violations {
+		paths = append(paths, v.Field)
+	}
+	return paths
+}
+
+func TestCheck(t *testing.T) {
+	for desc, tc := range map[string]struct {
+		spec   hydrav1alpha1.OAuth2ClientPolicySpec
+		update func(c *hydrav1alpha1.OAuth2Client)
+		rank   int
+		fields []string
+	}{
+		"empty policy": {
+			update: func(c *hydrav1alpha1.OAuth2Client) {
+				c.Spec.SkipConsent = true
+			},
+			rank: 100,
+		},
+		"allowed client": {
+			spec: hydrav1alpha1.OAuth2ClientPolicySpec{
+				AllowedGrantTypes:       []hydrav1alpha1.GrantType{"authorization_code", "refresh_token", "client_credentials"},
+				AllowedScopes:           []string{"openid", "orders.*"},
+				AllowedRedirectURIHosts: []string{"*.EXAMPLE.com"},
+				AllowedAudiences:        []string{"https://api.example.com/*"},
+				AllowSkipConsent:        ptr.To(false),
+				MaxTokenLifespan:        &metav1.Duration{Duration: time.Hour},
+				MaxClientsPerNamespace:  ptr.To(int32(2)),
+			},
+			update: func(c *hydrav1alpha1.OAuth2Client) {
+				c.Spec.TokenLifespans.AuthorizationCodeGrantAccessTokenLifespan = "1h"
+			},
+			rank: 1,
+		},
+		"grant types": {
+			spec: hydrav1alpha1.OAuth2ClientPolicySpec{AllowedGrantTypes: []hydrav1alpha1.GrantType{"authorization_code"}},
+			fields: []string{
+				"spec.grantTypes[1]",
+			},
+		},
+		"scopes": {
+			spec: hydrav1alpha1.OAuth2ClientPolicySpec{AllowedScopes: []string{"orders.*"}},
+			update: func(c *hydrav1alpha1.OAuth2Client) {
+				c.Spec.Scope = "orders.write admin"
+			},
+			fields: []string{
+				"spec.scopeArray[0]",
+				"spec.scope",
+			},
+		},
+		"redirect URI hosts": {
+			spec: hydrav1alpha1.OAuth2ClientPolicySpec{AllowedRedirectURIHosts: []string{"app.example.com"}},
+			update: func(c *hydrav1alpha1.OAuth2Client) {
+				c.Spec.RedirectURIs = append(c.Spec.RedirectURIs, "https://evil.com/callback", "/relative")
+				c.Spec.PostLogoutRedirectURIs = []hydrav1alpha1.RedirectURI{"https://app.example.com.evil.com/"}
+			},
+			fields: []string{
+				"spec.redirectUris[1]",
+				"spec.redirectUris[2]",
+				"spec.postLogoutRedirectUris[0]",
+			},
+		},
+		"audiences": {
+			spec: hydrav1alpha1.OAuth2ClientPolicySpec{AllowedAudiences: []string{"https://api.example.com/payments"}},
+			fields: []string{
+				"spec.audience[0]",
+			},
+		},
+		"skip consent": {
+			spec: hydrav1alpha1.OAuth2ClientPolicySpec{AllowSkipConsent: ptr.To(false)},
+			update: func(c *hydrav1alpha1.OAuth2Client) {
+				c.Spec.SkipConsent = true
+			},
+			fields: []string{
+				"spec.skipConsent",
+			},
+		},
+		"token lifespans": {
+			spec: hydrav1alpha1.OAuth2ClientPolicySpec{MaxTokenLifespan: &metav1.Duration{Duration: time.Hour}},
+			update: func(c *hydrav1alpha1.OAuth2Client) {
+				c.Spec.TokenLifespans.AuthorizationCodeGrantAccessTokenLifespan = "30m"
+				c.Spec.TokenLifespans.RefreshTokenGrantRefreshTokenLifespan = "720h"
+			},
+			fields: []string{
+				"spec.tokenLifespans.refresh_token_grant_refresh_token_lifespan",
+			},
+		},
+		"clients per namespace": {
+			spec: hydrav1alpha1.OAuth2ClientPolicySpec{MaxClientsPerNamespace: ptr.To(int32(2))},
+			rank: 2,
+			fields: []string{
+				"metadata.namespace",
+			},
+		},
+	} {
+		t.Run("case="+desc, func(t *testing.T) {
+			c := oauth2Client("client", time.Now())
+			if tc.update != nil {
+				tc.update(c)
+			}
+
+			violations := policy.Policy{Name: "OAuth2ClientPolicy team-a/restricted", Spec: tc.spec}.Check(c, tc.rank)
+			assert.Equal(t, tc.fields, fields(violations))
+			for _, v := range violations {
+				assert.Contains(t, v.Error(), "by OAuth2ClientPolicy team-a/restricted")
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, hydrav1alpha1.AddToScheme(s))
+
+	now := time.Now().Truncate(time.Second)
+	first, second, third := oauth2Client("first", now.Add(-time.Hour)), oauth2Client("second", now), oauth2Client("third", now)
+	first.Spec.SkipConsent = true
+
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		&apiv1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"tenant": "true"}}},
+		first, second, third,
+		&hydrav1alpha1.OAuth2ClientPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "team-a"},
+			Spec:       hydrav1alpha1.OAuth2ClientPolicySpec{MaxClientsPerNamespace: ptr.To(int32(2))},
+		},
+		&hydrav1alpha1.OAuth2ClientPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "team-b"},
+			Spec:       hydrav1alpha1.OAuth2ClientPolicySpec{AllowedScopes: []string{"none"}},
+		},
+		&hydrav1alpha1.ClusterOAuth2ClientPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenants"},
+			Spec: hydrav1alpha1.ClusterOAuth2ClientPolicySpec{
+				NamespaceSelector:      &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+				OAuth2ClientPolicySpec: hydrav1alpha1.OAuth2ClientPolicySpec{AllowSkipConsent: ptr.To(false)},
+			},
+		},
+		&hydrav1alpha1.ClusterOAuth2ClientPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "others"},
+			Spec: hydrav1alpha1.ClusterOAuth2ClientPolicySpec{
+				NamespaceSelector:      &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "false"}},
+				OAuth2ClientPolicySpec: hydrav1alpha1.OAuth2ClientPolicySpec{AllowedScopes: []string{"none"}},
+			},
+		},
+	).Build()
+	ctx := context.Background()
+
+	policies, err := policy.ForClient(ctx, c, first)
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	assert.Equal(t, "OAuth2ClientPolicy team-a/quota", policies[0].Name)
+	assert.Equal(t, "ClusterOAuth2ClientPolicy tenants", policies[1].Name)
+
+	for _, tc := range []struct {
+		client *hydrav1alpha1.OAuth2Client
+		fields []string
+	}{
+		{first, []string{"spec.skipConsent"}},
+		{second, nil},
+		// created at the same time as second, but ordered after it
+		{third, []string{"metadata.namespace"}},
+		// not created yet
+		{oauth2Client("fourth", time.Time{}), []string{"metadata.namespace"}},
+	} {
+		t.Run("client="+tc.client.Name, func(t *testing.T) {
+			violations, err := policy.Evaluate(ctx, c, c, tc.client)
+			require.NoError(t, err)
+			assert.Equal(t, tc.fields, fields(violations))
+		})
+	}
+
+	t.Run("case=clients are counted with the clients reader", func(t *testing.T) {
+		// e.g. a cache holding only the clients of a shard
+		shard := fake.NewClientBuilder().WithScheme(s).WithObjects(
+			&apiv1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+			&hydrav1alpha1.OAuth2ClientPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "team-a"},
+				Spec:       hydrav1alpha1.OAuth2ClientPolicySpec{MaxClientsPerNamespace: ptr.To(int32(2))},
+			},
+			third,
+		).Build()
+
+		violations, err := policy.Evaluate(ctx, shard, shard, third)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+
+		violations, err = policy.Evaluate(ctx, shard, c, third)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"metadata.namespace"}, fields(violations))
+	})
+
+	t.Run("case=clients being deleted are not counted", func(t *testing.T) {
+		second.Finalizers = []string{"test"}
+		require.NoError(t, c.Update(ctx, second))
+		require.NoError(t, c.Delete(ctx, second))
+
+		violations, err := policy.Evaluate(ctx, c, c, third)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("case=validator", func(t *testing.T) {
+		v := &policy.Validator{Reader: c}
+
+		_, err := v.ValidateCreate(ctx, oauth2Client("fourth", time.Time{}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `OAuth2Client.hydra.ory.sh "fourth" is invalid: metadata.namespace: Forbidden`)
+
+		// the clients violating a policy can be updated unless their spec
+		// changes
+		updated := first.DeepCopy()
+		updated.Finalizers = []string{"test"}
+		_, err = v.ValidateUpdate(ctx, first, updated)
+		assert.NoError(t, err)
+
+		updated.Spec.ClientName = "first"
+		_, err = v.ValidateUpdate(ctx, first, updated)
+		assert.ErrorContains(t, err, "spec.skipConsent")
+
+		updated.Spec.SkipConsent = false
+		_, err = v.ValidateUpdate(ctx, first, updated)
+		assert.NoError(t, err)
+
+		_, err = v.ValidateDelete(ctx, first)
+		assert.NoError(t, err)
+	})
+
+	t.Run("case=invalid namespace selector", func(t *testing.T) {
+		require.NoError(t, c.Create(ctx, &hydrav1alpha1.ClusterOAuth2ClientPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "invalid"},
+			Spec: hydrav1alpha1.ClusterOAuth2ClientPolicySpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tenant", Operator: "Unknown"}}},
+			},
+		}))
+
+		_, err := policy.Evaluate(ctx, c, c, first)
+		assert.ErrorContains(t, err, "ClusterOAuth2ClientPolicy invalid")
+	})
+}
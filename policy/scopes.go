@@ -0,0 +1,89 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+)
+
+// ScopeOwners returns the OAuth2Scope owning each declared scope. A scope
+// declared by several OAuth2Scopes is owned by the one created first.
+func ScopeOwners(ctx context.Context, r client.Reader) (map[string]*hydrav1alpha1.OAuth2Scope, error) {
+	var scopes hydrav1alpha1.OAuth2ScopeList
+	if err := r.List(ctx, &scopes); err != nil {
+		return nil, err
+	}
+
+	owners := make(map[string]*hydrav1alpha1.OAuth2Scope, len(scopes.Items))
+	for i := range scopes.Items {
+		s := &scopes.Items[i]
+		if owner, ok := owners[s.Spec.Scope]; ok && !createdBefore(s, owner) {
+			continue
+		}
+		owners[s.Spec.Scope] = s
+	}
+	return owners, nil
+}
+
+// createdBefore returns true if s was created before other, using the name
+// to break ties.
+func createdBefore(s, other *hydrav1alpha1.OAuth2Scope) bool {
+	if !s.CreationTimestamp.Equal(&other.CreationTimestamp) {
+		return s.CreationTimestamp.Before(&other.CreationTimestamp)
+	}
+	return s.Name < other.Name
+}
+
+// EvaluateScopes returns the fields of c requesting a scope that no
+// OAuth2Scope declares, or that the namespace owning it did not approve for
+// c. The clients may request the scopes owned by their namespace.
+func EvaluateScopes(ctx context.Context, r client.Reader, c *hydrav1alpha1.OAuth2Client) (field.ErrorList, error) {
+	owners, err := ScopeOwners(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := field.NewPath("spec")
+	var violations field.ErrorList
+	check := func(path *field.Path, scope string) {
+		owner, ok := owners[scope]
+		switch {
+		case !ok:
+			violations = append(violations, field.Forbidden(path, fmt.Sprintf("scope %q is not owned by any namespace", scope)))
+		case !approved(owner, c):
+			violations = append(violations, field.Forbidden(path, fmt.Sprintf("scope %q is not approved for the client by OAuth2Scope %s", scope, owner.Name)))
+		}
+	}
+	for i, scope := range c.Spec.ScopeArray {
+		check(spec.Child("scopeArray").Index(i), scope)
+	}
+	for _, scope := range strings.Fields(c.Spec.Scope) {
+		check(spec.Child("scope"), scope)
+	}
+	return violations, nil
+}
+
+// approved returns true if the owner of a scope approves c to request it.
+func approved(owner *hydrav1alpha1.OAuth2Scope, c *hydrav1alpha1.OAuth2Client) bool {
+	if owner.Spec.Namespace == c.Namespace {
+		return true
+	}
+	for _, approval := range owner.Spec.Approvals {
+		if approval.Namespace != "*" && approval.Namespace != c.Namespace {
+			continue
+		}
+		if len(approval.Clients) == 0 || slices.Contains(approval.Clients, c.Name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package policy_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+	"github.com/ory/hydra-maester/policy"
+)
+
+func oauth2Scope(namespace, name, scope string, created time.Time, approvals ...hydrav1alpha1.OAuth2ScopeApproval) *hydrav1alpha1.OAuth2Scope {
+	return &hydrav1alpha1.OAuth2Scope{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+		Spec:       hydrav1alpha1.OAuth2ScopeSpec{Scope: scope, Namespace: namespace, Approvals: approvals},
+	}
+}
+
+func TestEvaluateScopes(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, hydrav1alpha1.AddToScheme(s))
+
+	now := time.Now().Truncate(time.Second)
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		oauth2Scope("platform", "openid", "openid", now, hydrav1alpha1.OAuth2ScopeApproval{Namespace: "*"}),
+		oauth2Scope("team-a", "orders-read", "orders.read", now),
+		oauth2Scope("payments", "payments-write", "payments:write", now,
+			hydrav1alpha1.OAuth2ScopeApproval{Namespace: "checkout", Clients: []string{"checkout-api"}},
+			hydrav1alpha1.OAuth2ScopeApproval{Namespace: "billing"},
+		),
+		// declared later for another namespace
+		oauth2Scope("checkout", "checkout-payments-write", "payments:write", now.Add(time.Hour),
+			hydrav1alpha1.OAuth2ScopeApproval{Namespace: "*"},
+		),
+	).Build()
+	ctx := context.Background()
+
+	owners, err := policy.ScopeOwners(ctx, c)
+	require.NoError(t, err)
+	require.Len(t, owners, 3)
+	assert.Equal(t, "payments", owners["payments:write"].Spec.Namespace)
+
+	for desc, tc := range map[string]struct {
+		namespace, name string
+		scopes          []string
+		scope           string
+		fields          []string
+		err             string
+	}{
+		"owning namespace": {
+			namespace: "team-a",
+			scopes:    []string{"openid", "orders.read"},
+		},
+		"approved namespace": {
+			namespace: "billing",
+			scopes:    []string{"openid", "payments:write"},
+		},
+		"approved client": {
+			namespace: "checkout",
+			name:      "checkout-api",
+			scopes:    []string{"payments:write"},
+		},
+		"client not approved": {
+			namespace: "checkout",
+			name:      "checkout-worker",
+			scopes:    []string{"payments:write"},
+			fields:    []string{"spec.scopeArray[0]"},
+			err:       `scope "payments:write" is not approved for the client by OAuth2Scope payments-write`,
+		},
+		"namespace not approved": {
+			namespace: "team-b",
+			scopes:    []string{"openid", "orders.read"},
+			fields:    []string{"spec.scopeArray[1]"},
+			err:       `scope "orders.read" is not approved for the client by OAuth2Scope orders-read`,
+		},
+		"unowned scope": {
+			namespace: "team-a",
+			scope:     "orders.read admin",
+			fields:    []string{"spec.scope"},
+			err:       `scope "admin" is not owned by any namespace`,
+		},
+	} {
+		t.Run("case="+desc, func(t *testing.T) {
+			client := oauth2Client("client", now)
+			client.Namespace = tc.namespace
+			if tc.name != "" {
+				client.Name = tc.name
+			}
+			client.Spec.ScopeArray = tc.scopes
+			client.Spec.Scope = tc.scope
+
+			denied, err := policy.EvaluateScopes(ctx, c, client)
+			require.NoError(t, err)
+			assert.Equal(t, tc.fields, fields(denied))
+			if tc.err != "" {
+				assert.Contains(t, denied.ToAggregate().Error(), tc.err)
+			}
+		})
+	}
+
+	t.Run("case=validator", func(t *testing.T) {
+		client := oauth2Client("client", time.Time{})
+		client.Spec.ScopeArray = []string{"admin"}
+
+		_, err := (&policy.Validator{Reader: c}).ValidateCreate(ctx, client)
+		assert.NoError(t, err)
+
+		_, err = (&policy.Validator{Reader: c, ScopeOwnership: true}).ValidateCreate(ctx, client)
+		assert.ErrorContains(t, err, `spec.scopeArray[0]: Forbidden: scope "admin" is not owned by any namespace`)
+	})
+}
@@ -0,0 +1,64 @@
+// Copyright © 2023 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	hydrav1alpha1 "github.com/ory/hydra-maester/api/v1alpha1"
+)
+
+// +kubebuilder:webhook:path=/validate-hydra-ory-sh-v1alpha1-oauth2client,mutating=false,failurePolicy=fail,sideEffects=None,groups=hydra.ory.sh,resources=oauth2clients,verbs=create;update,versions=v1alpha1,name=voauth2client.hydra.ory.sh,admissionReviewVersions=v1
+
+// Validator is a validating webhook rejecting the oauth2 clients that
+// violate the policies applying to them.
+type Validator struct {
+	Reader client.Reader
+	// ScopeOwnership, if set, also rejects the clients requesting scopes that
+	// are not owned by a namespace or not approved for them.
+	ScopeOwnership bool
+}
+
+var _ admission.Validator[*hydrav1alpha1.OAuth2Client] = &Validator{}
+
+func (v *Validator) ValidateCreate(ctx context.Context, c *hydrav1alpha1.OAuth2Client) (admission.Warnings, error) {
+	return nil, v.validate(ctx, c)
+}
+
+// ValidateUpdate only validates the clients whose spec changes, so that the
+// clients created before a policy can still be updated, e.g. to remove their
+// finalizer.
+func (v *Validator) ValidateUpdate(ctx context.Context, old, c *hydrav1alpha1.OAuth2Client) (admission.Warnings, error) {
+	if equality.Semantic.DeepEqual(old.Spec, c.Spec) || !c.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	return nil, v.validate(ctx, c)
+}
+
+func (v *Validator) ValidateDelete(ctx context.Context, c *hydrav1alpha1.OAuth2Client) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *Validator) validate(ctx context.Context, c *hydrav1alpha1.OAuth2Client) error {
+	violations, err := Evaluate(ctx, v.Reader, v.Reader, c)
+	if err != nil {
+		return apierrs.NewInternalError(err)
+	}
+	if v.ScopeOwnership {
+		scopeViolations, err := EvaluateScopes(ctx, v.Reader, c)
+		if err != nil {
+			return apierrs.NewInternalError(err)
+		}
+		violations = append(violations, scopeViolations...)
+	}
+	if len(violations) > 0 {
+		return apierrs.NewInvalid(hydrav1alpha1.GroupVersion.WithKind("OAuth2Client").GroupKind(), c.Name, violations)
+	}
+	return nil
+}